@@ -11,22 +11,44 @@ import (
 // Similar to slog.Handler, it receives and stores status updates.
 type StatusHandler struct {
 	statuses map[workflow.ActivityID]string
+	listener StatusListener
 	mu       sync.RWMutex
 }
 
+// StatusListener is called after each status update. It must not block.
+type StatusListener func(activityID workflow.ActivityID, status string)
+
+// StatusHandlerOption configures a StatusHandler.
+type StatusHandlerOption func(*StatusHandler)
+
+// WithStatusListener sets a function that is called after each status update.
+func WithStatusListener(listener StatusListener) StatusHandlerOption {
+	return func(sh *StatusHandler) {
+		sh.listener = listener
+	}
+}
+
 // NewStatusHandler creates a new status handler.
-func NewStatusHandler() *StatusHandler {
-	return &StatusHandler{
+func NewStatusHandler(opts ...StatusHandlerOption) *StatusHandler {
+	sh := &StatusHandler{
 		statuses: make(map[workflow.ActivityID]string),
 	}
+	for _, opt := range opts {
+		opt(sh)
+	}
+	return sh
 }
 
 // Set updates the status for a specific activity ID.
 // This is called by StatusLine instances.
 func (sh *StatusHandler) Set(activityID workflow.ActivityID, status string) {
 	sh.mu.Lock()
-	defer sh.mu.Unlock()
 	sh.statuses[activityID] = status
+	sh.mu.Unlock()
+
+	if sh.listener != nil {
+		sh.listener(activityID, status)
+	}
 }
 
 // Get returns the status for a specific activity ID.
@@ -32,4 +32,15 @@ func TestStatusHandler(t *testing.T) {
 		all[activityID] = "modified"
 		assert.Equal(t, "done", handler.Get(activityID))
 	})
+
+	t.Run("listener is called on set", func(t *testing.T) {
+		var got []string
+		handler := NewStatusHandler(WithStatusListener(func(id workflow.ActivityID, status string) {
+			assert.Equal(t, activityID, id)
+			got = append(got, status)
+		}))
+		handler.Set(activityID, "starting")
+		handler.Set(activityID, "done")
+		assert.Equal(t, []string{"starting", "done"}, got)
+	})
 }
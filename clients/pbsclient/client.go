@@ -2,25 +2,39 @@
 //
 // Example usage:
 //
-//	client, _ := pbsclient.New("https://pbs.example.com")
+//	client, _ := pbsclient.New("https://pbs.example.com:8007",
+//		pbsclient.WithToken("backup@pbs!goback:secret"))
 //	resp, err := client.Ping()
+//	usage, err := client.DatastoreUsage(ctx)
+//	snapshots, err := client.ListSnapshots(ctx, "backups", pbsclient.SnapshotFilter{BackupType: "vm"})
+//	upid, err := client.StartGarbageCollection(ctx, "backups")
+//	status, err := client.TaskStatus(ctx, upid)
 //
-// Currently, only the Ping method is implemented.
+// Ping does not require authentication; all other methods need an API token, or a user
+// logged in with WithTicketAuth, with sufficient privileges on the datastores involved.
 package pbsclient
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/nomis52/goback/clients/pveapi"
 )
 
 const (
 	// defaultHTTPTimeout is the default timeout for HTTP requests to PBS
 	defaultHTTPTimeout = 10 * time.Second
+
+	// taskNode is the node name used in task paths. PBS is not clustered so the local node is always used.
+	taskNode = "localhost"
 )
 
 // Option is a function that configures a Client
@@ -33,12 +47,41 @@ func WithLogger(logger *slog.Logger) Option {
 	}
 }
 
+// WithToken sets the API token used for authentication, in the form
+// "user@realm!tokenname:secret"
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithTicketAuth authenticates with a ticket obtained by logging in with a username
+// (e.g. "backup@pbs") and password, instead of an API token.
+func WithTicketAuth(username, password string) Option {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for requests, e.g. one created by
+// pveapi.NewHTTPClient to trust the server's CA or pin its certificate.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
 // Client represents a Proxmox Backup Server API client.
 // Use New() to create a new client for a given PBS host.
 type Client struct {
-	Host   string
-	Logger *slog.Logger
-	client *http.Client
+	Host     string
+	Logger   *slog.Logger
+	token    string
+	username string
+	password string
+	auth     *pveapi.TicketAuth
+	client   *http.Client
 }
 
 // New creates a new Client for the given Proxmox Backup Server host.
@@ -66,6 +109,10 @@ func New(host string, opts ...Option) (*Client, error) {
 		opt(c)
 	}
 
+	if c.username != "" {
+		c.auth = pveapi.NewTicketAuth(c.client, host, pveapi.PBSAuthCookie, c.username, c.password)
+	}
+
 	// Set default logger if not provided
 	if c.Logger == nil {
 		c.Logger = slog.Default()
@@ -104,3 +151,314 @@ func (c *Client) Ping() (string, error) {
 	c.Logger.Debug("successfully pinged PBS server", "response", string(body))
 	return string(body), nil
 }
+
+// ListDatastores returns the datastores the token can access.
+// It calls GET /api2/json/admin/datastore
+func (c *Client) ListDatastores(ctx context.Context) ([]Datastore, error) {
+	var datastores []Datastore
+	if err := c.getJSON(ctx, "/api2/json/admin/datastore", nil, &datastores); err != nil {
+		return nil, fmt.Errorf("failed to list datastores: %w", err)
+	}
+	return datastores, nil
+}
+
+// DatastoreUsage returns the disk usage of every datastore the token can access.
+// It calls GET /api2/json/status/datastore-usage
+func (c *Client) DatastoreUsage(ctx context.Context) ([]DatastoreUsage, error) {
+	var usage []DatastoreUsage
+	if err := c.getJSON(ctx, "/api2/json/status/datastore-usage", nil, &usage); err != nil {
+		return nil, fmt.Errorf("failed to get datastore usage: %w", err)
+	}
+	return usage, nil
+}
+
+// ListNamespaces returns the namespaces of a datastore, including the root namespace.
+// It calls GET /api2/json/admin/datastore/{store}/namespace
+func (c *Client) ListNamespaces(ctx context.Context, store string) ([]Namespace, error) {
+	var namespaces []Namespace
+	if err := c.getJSON(ctx, datastorePath(store, "namespace"), nil, &namespaces); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces of %s: %w", store, err)
+	}
+	return namespaces, nil
+}
+
+// ListGroups returns the backup groups in a namespace of a datastore. An empty namespace
+// is the root namespace.
+// It calls GET /api2/json/admin/datastore/{store}/groups
+func (c *Client) ListGroups(ctx context.Context, store, namespace string) ([]BackupGroup, error) {
+	query := url.Values{}
+	if namespace != "" {
+		query.Set("ns", namespace)
+	}
+
+	var groups []BackupGroup
+	if err := c.getJSON(ctx, datastorePath(store, "groups"), query, &groups); err != nil {
+		return nil, fmt.Errorf("failed to list groups of %s: %w", store, err)
+	}
+	return groups, nil
+}
+
+// ListSnapshots returns the snapshots in a datastore that match the filter.
+// It calls GET /api2/json/admin/datastore/{store}/snapshots
+func (c *Client) ListSnapshots(ctx context.Context, store string, filter SnapshotFilter) ([]Snapshot, error) {
+	query := url.Values{}
+	if filter.Namespace != "" {
+		query.Set("ns", filter.Namespace)
+	}
+	if filter.BackupType != "" {
+		query.Set("backup-type", filter.BackupType)
+	}
+	if filter.BackupID != "" {
+		query.Set("backup-id", filter.BackupID)
+	}
+
+	var snapshots []Snapshot
+	if err := c.getJSON(ctx, datastorePath(store, "snapshots"), query, &snapshots); err != nil {
+		return nil, fmt.Errorf("failed to list snapshots of %s: %w", store, err)
+	}
+	return snapshots, nil
+}
+
+// GCStatus returns the result of the last garbage collection run on a datastore.
+// It calls GET /api2/json/admin/datastore/{store}/gc
+func (c *Client) GCStatus(ctx context.Context, store string) (*GCStatus, error) {
+	var status GCStatus
+	if err := c.getJSON(ctx, datastorePath(store, "gc"), nil, &status); err != nil {
+		return nil, fmt.Errorf("failed to get gc status of %s: %w", store, err)
+	}
+	return &status, nil
+}
+
+// StartGarbageCollection starts garbage collection on a datastore and returns the task ID.
+// It calls POST /api2/json/admin/datastore/{store}/gc
+func (c *Client) StartGarbageCollection(ctx context.Context, store string) (TaskID, error) {
+	upid, err := c.startTask(ctx, datastorePath(store, "gc"), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to start garbage collection on %s: %w", store, err)
+	}
+	return upid, nil
+}
+
+// Prune prunes the snapshots of a datastore according to the keep options and returns
+// the task ID. At least one keep option should be given; PBS keeps everything otherwise.
+// It calls POST /api2/json/admin/datastore/{store}/prune-datastore
+func (c *Client) Prune(ctx context.Context, store string, opts ...PruneOption) (TaskID, error) {
+	params := &jobParams{}
+	for _, opt := range opts {
+		opt(params)
+	}
+
+	upid, err := c.startTask(ctx, datastorePath(store, "prune-datastore"), params.params)
+	if err != nil {
+		return "", fmt.Errorf("failed to start prune on %s: %w", store, err)
+	}
+	return upid, nil
+}
+
+// Verify verifies the snapshots of a datastore and returns the task ID.
+// It calls POST /api2/json/admin/datastore/{store}/verify
+func (c *Client) Verify(ctx context.Context, store string, opts ...VerifyOption) (TaskID, error) {
+	params := &jobParams{}
+	for _, opt := range opts {
+		opt(params)
+	}
+
+	upid, err := c.startTask(ctx, datastorePath(store, "verify"), params.params)
+	if err != nil {
+		return "", fmt.Errorf("failed to start verify on %s: %w", store, err)
+	}
+	return upid, nil
+}
+
+// RunPruneJob runs a prune job configured on the PBS server and returns the task ID.
+// It calls POST /api2/json/admin/prune/{id}/run
+func (c *Client) RunPruneJob(ctx context.Context, id string) (TaskID, error) {
+	upid, err := c.startTask(ctx, "/api2/json/admin/prune/"+url.PathEscape(id)+"/run", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to run prune job %s: %w", id, err)
+	}
+	return upid, nil
+}
+
+// RunVerifyJob runs a verify job configured on the PBS server and returns the task ID.
+// It calls POST /api2/json/admin/verify/{id}/run
+func (c *Client) RunVerifyJob(ctx context.Context, id string) (TaskID, error) {
+	upid, err := c.startTask(ctx, "/api2/json/admin/verify/"+url.PathEscape(id)+"/run", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to run verify job %s: %w", id, err)
+	}
+	return upid, nil
+}
+
+// ListTasks returns the tasks that match the filter, most recent first.
+// It calls GET /api2/json/nodes/localhost/tasks
+func (c *Client) ListTasks(ctx context.Context, filter TaskFilter) ([]Task, error) {
+	query := url.Values{}
+	if filter.Running {
+		query.Set("running", "true")
+	}
+	if filter.Errors {
+		query.Set("errors", "true")
+	}
+	if filter.Store != "" {
+		query.Set("store", filter.Store)
+	}
+	if filter.TypeFilter != "" {
+		query.Set("typefilter", filter.TypeFilter)
+	}
+	if !filter.Since.IsZero() {
+		query.Set("since", strconv.FormatInt(filter.Since.Unix(), 10))
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+
+	var tasks []Task
+	if err := c.getJSON(ctx, "/api2/json/nodes/"+taskNode+"/tasks", query, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// TaskStatus returns the status of a task.
+// It calls GET /api2/json/nodes/localhost/tasks/{upid}/status
+func (c *Client) TaskStatus(ctx context.Context, upid TaskID) (*TaskStatus, error) {
+	var status TaskStatus
+	path := "/api2/json/nodes/" + taskNode + "/tasks/" + url.PathEscape(string(upid)) + "/status"
+	if err := c.getJSON(ctx, path, nil, &status); err != nil {
+		return nil, fmt.Errorf("failed to get task status: %w", err)
+	}
+	return &status, nil
+}
+
+// StopTask stops a running task.
+// It calls DELETE /api2/json/nodes/localhost/tasks/{upid}
+func (c *Client) StopTask(ctx context.Context, upid TaskID) error {
+	path := "/api2/json/nodes/" + taskNode + "/tasks/" + url.PathEscape(string(upid))
+
+	resp, err := c.doRequest(ctx, http.MethodDelete, path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to stop task: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to stop task: %w", readError(resp))
+	}
+	return nil
+}
+
+// Non-exported Methods
+
+// datastorePath returns the admin API path of an endpoint of a datastore.
+func datastorePath(store, endpoint string) string {
+	return "/api2/json/admin/datastore/" + url.PathEscape(store) + "/" + endpoint
+}
+
+// startTask performs a POST request to an endpoint that starts a task and returns its UPID.
+func (c *Client) startTask(ctx context.Context, path string, params map[string]string) (TaskID, error) {
+	query := url.Values{}
+	for k, v := range params {
+		query.Set(k, v)
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodPost, path, query)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", readError(resp)
+	}
+
+	var response taskResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	c.Logger.Debug("started PBS task", "path", path, "upid", response.Data)
+	return TaskID(response.Data), nil
+}
+
+// getJSON performs a GET request and decodes the "data" field of the response into v.
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, v any) error {
+	resp, err := c.doRequest(ctx, http.MethodGet, path, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return readError(resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	response := struct {
+		Data any `json:"data"`
+	}{Data: v}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// doRequest performs an HTTP request with the configured authentication.
+// With ticket authentication a rejected ticket is discarded and the request retried once.
+func (c *Client) doRequest(ctx context.Context, method, path string, query url.Values) (*http.Response, error) {
+	resp, err := c.send(ctx, method, path, query)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && c.auth != nil {
+		resp.Body.Close()
+		c.auth.Invalidate()
+		resp, err = c.send(ctx, method, path, query)
+	}
+	return resp, err
+}
+
+// send performs a single authenticated request.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values) (*http.Response, error) {
+	u := c.Host + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if c.auth != nil {
+		if err := c.auth.Authorize(ctx, req); err != nil {
+			return nil, err
+		}
+	} else if c.token != "" {
+		req.Header.Set("Authorization", "PBSAPIToken="+c.token)
+	}
+
+	c.Logger.Debug("PBS API request", "method", method, "url", u)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	return resp, nil
+}
+
+// readError builds an error from a non-success response, including the message PBS
+// returns if there is one.
+func readError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+
+	var pbsErr errorResponse
+	if err := json.Unmarshal(body, &pbsErr); err == nil && pbsErr.Message != "" {
+		return fmt.Errorf("unexpected status code: %d: %s", resp.StatusCode, strings.TrimSpace(pbsErr.Message))
+	}
+	if msg := strings.TrimSpace(string(body)); msg != "" && !strings.HasPrefix(msg, "{") {
+		return fmt.Errorf("unexpected status code: %d: %s", resp.StatusCode, msg)
+	}
+	return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+}
@@ -1,12 +1,16 @@
 package pbsclient
 
 import (
+	"context"
 	"errors"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -143,6 +147,301 @@ func TestPingReadError(t *testing.T) {
 	assert.Empty(t, resp)
 }
 
+const testToken = "goback@pbs!goback:secret"
+
+// newTestClient returns a client for a test server that checks the token and serves handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PBSAPIToken="+testToken, r.Header.Get("Authorization"))
+		handler(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	client, err := New(ts.URL, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))), WithToken(testToken))
+	require.NoError(t, err)
+	return client
+}
+
+func TestDatastores(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		switch r.URL.Path {
+		case "/api2/json/admin/datastore":
+			w.Write([]byte(`{"data": [{"store": "backups", "comment": "main"}, {"store": "offsite", "maintenance": "read-only"}]}`))
+		case "/api2/json/status/datastore-usage":
+			w.Write([]byte(`{"data": [
+				{"store": "backups", "total": 1000, "used": 250, "avail": 750, "estimated-full-date": 1767225600},
+				{"store": "offsite", "total": 0, "used": 0, "avail": 0, "estimated-full-date": -1, "error": "not mounted"}
+			]}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	datastores, err := client.ListDatastores(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Datastore{{Store: "backups", Comment: "main"}, {Store: "offsite", Maintenance: "read-only"}}, datastores)
+
+	usage, err := client.DatastoreUsage(context.Background())
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+	assert.Equal(t, "backups", usage[0].Store)
+	assert.Equal(t, int64(750), usage[0].Available)
+	assert.InDelta(t, 0.25, usage[0].UsedFraction(), 0.001)
+	assert.Equal(t, time.Unix(1767225600, 0), usage[0].EstimatedFullDate)
+	assert.True(t, usage[1].EstimatedFullDate.IsZero())
+	assert.Equal(t, "not mounted", usage[1].Error)
+	assert.Zero(t, usage[1].UsedFraction())
+}
+
+func TestListSnapshots(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    SnapshotFilter
+		wantQuery url.Values
+	}{
+		{
+			name:      "all snapshots",
+			wantQuery: url.Values{},
+		},
+		{
+			name:      "single group in namespace",
+			filter:    SnapshotFilter{Namespace: "pve", BackupType: "vm", BackupID: "100"},
+			wantQuery: url.Values{"ns": {"pve"}, "backup-type": {"vm"}, "backup-id": {"100"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api2/json/admin/datastore/backups/snapshots", r.URL.Path)
+				assert.Equal(t, tt.wantQuery, r.URL.Query())
+				w.Write([]byte(`{"data": [{
+					"backup-type": "vm", "backup-id": "100", "backup-time": 1700000000, "size": 4096,
+					"files": [{"filename": "drive-scsi0.img.fidx", "size": 4096, "crypt-mode": "none"}],
+					"verification": {"state": "ok", "upid": "UPID:pbs:verify"}
+				}]}`))
+			})
+
+			snapshots, err := client.ListSnapshots(context.Background(), "backups", tt.filter)
+			require.NoError(t, err)
+			require.Len(t, snapshots, 1)
+			assert.Equal(t, "vm", snapshots[0].BackupType)
+			assert.Equal(t, "100", snapshots[0].BackupID)
+			assert.Equal(t, time.Unix(1700000000, 0), snapshots[0].BackupTime)
+			assert.Equal(t, []SnapshotFile{{Filename: "drive-scsi0.img.fidx", Size: 4096, CryptMode: "none"}}, snapshots[0].Files)
+			require.NotNil(t, snapshots[0].Verification)
+			assert.Equal(t, "ok", snapshots[0].Verification.State)
+		})
+	}
+}
+
+func TestListGroups(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api2/json/admin/datastore/backups/namespace":
+			w.Write([]byte(`{"data": [{"ns": ""}, {"ns": "pve"}]}`))
+		case "/api2/json/admin/datastore/backups/groups":
+			assert.Equal(t, "pve", r.URL.Query().Get("ns"))
+			w.Write([]byte(`{"data": [{"backup-type": "ct", "backup-id": "101", "last-backup": 1700000000, "backup-count": 3}]}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	namespaces, err := client.ListNamespaces(context.Background(), "backups")
+	require.NoError(t, err)
+	assert.Equal(t, []Namespace{{NS: ""}, {NS: "pve"}}, namespaces)
+
+	groups, err := client.ListGroups(context.Background(), "backups", "pve")
+	require.NoError(t, err)
+	assert.Equal(t, []BackupGroup{{BackupType: "ct", BackupID: "101", LastBackup: time.Unix(1700000000, 0), BackupCount: 3}}, groups)
+}
+
+func TestStartTask(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     func(*Client) (TaskID, error)
+		wantPath  string
+		wantQuery url.Values
+	}{
+		{
+			name:      "garbage collection",
+			start:     func(c *Client) (TaskID, error) { return c.StartGarbageCollection(context.Background(), "backups") },
+			wantPath:  "/api2/json/admin/datastore/backups/gc",
+			wantQuery: url.Values{},
+		},
+		{
+			name: "prune",
+			start: func(c *Client) (TaskID, error) {
+				return c.Prune(context.Background(), "backups", WithKeepLast(3), WithKeepDaily(7), WithPruneNamespace("pve"), WithDryRun(true))
+			},
+			wantPath:  "/api2/json/admin/datastore/backups/prune-datastore",
+			wantQuery: url.Values{"keep-last": {"3"}, "keep-daily": {"7"}, "ns": {"pve"}, "dry-run": {"true"}},
+		},
+		{
+			name: "verify",
+			start: func(c *Client) (TaskID, error) {
+				return c.Verify(context.Background(), "backups", WithIgnoreVerified(true), WithOutdatedAfter(30))
+			},
+			wantPath:  "/api2/json/admin/datastore/backups/verify",
+			wantQuery: url.Values{"ignore-verified": {"true"}, "outdated-after": {"30"}},
+		},
+		{
+			name: "verify snapshot",
+			start: func(c *Client) (TaskID, error) {
+				return c.Verify(context.Background(), "backups", WithVerifySnapshot("vm", "100", time.Unix(1700000000, 0)))
+			},
+			wantPath:  "/api2/json/admin/datastore/backups/verify",
+			wantQuery: url.Values{"backup-type": {"vm"}, "backup-id": {"100"}, "backup-time": {"1700000000"}},
+		},
+		{
+			name:      "prune job",
+			start:     func(c *Client) (TaskID, error) { return c.RunPruneJob(context.Background(), "daily") },
+			wantPath:  "/api2/json/admin/prune/daily/run",
+			wantQuery: url.Values{},
+		},
+		{
+			name:      "verify job",
+			start:     func(c *Client) (TaskID, error) { return c.RunVerifyJob(context.Background(), "weekly") },
+			wantPath:  "/api2/json/admin/verify/weekly/run",
+			wantQuery: url.Values{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, tt.wantPath, r.URL.Path)
+				assert.Equal(t, tt.wantQuery, r.URL.Query())
+				w.Write([]byte(`{"data": "UPID:pbs:0000:task"}`))
+			})
+
+			upid, err := tt.start(client)
+			require.NoError(t, err)
+			assert.Equal(t, TaskID("UPID:pbs:0000:task"), upid)
+		})
+	}
+}
+
+func TestTasks(t *testing.T) {
+	const upid = "UPID:pbs:000A:0001:garbage_collection:backups:root@pam:"
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/api2/json/nodes/localhost/tasks":
+			assert.Equal(t, url.Values{"store": {"backups"}, "running": {"true"}, "since": {"1700000000"}, "limit": {"10"}}, r.URL.Query())
+			w.Write([]byte(`{"data": [{"upid": "` + upid + `", "node": "pbs", "worker_type": "garbage_collection", "worker_id": "backups", "user": "root@pam", "starttime": 1700000100}]}`))
+		case "/api2/json/nodes/localhost/tasks/" + url.PathEscape(upid):
+			assert.Equal(t, http.MethodDelete, r.Method)
+			w.Write([]byte(`{"data": null}`))
+		case "/api2/json/nodes/localhost/tasks/" + url.PathEscape(upid) + "/status":
+			w.Write([]byte(`{"data": {"upid": "` + upid + `", "type": "garbage_collection", "status": "stopped", "exitstatus": "OK", "starttime": 1700000100}}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.EscapedPath())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	tasks, err := client.ListTasks(context.Background(), TaskFilter{Running: true, Store: "backups", Since: time.Unix(1700000000, 0), Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, TaskID(upid), tasks[0].UPID)
+	assert.Equal(t, "garbage_collection", tasks[0].WorkerType)
+	assert.Equal(t, time.Unix(1700000100, 0), tasks[0].StartTime)
+	assert.True(t, tasks[0].EndTime.IsZero())
+
+	status, err := client.TaskStatus(context.Background(), TaskID(upid))
+	require.NoError(t, err)
+	assert.False(t, status.Running())
+	assert.True(t, status.Succeeded())
+
+	require.NoError(t, client.StopTask(context.Background(), TaskID(upid)))
+}
+
+func TestRequestErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{
+			name:    "json message",
+			status:  http.StatusBadRequest,
+			body:    `{"data": null, "message": "datastore 'backups' is in maintenance mode\n"}`,
+			wantErr: "failed to start garbage collection on backups: unexpected status code: 400: datastore 'backups' is in maintenance mode",
+		},
+		{
+			name:    "plain text",
+			status:  http.StatusForbidden,
+			body:    "permission check failed\n",
+			wantErr: "unexpected status code: 403: permission check failed",
+		},
+		{
+			name:    "no body",
+			status:  http.StatusInternalServerError,
+			wantErr: "unexpected status code: 500",
+		},
+		{
+			name:    "invalid json",
+			status:  http.StatusOK,
+			body:    "invalid",
+			wantErr: "failed to unmarshal response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := client.StartGarbageCollection(context.Background(), "backups")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestTicketAuth(t *testing.T) {
+	var logins int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api2/json/access/ticket" {
+			logins++
+			w.Write([]byte(`{"data": {"ticket": "PBS:ticket-` + strconv.Itoa(logins) + `", "CSRFPreventionToken": "csrf"}}`))
+			return
+		}
+
+		assert.Empty(t, r.Header.Get("Authorization"))
+		cookie, err := r.Cookie("PBSAuthCookie")
+		if !assert.NoError(t, err) || cookie.Value == "PBS:ticket-1" {
+			// The first ticket has been revoked
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "csrf", r.Header.Get("CSRFPreventionToken"))
+		w.Write([]byte(`{"data": "UPID:pbs:gc"}`))
+	}))
+	defer ts.Close()
+
+	client, err := New(ts.URL,
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		WithTicketAuth("backup@pbs", "secret"),
+		WithHTTPClient(ts.Client()))
+	require.NoError(t, err)
+
+	upid, err := client.StartGarbageCollection(context.Background(), "backups")
+	require.NoError(t, err)
+	assert.Equal(t, TaskID("UPID:pbs:gc"), upid)
+	assert.Equal(t, 2, logins, "a rejected ticket should be renewed once")
+}
+
 // Test helper types
 
 type errorReader struct{}
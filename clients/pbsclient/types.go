@@ -0,0 +1,340 @@
+package pbsclient
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// TaskID is a PBS task identifier (UPID).
+type TaskID string
+
+// Task states reported by PBS.
+const (
+	TaskStatusRunning = "running"
+	TaskStatusStopped = "stopped"
+
+	// TaskExitOK is the exit status of a task that completed successfully.
+	TaskExitOK = "OK"
+)
+
+// Datastore is a datastore configured on the PBS server.
+type Datastore struct {
+	Store       string `json:"store"`
+	Comment     string `json:"comment,omitempty"`
+	Maintenance string `json:"maintenance,omitempty"`
+}
+
+// DatastoreUsage is the disk usage of a datastore.
+type DatastoreUsage struct {
+	Store     string `json:"store"`
+	Total     int64  `json:"total"`
+	Used      int64  `json:"used"`
+	Available int64  `json:"avail"`
+	// EstimatedFullDate is PBS's estimate of when the datastore fills up. It is zero if
+	// PBS has not collected enough history or usage is not growing.
+	EstimatedFullDate time.Time `json:"-"`
+	// Error is set if PBS could not determine the usage of the datastore.
+	Error string `json:"error,omitempty"`
+}
+
+// UsedFraction returns the fraction of the datastore that is in use.
+func (u DatastoreUsage) UsedFraction() float64 {
+	if u.Total == 0 {
+		return 0
+	}
+	return float64(u.Used) / float64(u.Total)
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for DatastoreUsage to handle Unix timestamp conversion
+func (u *DatastoreUsage) UnmarshalJSON(data []byte) error {
+	type usageAlias DatastoreUsage
+	temp := struct {
+		*usageAlias
+		EstimatedFullDate int64 `json:"estimated-full-date"`
+	}{
+		usageAlias: (*usageAlias)(u),
+	}
+
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+
+	// PBS reports 0 for "never" and -1 for "not enough data"
+	if temp.EstimatedFullDate > 0 {
+		u.EstimatedFullDate = time.Unix(temp.EstimatedFullDate, 0)
+	}
+	return nil
+}
+
+// GCStatus is the result of the last garbage collection run on a datastore.
+type GCStatus struct {
+	UPID           TaskID `json:"upid,omitempty"`
+	IndexFileCount int64  `json:"index-file-count"`
+	IndexDataBytes int64  `json:"index-data-bytes"`
+	DiskBytes      int64  `json:"disk-bytes"`
+	DiskChunks     int64  `json:"disk-chunks"`
+	RemovedBytes   int64  `json:"removed-bytes"`
+	RemovedChunks  int64  `json:"removed-chunks"`
+	PendingBytes   int64  `json:"pending-bytes"`
+	PendingChunks  int64  `json:"pending-chunks"`
+	RemovedBad     int64  `json:"removed-bad"`
+	StillBad       int64  `json:"still-bad"`
+}
+
+// Namespace is a backup namespace within a datastore. The root namespace has an empty name.
+type Namespace struct {
+	NS      string `json:"ns"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// BackupGroup is a set of snapshots of the same backup source, e.g. "vm/100".
+type BackupGroup struct {
+	BackupType  string    `json:"backup-type"` // "vm", "ct" or "host"
+	BackupID    string    `json:"backup-id"`
+	LastBackup  time.Time `json:"-"`
+	BackupCount int       `json:"backup-count"`
+	Owner       string    `json:"owner,omitempty"`
+	Comment     string    `json:"comment,omitempty"`
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for BackupGroup to handle Unix timestamp conversion
+func (g *BackupGroup) UnmarshalJSON(data []byte) error {
+	type groupAlias BackupGroup
+	temp := struct {
+		*groupAlias
+		LastBackup int64 `json:"last-backup"`
+	}{
+		groupAlias: (*groupAlias)(g),
+	}
+
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+
+	g.LastBackup = time.Unix(temp.LastBackup, 0)
+	return nil
+}
+
+// Snapshot is a single backup snapshot in a datastore.
+type Snapshot struct {
+	BackupType   string         `json:"backup-type"`
+	BackupID     string         `json:"backup-id"`
+	BackupTime   time.Time      `json:"-"`
+	Size         int64          `json:"size,omitempty"`
+	Protected    bool           `json:"protected,omitempty"`
+	Comment      string         `json:"comment,omitempty"`
+	Owner        string         `json:"owner,omitempty"`
+	Files        []SnapshotFile `json:"files,omitempty"`
+	Verification *Verification  `json:"verification,omitempty"`
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for Snapshot to handle Unix timestamp conversion
+func (s *Snapshot) UnmarshalJSON(data []byte) error {
+	type snapshotAlias Snapshot
+	temp := struct {
+		*snapshotAlias
+		BackupTime int64 `json:"backup-time"`
+	}{
+		snapshotAlias: (*snapshotAlias)(s),
+	}
+
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+
+	s.BackupTime = time.Unix(temp.BackupTime, 0)
+	return nil
+}
+
+// SnapshotFile is an archive stored in a snapshot.
+type SnapshotFile struct {
+	Filename  string `json:"filename"`
+	Size      int64  `json:"size,omitempty"`
+	CryptMode string `json:"crypt-mode,omitempty"`
+}
+
+// Verification is the result of the last verification of a snapshot.
+type Verification struct {
+	State string `json:"state"` // "ok" or "failed"
+	UPID  TaskID `json:"upid"`
+}
+
+// SnapshotFilter restricts the snapshots returned by ListSnapshots.
+// Empty fields match everything.
+type SnapshotFilter struct {
+	Namespace  string
+	BackupType string
+	BackupID   string
+}
+
+// Task is an entry in the PBS task list.
+type Task struct {
+	UPID       TaskID    `json:"upid"`
+	Node       string    `json:"node"`
+	WorkerType string    `json:"worker_type"` // e.g. "garbage_collection", "prune", "verify"
+	WorkerID   string    `json:"worker_id,omitempty"`
+	User       string    `json:"user"`
+	StartTime  time.Time `json:"-"`
+	EndTime    time.Time `json:"-"` // zero while the task is running
+	// Status is the exit status of a finished task, e.g. "OK" or an error message.
+	Status string `json:"status,omitempty"`
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for Task to handle Unix timestamp conversion
+func (t *Task) UnmarshalJSON(data []byte) error {
+	type taskAlias Task
+	temp := struct {
+		*taskAlias
+		StartTime int64 `json:"starttime"`
+		EndTime   int64 `json:"endtime"`
+	}{
+		taskAlias: (*taskAlias)(t),
+	}
+
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+
+	t.StartTime = time.Unix(temp.StartTime, 0)
+	if temp.EndTime != 0 {
+		t.EndTime = time.Unix(temp.EndTime, 0)
+	}
+	return nil
+}
+
+// TaskFilter restricts the tasks returned by ListTasks.
+// Zero fields match everything.
+type TaskFilter struct {
+	Running    bool      // only running tasks
+	Errors     bool      // only tasks that failed
+	Store      string    // only tasks for this datastore
+	TypeFilter string    // only tasks of this worker type
+	Since      time.Time // only tasks started at or after this time
+	Limit      int       // maximum number of tasks to return
+}
+
+// TaskStatus is the status of a single task.
+// See: GET /api2/json/nodes/{node}/tasks/{upid}/status
+type TaskStatus struct {
+	UPID       TaskID `json:"upid"`
+	Type       string `json:"type"`
+	ID         string `json:"id,omitempty"`
+	User       string `json:"user"`
+	Status     string `json:"status"`               // "running" or "stopped"
+	ExitStatus string `json:"exitstatus,omitempty"` // set once stopped, "OK" on success
+	StartTime  int64  `json:"starttime"`
+}
+
+// Running reports whether the task is still running.
+func (s TaskStatus) Running() bool {
+	return s.Status == TaskStatusRunning
+}
+
+// Succeeded reports whether the task has stopped with an OK exit status.
+func (s TaskStatus) Succeeded() bool {
+	return s.Status == TaskStatusStopped && s.ExitStatus == TaskExitOK
+}
+
+// PruneOption is a function that configures prune parameters
+type PruneOption func(*jobParams)
+
+// WithKeepLast keeps the last n snapshots of each group
+func WithKeepLast(n int) PruneOption {
+	return func(p *jobParams) { p.set("keep-last", strconv.Itoa(n)) }
+}
+
+// WithKeepHourly keeps one snapshot for each of the last n hours
+func WithKeepHourly(n int) PruneOption {
+	return func(p *jobParams) { p.set("keep-hourly", strconv.Itoa(n)) }
+}
+
+// WithKeepDaily keeps one snapshot for each of the last n days
+func WithKeepDaily(n int) PruneOption {
+	return func(p *jobParams) { p.set("keep-daily", strconv.Itoa(n)) }
+}
+
+// WithKeepWeekly keeps one snapshot for each of the last n weeks
+func WithKeepWeekly(n int) PruneOption {
+	return func(p *jobParams) { p.set("keep-weekly", strconv.Itoa(n)) }
+}
+
+// WithKeepMonthly keeps one snapshot for each of the last n months
+func WithKeepMonthly(n int) PruneOption {
+	return func(p *jobParams) { p.set("keep-monthly", strconv.Itoa(n)) }
+}
+
+// WithKeepYearly keeps one snapshot for each of the last n years
+func WithKeepYearly(n int) PruneOption {
+	return func(p *jobParams) { p.set("keep-yearly", strconv.Itoa(n)) }
+}
+
+// WithPruneNamespace limits pruning to the given namespace
+func WithPruneNamespace(ns string) PruneOption {
+	return func(p *jobParams) { p.set("ns", ns) }
+}
+
+// WithDryRun reports what would be pruned without removing anything
+func WithDryRun(dryRun bool) PruneOption {
+	return func(p *jobParams) { p.set("dry-run", strconv.FormatBool(dryRun)) }
+}
+
+// VerifyOption is a function that configures verify parameters
+type VerifyOption func(*jobParams)
+
+// WithIgnoreVerified skips snapshots that have already been verified
+func WithIgnoreVerified(ignore bool) VerifyOption {
+	return func(p *jobParams) { p.set("ignore-verified", strconv.FormatBool(ignore)) }
+}
+
+// WithOutdatedAfter re-verifies snapshots whose last verification is older than the given
+// number of days. It only has an effect together with WithIgnoreVerified.
+func WithOutdatedAfter(days int) VerifyOption {
+	return func(p *jobParams) { p.set("outdated-after", strconv.Itoa(days)) }
+}
+
+// WithVerifyNamespace limits verification to the given namespace
+func WithVerifyNamespace(ns string) VerifyOption {
+	return func(p *jobParams) { p.set("ns", ns) }
+}
+
+// WithVerifyGroup limits verification to a single backup group
+func WithVerifyGroup(backupType, backupID string) VerifyOption {
+	return func(p *jobParams) {
+		p.set("backup-type", backupType)
+		p.set("backup-id", backupID)
+	}
+}
+
+// WithVerifySnapshot limits verification to a single snapshot
+func WithVerifySnapshot(backupType, backupID string, backupTime time.Time) VerifyOption {
+	return func(p *jobParams) {
+		p.set("backup-type", backupType)
+		p.set("backup-id", backupID)
+		p.set("backup-time", strconv.FormatInt(backupTime.Unix(), 10))
+	}
+}
+
+// Internal types
+
+// jobParams holds the parameters of a prune or verify request
+type jobParams struct {
+	params map[string]string
+}
+
+func (p *jobParams) set(key, value string) {
+	if p.params == nil {
+		p.params = make(map[string]string)
+	}
+	p.params[key] = value
+}
+
+// taskResponse is the response from an endpoint that starts a task
+type taskResponse struct {
+	Data string `json:"data"`
+}
+
+// errorResponse is the body PBS returns with a failed request
+type errorResponse struct {
+	Message string `json:"message"`
+}
@@ -2,13 +2,16 @@
 //
 // Example usage:
 //
-//	client, err := proxmoxclient.New("https://proxmox.example.com")
+//	client, err := proxmoxclient.New("https://proxmox.example.com:8006",
+//		proxmoxclient.WithTicketAuth("root@pam", "secret"))
 //	if err != nil {
 //		log.Fatal(err)
 //	}
 //	version, err := client.Version()
 //	vms, err := client.ListComputeResources(ctx)
-//	backups, err := client.ListBackups(ctx, "pve2", "pbs")
+//	nodes, err := client.FindStorage(ctx, "pbs")
+//	backups, err := client.ListBackups(ctx, nodes[0].Node, "pbs")
+//	taskID, err := client.Restore(ctx, "pve2", proxmoxclient.GuestTypeQEMU, 9999, backups[0].VolID, "local-lvm")
 package proxmoxclient
 
 import (
@@ -19,7 +22,10 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+
+	"github.com/nomis52/goback/clients/pveapi"
 )
 
 // Option is a function that configures a Client
@@ -28,9 +34,13 @@ type Option func(*Client)
 // Client represents a Proxmox VE API client.
 // Use New() to create a new client for a given Proxmox host.
 type Client struct {
-	baseURL *url.URL
-	token   string
-	logger  *slog.Logger
+	baseURL  *url.URL
+	token    string
+	username string
+	password string
+	auth     *pveapi.TicketAuth
+	client   *http.Client
+	logger   *slog.Logger
 }
 
 // New and Options
@@ -42,6 +52,23 @@ func WithToken(token string) Option {
 	}
 }
 
+// WithTicketAuth authenticates with a ticket obtained by logging in with a username
+// (e.g. "root@pam") and password, instead of an API token.
+func WithTicketAuth(username, password string) Option {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for requests, e.g. one created by
+// pveapi.NewHTTPClient to trust the cluster's CA or pin its certificate.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
 // WithLogger sets the logger for the client
 func WithLogger(logger *slog.Logger) Option {
 	return func(c *Client) {
@@ -60,6 +87,7 @@ func New(host string, opts ...Option) (*Client, error) {
 
 	client := &Client{
 		baseURL: baseURL,
+		client:  http.DefaultClient,
 		logger:  slog.Default(),
 	}
 
@@ -67,6 +95,10 @@ func New(host string, opts ...Option) (*Client, error) {
 		opt(client)
 	}
 
+	if client.username != "" {
+		client.auth = pveapi.NewTicketAuth(client.client, baseURL.String(), pveapi.PVEAuthCookie, client.username, client.password)
+	}
+
 	return client, nil
 }
 
@@ -141,6 +173,90 @@ func (c *Client) ListComputeResources(ctx context.Context) ([]Resource, error) {
 	return resources, nil
 }
 
+// ListNodes retrieves the nodes of the Proxmox cluster and their status.
+// It queries the /api2/json/nodes endpoint.
+func (c *Client) ListNodes(ctx context.Context) ([]Node, error) {
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api2/json/nodes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Data []Node `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	slices.SortFunc(response.Data, func(a, b Node) int {
+		return strings.Compare(a.Node, b.Node)
+	})
+	return response.Data, nil
+}
+
+// ListStorage retrieves the storages available on a node.
+// It queries the /api2/json/nodes/{node}/storage endpoint.
+func (c *Client) ListStorage(ctx context.Context, node string) ([]Storage, error) {
+	path := fmt.Sprintf("/api2/json/nodes/%s/storage", node)
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Data []Storage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return response.Data, nil
+}
+
+// FindStorage returns the online nodes on which storage is enabled and active, sorted by
+// node name. Offline nodes, and nodes whose storage can't be listed, are skipped with a warning.
+// It returns an error if no online node can use the storage.
+func (c *Client) FindStorage(ctx context.Context, storage string) ([]NodeStorage, error) {
+	nodes, err := c.ListNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var found []NodeStorage
+	for _, node := range nodes {
+		if !node.Online() {
+			c.logger.Warn("Skipping offline Proxmox node", "node", node.Node, "status", node.Status)
+			continue
+		}
+
+		storages, err := c.ListStorage(ctx, node.Node)
+		if err != nil {
+			c.logger.Warn("Failed to list storage on Proxmox node", "node", node.Node, "error", err)
+			continue
+		}
+		for _, s := range storages {
+			if s.Storage == storage && s.IsUsable() {
+				found = append(found, NodeStorage{Node: node.Node, Storage: s})
+			}
+		}
+	}
+
+	if len(found) == 0 {
+		return nil, fmt.Errorf("storage %q is not enabled and active on any online node", storage)
+	}
+	return found, nil
+}
+
 // ListBackups retrieves all backups from a specific storage on a specific node.
 // It queries the /api2/json/nodes/{node}/storage/{storage}/content endpoint with content=backup filter.
 // The node parameter specifies which Proxmox node to query (e.g., "pve2").
@@ -276,8 +392,197 @@ func (c *Client) TaskStatus(ctx context.Context, node string, taskID TaskID) (*T
 	return &response.Data, nil
 }
 
+// TaskLog retrieves up to limit lines of a task's log, skipping the first start lines.
+// It calls /api2/json/nodes/{node}/tasks/{upid}/log
+// Callers page through a running task's log by passing the N of the last line received as start.
+func (c *Client) TaskLog(ctx context.Context, node string, taskID TaskID, start, limit int) ([]TaskLogLine, error) {
+	path := fmt.Sprintf("/api2/json/nodes/%s/tasks/%s/log?start=%d&limit=%d", node, string(taskID), start, limit)
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute task log request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var response struct {
+		Data []TaskLogLine `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return response.Data, nil
+}
+
+// StopTask stops a running task by its UPID (TaskID) on a given node.
+// It calls DELETE /api2/json/nodes/{node}/tasks/{upid}
+func (c *Client) StopTask(ctx context.Context, node string, taskID TaskID) error {
+	path := fmt.Sprintf("/api2/json/nodes/%s/tasks/%s", node, string(taskID))
+
+	resp, err := c.doRequest(ctx, http.MethodDelete, path)
+	if err != nil {
+		return fmt.Errorf("failed to execute stop task request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Restore restores a backup archive to a new guest with the given VMID and returns the task ID.
+// guestType is GuestTypeQEMU or GuestTypeLXC; archive is the backup's volume ID and storage
+// is where the restored disks are created. The guest is given new unique MAC addresses.
+// It calls POST /api2/json/nodes/{node}/qemu or POST /api2/json/nodes/{node}/lxc
+func (c *Client) Restore(ctx context.Context, node, guestType string, vmid VMID, archive, storage string) (TaskID, error) {
+	params := url.Values{}
+	params.Set("vmid", fmt.Sprintf("%d", vmid))
+	params.Set("storage", storage)
+	params.Set("unique", "1")
+
+	switch guestType {
+	case GuestTypeQEMU:
+		params.Set("archive", archive)
+	case GuestTypeLXC:
+		params.Set("ostemplate", archive)
+		params.Set("restore", "1")
+	default:
+		return "", fmt.Errorf("unsupported guest type: %q", guestType)
+	}
+
+	path := fmt.Sprintf("/api2/json/nodes/%s/%s?%s", node, guestType, params.Encode())
+	taskID, err := c.startTask(ctx, http.MethodPost, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to restore %s to VMID %d: %w", archive, vmid, err)
+	}
+	return taskID, nil
+}
+
+// GuestConfig returns the current configuration of a guest as a map of option name to value.
+// It calls GET /api2/json/nodes/{node}/{type}/{vmid}/config
+func (c *Client) GuestConfig(ctx context.Context, node, guestType string, vmid VMID) (map[string]any, error) {
+	path := fmt.Sprintf("/api2/json/nodes/%s/%s/%d/config", node, guestType, vmid)
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return response.Data, nil
+}
+
+// DeleteGuestConfig removes options, such as network devices, from a guest's configuration.
+// It calls PUT /api2/json/nodes/{node}/{type}/{vmid}/config
+func (c *Client) DeleteGuestConfig(ctx context.Context, node, guestType string, vmid VMID, keys []string) error {
+	params := url.Values{}
+	params.Set("delete", strings.Join(keys, ","))
+	path := fmt.Sprintf("/api2/json/nodes/%s/%s/%d/config?%s", node, guestType, vmid, params.Encode())
+
+	resp, err := c.doRequest(ctx, http.MethodPut, path)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// StartGuest starts a guest and returns the task ID.
+// It calls POST /api2/json/nodes/{node}/{type}/{vmid}/status/start
+func (c *Client) StartGuest(ctx context.Context, node, guestType string, vmid VMID) (TaskID, error) {
+	path := fmt.Sprintf("/api2/json/nodes/%s/%s/%d/status/start", node, guestType, vmid)
+	taskID, err := c.startTask(ctx, http.MethodPost, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to start VMID %d: %w", vmid, err)
+	}
+	return taskID, nil
+}
+
+// StopGuest immediately stops a guest, without a clean shutdown, and returns the task ID.
+// It calls POST /api2/json/nodes/{node}/{type}/{vmid}/status/stop
+func (c *Client) StopGuest(ctx context.Context, node, guestType string, vmid VMID) (TaskID, error) {
+	path := fmt.Sprintf("/api2/json/nodes/%s/%s/%d/status/stop", node, guestType, vmid)
+	taskID, err := c.startTask(ctx, http.MethodPost, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to stop VMID %d: %w", vmid, err)
+	}
+	return taskID, nil
+}
+
+// DestroyGuest destroys a stopped guest and all of its disks and returns the task ID.
+// It calls DELETE /api2/json/nodes/{node}/{type}/{vmid}
+func (c *Client) DestroyGuest(ctx context.Context, node, guestType string, vmid VMID) (TaskID, error) {
+	path := fmt.Sprintf("/api2/json/nodes/%s/%s/%d?purge=1&destroy-unreferenced-disks=1", node, guestType, vmid)
+	taskID, err := c.startTask(ctx, http.MethodDelete, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to destroy VMID %d: %w", vmid, err)
+	}
+	return taskID, nil
+}
+
+// AgentPing checks that the QEMU guest agent of a running VM responds.
+// It calls POST /api2/json/nodes/{node}/qemu/{vmid}/agent/ping
+func (c *Client) AgentPing(ctx context.Context, node string, vmid VMID) error {
+	path := fmt.Sprintf("/api2/json/nodes/%s/qemu/%d/agent/ping", node, vmid)
+
+	resp, err := c.doRequest(ctx, http.MethodPost, path)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
 // Non-exported Methods
 
+// startTask performs a request that starts a task and returns the task ID from the response.
+func (c *Client) startTask(ctx context.Context, method, path string) (TaskID, error) {
+	resp, err := c.doRequest(ctx, method, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var response backupTaskResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return TaskID(response.Data), nil
+}
+
 // buildURL constructs a proper URL by joining the base host with the given path.
 // It handles cases where the host may or may not have a trailing slash.
 func (c *Client) buildURL(path string) (string, error) {
@@ -290,8 +595,20 @@ func (c *Client) buildURL(path string) (string, error) {
 	return resolvedURL.String(), nil
 }
 
-// doRequest performs an HTTP request with the configured authentication
+// doRequest performs an HTTP request with the configured authentication.
+// With ticket authentication a rejected ticket is discarded and the request retried once.
 func (c *Client) doRequest(ctx context.Context, method, path string) (*http.Response, error) {
+	resp, err := c.send(ctx, method, path)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && c.auth != nil {
+		resp.Body.Close()
+		c.auth.Invalidate()
+		resp, err = c.send(ctx, method, path)
+	}
+	return resp, err
+}
+
+// send performs a single authenticated HTTP request
+func (c *Client) send(ctx context.Context, method, path string) (*http.Response, error) {
 	url, err := c.buildURL(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build URL: %w", err)
@@ -302,7 +619,11 @@ func (c *Client) doRequest(ctx context.Context, method, path string) (*http.Resp
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	if c.token != "" {
+	if c.auth != nil {
+		if err := c.auth.Authorize(ctx, req); err != nil {
+			return nil, err
+		}
+	} else if c.token != "" {
 		req.Header.Set("Authorization", fmt.Sprintf("PVEAPIToken=%s", c.token))
 	}
 
@@ -311,5 +632,5 @@ func (c *Client) doRequest(ctx context.Context, method, path string) (*http.Resp
 		"method", method,
 		"url", url)
 
-	return http.DefaultClient.Do(req)
+	return c.client.Do(req)
 }
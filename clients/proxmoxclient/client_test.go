@@ -6,6 +6,9 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -98,7 +101,9 @@ func TestListComputeResources(t *testing.T) {
 						"maxdisk": 68719476736,
 						"cpu": 0.0,
 						"mem": 0,
-						"uptime": 0
+						"uptime": 0,
+						"tags": "prod;db",
+						"pool": "critical"
 					}
 				]
 			}`,
@@ -107,6 +112,9 @@ func TestListComputeResources(t *testing.T) {
 				require.Len(t, resources, 2)
 				assert.Equal(t, VMID(100), resources[0].VMID)
 				assert.Equal(t, "web-server", resources[0].Name)
+				assert.Empty(t, resources[0].TagList())
+				assert.Equal(t, []string{"prod", "db"}, resources[1].TagList())
+				assert.Equal(t, "critical", resources[1].Pool)
 			},
 		},
 		{
@@ -258,6 +266,78 @@ func TestListBackups(t *testing.T) {
 	}
 }
 
+func TestFindStorage(t *testing.T) {
+	tests := []struct {
+		name      string
+		nodes     string
+		storage   map[string]string
+		wantNodes []string
+		wantErr   string
+	}{
+		{
+			name:  "skips offline nodes",
+			nodes: `[{"node": "pve2", "status": "online"}, {"node": "pve1", "status": "online"}, {"node": "pve3", "status": "offline"}]`,
+			storage: map[string]string{
+				"pve1": `[{"storage": "local"}, {"storage": "pbs", "shared": 1, "enabled": 1, "active": 1}]`,
+				"pve2": `[{"storage": "pbs", "shared": 1, "enabled": 1, "active": 1}]`,
+			},
+			wantNodes: []string{"pve1", "pve2"},
+		},
+		{
+			name:  "skips nodes where the storage is unusable",
+			nodes: `[{"node": "pve1", "status": "online"}, {"node": "pve2", "status": "online"}, {"node": "pve3", "status": "online"}]`,
+			storage: map[string]string{
+				"pve1": `[{"storage": "pbs", "enabled": 1, "active": 0}]`,
+				"pve2": `[{"storage": "pbs", "enabled": 1, "active": 1}]`,
+				"pve3": `[{"storage": "local"}]`,
+			},
+			wantNodes: []string{"pve2"},
+		},
+		{
+			name:    "no usable node",
+			nodes:   `[{"node": "pve1", "status": "offline"}]`,
+			wantErr: `storage "pbs" is not enabled and active on any online node`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api2/json/nodes" {
+					w.Write([]byte(`{"data": ` + tt.nodes + `}`))
+					return
+				}
+				node := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api2/json/nodes/"), "/storage")
+				storage, ok := tt.storage[node]
+				if !assert.True(t, ok, "unexpected request for %s", r.URL.Path) {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.Write([]byte(`{"data": ` + storage + `}`))
+			}))
+			defer ts.Close()
+
+			client, err := New(ts.URL, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+			require.NoError(t, err)
+
+			found, err := client.FindStorage(context.Background(), "pbs")
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			var nodes []string
+			for _, f := range found {
+				assert.Equal(t, "pbs", f.Storage.Storage)
+				nodes = append(nodes, f.Node)
+			}
+			assert.Equal(t, tt.wantNodes, nodes)
+		})
+	}
+}
+
 func TestTaskStatus(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -326,6 +406,123 @@ func TestTaskStatus(t *testing.T) {
 	}
 }
 
+func TestTaskLog(t *testing.T) {
+	tests := []struct {
+		name           string
+		start          int
+		serverResponse string
+		status         int
+		want           []TaskLogLine
+		wantErr        string
+	}{
+		{
+			name:           "first page",
+			start:          0,
+			serverResponse: `{"data": [{"n": 1, "t": "INFO: starting new backup job"}, {"n": 2, "t": "INFO: Starting Backup of VM 100 (qemu)"}], "total": 2}`,
+			status:         http.StatusOK,
+			want: []TaskLogLine{
+				{N: 1, Text: "INFO: starting new backup job"},
+				{N: 2, Text: "INFO: Starting Backup of VM 100 (qemu)"},
+			},
+		},
+		{
+			name:           "no new lines",
+			start:          2,
+			serverResponse: `{"data": [], "total": 2}`,
+			status:         http.StatusOK,
+			want:           []TaskLogLine{},
+		},
+		{
+			name:    "http error",
+			status:  http.StatusForbidden,
+			wantErr: "unexpected status code: 403",
+		},
+		{
+			name:           "invalid json",
+			serverResponse: "invalid",
+			status:         http.StatusOK,
+			wantErr:        "failed to unmarshal response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api2/json/nodes/pve2/tasks/UPID:pve2:vzdump/log", r.URL.Path)
+				assert.Equal(t, strconv.Itoa(tt.start), r.URL.Query().Get("start"))
+				assert.Equal(t, "500", r.URL.Query().Get("limit"))
+
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.serverResponse))
+			}))
+			defer ts.Close()
+
+			client, err := New(ts.URL)
+			require.NoError(t, err)
+
+			lines, err := client.TaskLog(context.Background(), "pve2", "UPID:pve2:vzdump", tt.start, 500)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, lines)
+			}
+		})
+	}
+}
+
+func TestStopTask(t *testing.T) {
+	tests := []struct {
+		name    string
+		node    string
+		taskID  TaskID
+		status  int
+		wantErr string
+	}{
+		{
+			name:   "success",
+			node:   "pve2",
+			taskID: "UPID:pve2:00000001:00000002:12345678:vzdump:100:user@host:1234567890",
+			status: http.StatusOK,
+		},
+		{
+			name:    "http error",
+			node:    "pve2",
+			taskID:  "UPID:...",
+			status:  http.StatusForbidden,
+			wantErr: "unexpected status code: 403",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodDelete, r.Method)
+				expectedPath := "/api2/json/nodes/" + tt.node + "/tasks/" + string(tt.taskID)
+				assert.Equal(t, expectedPath, r.URL.Path)
+
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"data": null}`))
+			}))
+			defer ts.Close()
+
+			client, err := New(ts.URL)
+			require.NoError(t, err)
+
+			err = client.StopTask(context.Background(), tt.node, tt.taskID)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestNewAndOptions(t *testing.T) {
 	t.Run("New with valid URL", func(t *testing.T) {
 		client, err := New("https://pve.test")
@@ -354,6 +551,37 @@ func TestNewAndOptions(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("WithTicketAuth", func(t *testing.T) {
+		var logins int
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api2/json/access/ticket" {
+				logins++
+				w.Write([]byte(`{"data": {"ticket": "ticket-` + strconv.Itoa(logins) + `", "CSRFPreventionToken": "csrf"}}`))
+				return
+			}
+
+			assert.Empty(t, r.Header.Get("Authorization"))
+			cookie, err := r.Cookie("PVEAuthCookie")
+			if !assert.NoError(t, err) || cookie.Value == "ticket-1" {
+				// The first ticket has been revoked
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.Method == http.MethodDelete {
+				assert.Equal(t, "csrf", r.Header.Get("CSRFPreventionToken"))
+			}
+			w.Write([]byte(`{"data":{"version":"1.0"}}`))
+		}))
+		defer ts.Close()
+
+		client, err := New(ts.URL, WithTicketAuth("root@pam", "secret"), WithHTTPClient(ts.Client()))
+		require.NoError(t, err)
+		_, err = client.Version()
+		require.NoError(t, err)
+		require.NoError(t, client.StopTask(context.Background(), "pve1", "UPID:pve1:task"))
+		assert.Equal(t, 2, logins, "a rejected ticket should be renewed once")
+	})
+
 	t.Run("WithLogger", func(t *testing.T) {
 		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 		_, err := New("https://pve.test", WithLogger(logger))
@@ -509,3 +737,119 @@ func TestBackup(t *testing.T) {
 		})
 	}
 }
+
+func TestGuestTasks(t *testing.T) {
+	tests := []struct {
+		name       string
+		start      func(*Client) (TaskID, error)
+		wantMethod string
+		wantPath   string
+		wantQuery  url.Values
+		wantErr    string
+	}{
+		{
+			name: "restore vm",
+			start: func(c *Client) (TaskID, error) {
+				return c.Restore(context.Background(), "pve1", GuestTypeQEMU, 9999, "pbs:backup/vm/100/2026-01-02T04:05:00Z", "local-lvm")
+			},
+			wantMethod: http.MethodPost,
+			wantPath:   "/api2/json/nodes/pve1/qemu",
+			wantQuery:  url.Values{"vmid": {"9999"}, "archive": {"pbs:backup/vm/100/2026-01-02T04:05:00Z"}, "storage": {"local-lvm"}, "unique": {"1"}},
+		},
+		{
+			name: "restore container",
+			start: func(c *Client) (TaskID, error) {
+				return c.Restore(context.Background(), "pve1", GuestTypeLXC, 9999, "pbs:backup/ct/101/2026-01-02T04:05:00Z", "local-lvm")
+			},
+			wantMethod: http.MethodPost,
+			wantPath:   "/api2/json/nodes/pve1/lxc",
+			wantQuery:  url.Values{"vmid": {"9999"}, "ostemplate": {"pbs:backup/ct/101/2026-01-02T04:05:00Z"}, "restore": {"1"}, "storage": {"local-lvm"}, "unique": {"1"}},
+		},
+		{
+			name: "restore unknown type",
+			start: func(c *Client) (TaskID, error) {
+				return c.Restore(context.Background(), "pve1", "openvz", 9999, "archive", "local-lvm")
+			},
+			wantErr: `unsupported guest type: "openvz"`,
+		},
+		{
+			name: "start",
+			start: func(c *Client) (TaskID, error) {
+				return c.StartGuest(context.Background(), "pve1", GuestTypeQEMU, 9999)
+			},
+			wantMethod: http.MethodPost,
+			wantPath:   "/api2/json/nodes/pve1/qemu/9999/status/start",
+			wantQuery:  url.Values{},
+		},
+		{
+			name:       "stop",
+			start:      func(c *Client) (TaskID, error) { return c.StopGuest(context.Background(), "pve1", GuestTypeLXC, 9999) },
+			wantMethod: http.MethodPost,
+			wantPath:   "/api2/json/nodes/pve1/lxc/9999/status/stop",
+			wantQuery:  url.Values{},
+		},
+		{
+			name: "destroy",
+			start: func(c *Client) (TaskID, error) {
+				return c.DestroyGuest(context.Background(), "pve1", GuestTypeQEMU, 9999)
+			},
+			wantMethod: http.MethodDelete,
+			wantPath:   "/api2/json/nodes/pve1/qemu/9999",
+			wantQuery:  url.Values{"purge": {"1"}, "destroy-unreferenced-disks": {"1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.wantMethod, r.Method)
+				assert.Equal(t, tt.wantPath, r.URL.Path)
+				assert.Equal(t, tt.wantQuery, r.URL.Query())
+				w.Write([]byte(`{"data": "UPID:pve1:task"}`))
+			}))
+			defer ts.Close()
+
+			client, err := New(ts.URL)
+			require.NoError(t, err)
+
+			taskID, err := tt.start(client)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, TaskID("UPID:pve1:task"), taskID)
+			}
+		})
+	}
+}
+
+func TestGuestConfig(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api2/json/nodes/pve1/qemu/9999/config":
+			w.Write([]byte(`{"data": {"name": "web", "net0": "virtio=AA:BB:CC:DD:EE:FF,bridge=vmbr0", "cores": 2}}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/api2/json/nodes/pve1/qemu/9999/config":
+			assert.Equal(t, "net0,net1", r.URL.Query().Get("delete"))
+			w.Write([]byte(`{"data": null}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api2/json/nodes/pve1/qemu/9999/agent/ping":
+			w.Write([]byte(`{"data": {}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := New(ts.URL)
+	require.NoError(t, err)
+
+	cfg, err := client.GuestConfig(context.Background(), "pve1", GuestTypeQEMU, 9999)
+	require.NoError(t, err)
+	assert.Equal(t, "web", cfg["name"])
+	assert.Contains(t, cfg, "net0")
+
+	require.NoError(t, client.DeleteGuestConfig(context.Background(), "pve1", GuestTypeQEMU, 9999, []string{"net0", "net1"}))
+	require.NoError(t, client.AgentPing(context.Background(), "pve1", 9999))
+}
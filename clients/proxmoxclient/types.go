@@ -2,6 +2,7 @@ package proxmoxclient
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -11,7 +12,14 @@ type VMID int
 // TaskID represents a Proxmox task identifier
 type TaskID string
 
-// backupTaskResponse represents the response from creating a backup task
+// Guest types, as used in Resource.Type, Backup.Subtype and API paths
+const (
+	GuestTypeQEMU = "qemu"
+	GuestTypeLXC  = "lxc"
+)
+
+// backupTaskResponse represents the response from creating a backup task, or any other
+// request that starts a task
 type backupTaskResponse struct {
 	Data string `json:"data"`
 }
@@ -29,6 +37,40 @@ type Resource struct {
 	CPU      float64 `json:"cpu"`
 	Mem      int64   `json:"mem"`
 	Uptime   int64   `json:"uptime"`
+	Tags     string  `json:"tags"` // semicolon separated, e.g. "prod;media"
+	Pool     string  `json:"pool"`
+}
+
+// TagList returns the resource's tags as a slice.
+// Proxmox separates tags with semicolons, but commas and spaces are also accepted.
+func (r Resource) TagList() []string {
+	return strings.FieldsFunc(r.Tags, func(c rune) bool {
+		return c == ';' || c == ',' || c == ' '
+	})
+}
+
+// IsTemplate reports whether the resource is a template.
+func (r Resource) IsTemplate() bool {
+	return r.Template == 1
+}
+
+// NodeStatusOnline is the status of a cluster node that is up and reachable
+const NodeStatusOnline = "online"
+
+// Node represents a node in the Proxmox cluster.
+type Node struct {
+	Node   string  `json:"node"`
+	Status string  `json:"status"` // "online", "offline" or "unknown"
+	CPU    float64 `json:"cpu"`
+	MaxCPU int     `json:"maxcpu"`
+	Mem    int64   `json:"mem"`
+	MaxMem int64   `json:"maxmem"`
+	Uptime int64   `json:"uptime"`
+}
+
+// Online reports whether the node is online.
+func (n Node) Online() bool {
+	return n.Status == NodeStatusOnline
 }
 
 // Storage represents a storage in Proxmox.
@@ -45,6 +87,22 @@ type Storage struct {
 	UsedFraction float64 `json:"used_fraction"`
 }
 
+// IsShared reports whether the storage has the same content on every node, as PBS storage does.
+func (s Storage) IsShared() bool {
+	return s.Shared == 1
+}
+
+// IsUsable reports whether the storage is enabled and currently active.
+func (s Storage) IsUsable() bool {
+	return s.Enabled == 1 && s.Active == 1
+}
+
+// NodeStorage is a storage as seen from a particular node.
+type NodeStorage struct {
+	Node string
+	Storage
+}
+
 // Backup represents a backup in Proxmox storage.
 type Backup struct {
 	Content   string    `json:"content"`
@@ -133,3 +191,10 @@ type TaskStatus struct {
 	EndTime    int64  `json:"endtime,omitempty"`
 	// Add more fields as needed from the API response
 }
+
+// TaskLogLine is a single line of a task's log
+// See: GET /api2/json/nodes/{node}/tasks/{upid}/log
+type TaskLogLine struct {
+	N    int    `json:"n"` // 1-based line number
+	Text string `json:"t"`
+}
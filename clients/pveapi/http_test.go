@@ -0,0 +1,103 @@
+package pveapi
+
+import (
+	"crypto/sha256"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": {}}`))
+	}))
+	defer ts.Close()
+
+	sum := sha256.Sum256(ts.Certificate().Raw)
+	fingerprint := FormatFingerprint(sum[:])
+
+	dir := t.TempDir()
+	caBundle := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}), 0o600))
+	emptyBundle := filepath.Join(dir, "empty.pem")
+	require.NoError(t, os.WriteFile(emptyBundle, []byte("not a certificate"), 0o600))
+
+	tests := []struct {
+		name          string
+		opts          ConnOptions
+		wantErr       string
+		wantCallError string
+	}{
+		{
+			name:          "system roots reject self-signed certificate",
+			opts:          ConnOptions{},
+			wantCallError: "certificate",
+		},
+		{
+			name: "CA bundle",
+			opts: ConnOptions{CABundle: caBundle},
+		},
+		{
+			name: "pinned fingerprint",
+			opts: ConnOptions{Fingerprint: fingerprint},
+		},
+		{
+			name: "pinned fingerprint without colons",
+			opts: ConnOptions{Fingerprint: strings.ToLower(strings.ReplaceAll(fingerprint, ":", ""))},
+		},
+		{
+			name:          "fingerprint mismatch",
+			opts:          ConnOptions{Fingerprint: strings.Repeat("00:", 31) + "00"},
+			wantCallError: "certificate fingerprint " + fingerprint + " does not match pinned fingerprint",
+		},
+		{
+			name:    "invalid fingerprint",
+			opts:    ConnOptions{Fingerprint: "AB:CD"},
+			wantErr: `invalid SHA-256 fingerprint "AB:CD"`,
+		},
+		{
+			name:    "missing CA bundle",
+			opts:    ConnOptions{CABundle: filepath.Join(dir, "missing.pem")},
+			wantErr: "failed to read CA bundle",
+		},
+		{
+			name:    "CA bundle without certificates",
+			opts:    ConnOptions{CABundle: emptyBundle},
+			wantErr: "no certificates found in CA bundle",
+		},
+		{
+			name:    "CA bundle and fingerprint",
+			opts:    ConnOptions{CABundle: caBundle, Fingerprint: fingerprint},
+			wantErr: "CA bundle and fingerprint cannot both be set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewHTTPClient(tt.opts)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			resp, err := client.Get(ts.URL)
+			if tt.wantCallError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantCallError)
+				return
+			}
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package pveapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// PVEAuthCookie is the cookie Proxmox VE reads the ticket from
+	PVEAuthCookie = "PVEAuthCookie"
+
+	// PBSAuthCookie is the cookie Proxmox Backup Server reads the ticket from
+	PBSAuthCookie = "PBSAuthCookie"
+
+	// csrfHeader carries the CSRF prevention token, required for requests that modify state
+	csrfHeader = "CSRFPreventionToken"
+
+	ticketPath = "/api2/json/access/ticket"
+
+	// ticketRenewAfter is the age at which a ticket is replaced. Proxmox tickets expire after two hours.
+	ticketRenewAfter = time.Hour
+)
+
+// TicketAuth authenticates requests with a ticket obtained by logging in with a username
+// and password. The ticket is renewed automatically before it expires.
+// It is safe for concurrent use.
+type TicketAuth struct {
+	client     *http.Client
+	host       string
+	cookieName string
+	username   string
+	password   string
+	now        func() time.Time
+
+	mu        sync.Mutex
+	ticket    string
+	csrfToken string
+	issued    time.Time
+}
+
+// NewTicketAuth creates a TicketAuth that logs in to host (including the scheme) using client.
+// cookieName is PVEAuthCookie or PBSAuthCookie.
+func NewTicketAuth(client *http.Client, host, cookieName, username, password string) *TicketAuth {
+	return &TicketAuth{
+		client:     client,
+		host:       strings.TrimSuffix(host, "/"),
+		cookieName: cookieName,
+		username:   username,
+		password:   password,
+		now:        time.Now,
+	}
+}
+
+// Authorize adds the ticket to req, along with the CSRF token if the request modifies state.
+// It logs in first if there is no ticket yet or the ticket is due for renewal.
+func (a *TicketAuth) Authorize(ctx context.Context, req *http.Request) error {
+	ticket, csrfToken, err := a.currentTicket(ctx)
+	if err != nil {
+		return err
+	}
+
+	req.AddCookie(&http.Cookie{Name: a.cookieName, Value: ticket})
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		req.Header.Set(csrfHeader, csrfToken)
+	}
+	return nil
+}
+
+// Invalidate discards the current ticket so the next request logs in again.
+// Clients call this when the host rejects the ticket.
+func (a *TicketAuth) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.ticket = ""
+}
+
+// currentTicket returns a valid ticket and CSRF token, logging in if needed.
+func (a *TicketAuth) currentTicket(ctx context.Context) (string, string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ticket != "" && a.now().Sub(a.issued) < ticketRenewAfter {
+		return a.ticket, a.csrfToken, nil
+	}
+
+	issued := a.now()
+	ticket, csrfToken, err := a.login(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	a.ticket, a.csrfToken, a.issued = ticket, csrfToken, issued
+	return ticket, csrfToken, nil
+}
+
+// login requests a new ticket and CSRF token with the username and password.
+func (a *TicketAuth) login(ctx context.Context) (string, string, error) {
+	form := url.Values{}
+	form.Set("username", a.username)
+	form.Set("password", a.password)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.host+ticketPath, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create login request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to log in as %s: %w", a.username, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("failed to log in as %s: unexpected status code: %d", a.username, resp.StatusCode)
+	}
+
+	var response struct {
+		Data struct {
+			Ticket    string `json:"ticket"`
+			CSRFToken string `json:"CSRFPreventionToken"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", "", fmt.Errorf("failed to unmarshal login response: %w", err)
+	}
+	if response.Data.Ticket == "" {
+		return "", "", fmt.Errorf("failed to log in as %s: no ticket in response", a.username)
+	}
+
+	return response.Data.Ticket, response.Data.CSRFToken, nil
+}
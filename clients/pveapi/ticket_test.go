@@ -0,0 +1,111 @@
+package pveapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTicketAuth(t *testing.T) {
+	var logins atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api2/json/access/ticket", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.NoError(t, r.ParseForm())
+		if r.PostForm.Get("username") != "root@pam" || r.PostForm.Get("password") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"data": null}`))
+			return
+		}
+		n := logins.Add(1)
+		fmt.Fprintf(w, `{"data": {"ticket": "PVE:root@pam:%d", "CSRFPreventionToken": "csrf-%d", "username": "root@pam"}}`, n, n)
+	}))
+	defer ts.Close()
+
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	auth := NewTicketAuth(ts.Client(), ts.URL+"/", PVEAuthCookie, "root@pam", "secret")
+	auth.now = func() time.Time { return now }
+
+	authorize := func(method string) *http.Request {
+		req := httptest.NewRequest(method, "https://pve.example.com/api2/json/version", nil)
+		require.NoError(t, auth.Authorize(context.Background(), req))
+		return req
+	}
+
+	// A GET logs in and carries the ticket but not the CSRF token
+	req := authorize(http.MethodGet)
+	cookie, err := req.Cookie(PVEAuthCookie)
+	require.NoError(t, err)
+	assert.Equal(t, "PVE:root@pam:1", cookie.Value)
+	assert.Empty(t, req.Header.Get("CSRFPreventionToken"))
+
+	// Requests that modify state carry the CSRF token, reusing the ticket
+	req = authorize(http.MethodPost)
+	assert.Equal(t, "csrf-1", req.Header.Get("CSRFPreventionToken"))
+	assert.Equal(t, int32(1), logins.Load())
+
+	// The ticket is renewed once it is old enough
+	now = now.Add(ticketRenewAfter)
+	req = authorize(http.MethodDelete)
+	cookie, err = req.Cookie(PVEAuthCookie)
+	require.NoError(t, err)
+	assert.Equal(t, "PVE:root@pam:2", cookie.Value)
+	assert.Equal(t, "csrf-2", req.Header.Get("CSRFPreventionToken"))
+
+	// Invalidating forces a new login
+	auth.Invalidate()
+	authorize(http.MethodGet)
+	assert.Equal(t, int32(3), logins.Load())
+}
+
+func TestTicketAuth_LoginFailed(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{
+			name:    "bad credentials",
+			status:  http.StatusUnauthorized,
+			body:    `{"data": null}`,
+			wantErr: "failed to log in as root@pam: unexpected status code: 401",
+		},
+		{
+			name:    "no ticket",
+			status:  http.StatusOK,
+			body:    `{"data": {}}`,
+			wantErr: "failed to log in as root@pam: no ticket in response",
+		},
+		{
+			name:    "invalid json",
+			status:  http.StatusOK,
+			body:    "invalid",
+			wantErr: "failed to unmarshal login response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			auth := NewTicketAuth(ts.Client(), ts.URL, PBSAuthCookie, "root@pam", "wrong")
+			req := httptest.NewRequest(http.MethodGet, ts.URL, nil)
+
+			err := auth.Authorize(context.Background(), req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
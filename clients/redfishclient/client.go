@@ -0,0 +1,372 @@
+// Package redfishclient provides a simple client for controlling a server through its BMC's
+// Redfish REST API.
+//
+// Example usage:
+//
+//	client, err := redfishclient.New("https://bmc.example.com",
+//		redfishclient.WithCredentials("admin", "password"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	state, err := client.PowerState(ctx)
+//	err = client.Reset(ctx, redfishclient.ResetGracefulShutdown)
+//	health, err := client.Health(ctx)
+//	entries, err := client.EventLog(ctx, 50)
+//
+// If no system ID is configured, the first member of /redfish/v1/Systems is used.
+// Similarly, the event log is read from the system's "EventLog" or "SEL" log service
+// unless one is configured with WithLogServiceID.
+package redfishclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultHTTPTimeout is the default timeout for HTTP requests to the BMC
+	defaultHTTPTimeout = 30 * time.Second
+
+	systemsPath = "/redfish/v1/Systems"
+)
+
+// eventLogServiceIDs are the log service IDs BMCs commonly use for the system event log.
+var eventLogServiceIDs = []string{"EventLog", "SEL", "Sel", "Log"}
+
+// Option is a function that configures a Client
+type Option func(*Client)
+
+// Client represents a Redfish API client for a single computer system.
+// Use New() to create a new client for a given BMC host.
+type Client struct {
+	baseURL      *url.URL
+	username     string
+	password     string
+	systemID     string
+	logServiceID string
+	client       *http.Client
+	logger       *slog.Logger
+
+	mu         sync.Mutex
+	systemPath string // resolved /redfish/v1/Systems/{id} path, cached after first lookup
+	logPath    string // resolved log service path, cached after first lookup
+}
+
+// New and Options
+
+// WithCredentials sets the username and password used for HTTP basic authentication
+func WithCredentials(username, password string) Option {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithSystemID sets the ID of the computer system to manage (e.g. "1" or "System.Embedded.1").
+// If not set, the first system listed by the BMC is used.
+func WithSystemID(id string) Option {
+	return func(c *Client) {
+		c.systemID = id
+	}
+}
+
+// WithLogServiceID sets the ID of the system log service read by EventLog (e.g. "SEL").
+// If not set, a well-known event log service is discovered.
+func WithLogServiceID(id string) Option {
+	return func(c *Client) {
+		c.logServiceID = id
+	}
+}
+
+// WithInsecureSkipVerify disables TLS certificate verification.
+// BMCs commonly ship with self-signed certificates.
+func WithInsecureSkipVerify(skip bool) Option {
+	return func(c *Client) {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: skip}
+		c.client.Transport = transport
+	}
+}
+
+// WithLogger sets the logger for the client
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// New creates a new Client for the given BMC host.
+// The host should include the scheme (e.g., "https://bmc.example.com").
+func New(host string, opts ...Option) (*Client, error) {
+	if !strings.HasPrefix(host, "http://") && !strings.HasPrefix(host, "https://") {
+		return nil, fmt.Errorf("host URL must include scheme (http:// or https://): %s", host)
+	}
+
+	baseURL, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host URL: %w", err)
+	}
+
+	c := &Client{
+		baseURL: baseURL,
+		client: &http.Client{
+			Timeout: defaultHTTPTimeout,
+		},
+		logger: slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.systemID != "" {
+		c.systemPath = systemsPath + "/" + url.PathEscape(c.systemID)
+	}
+
+	c.logger = c.logger.With("component", "redfishclient")
+
+	return c, nil
+}
+
+// PowerState returns the current power state of the computer system.
+// It calls GET /redfish/v1/Systems/{id}
+func (c *Client) PowerState(ctx context.Context) (PowerState, error) {
+	system, err := c.System(ctx)
+	if err != nil {
+		return PowerStateUnknown, err
+	}
+	return system.PowerState, nil
+}
+
+// System returns the computer system resource.
+// It calls GET /redfish/v1/Systems/{id}
+func (c *Client) System(ctx context.Context) (*ComputerSystem, error) {
+	path, err := c.resolveSystemPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var system ComputerSystem
+	if err := c.getJSON(ctx, path, &system); err != nil {
+		return nil, fmt.Errorf("failed to get system: %w", err)
+	}
+	return &system, nil
+}
+
+// Health returns the status and health of the computer system.
+// It calls GET /redfish/v1/Systems/{id}
+func (c *Client) Health(ctx context.Context) (Status, error) {
+	system, err := c.System(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	return system.Status, nil
+}
+
+// EventLog returns up to limit entries from the system event log, following pagination links.
+// A limit of 0 returns all entries. Entries are returned in the order the BMC lists them.
+// It calls GET /redfish/v1/Systems/{id}/LogServices/{log}/Entries
+func (c *Client) EventLog(ctx context.Context, limit int) ([]LogEntry, error) {
+	logPath, err := c.resolveLogPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []LogEntry
+	next := logPath + "/Entries"
+	for next != "" {
+		var page logEntryCollection
+		if err := c.getJSON(ctx, next, &page); err != nil {
+			return nil, fmt.Errorf("failed to get log entries: %w", err)
+		}
+		entries = append(entries, page.Members...)
+		if limit > 0 && len(entries) >= limit {
+			return entries[:limit], nil
+		}
+		next = page.NextLink
+	}
+	return entries, nil
+}
+
+// Reset performs a ComputerSystem.Reset action with the given reset type.
+// It calls POST /redfish/v1/Systems/{id}/Actions/ComputerSystem.Reset
+func (c *Client) Reset(ctx context.Context, resetType ResetType) error {
+	path, err := c.resolveSystemPath(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(resetRequest{ResetType: resetType})
+	if err != nil {
+		return fmt.Errorf("failed to marshal reset request: %w", err)
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodPost, path+"/Actions/ComputerSystem.Reset", body)
+	if err != nil {
+		return fmt.Errorf("failed to execute reset request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Redfish actions may complete synchronously (200/204) or be accepted as a task (202)
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent:
+	default:
+		return fmt.Errorf("reset %s failed: %w", resetType, readError(resp))
+	}
+
+	c.logger.Debug("Redfish reset requested", "reset_type", resetType)
+	return nil
+}
+
+// Non-exported Methods
+
+// resolveSystemPath returns the path of the managed computer system, discovering it from
+// the Systems collection if no system ID was configured.
+func (c *Client) resolveSystemPath(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.systemPath != "" {
+		return c.systemPath, nil
+	}
+
+	var collection collectionResponse
+	if err := c.getJSON(ctx, systemsPath, &collection); err != nil {
+		return "", fmt.Errorf("failed to list systems: %w", err)
+	}
+	if len(collection.Members) == 0 {
+		return "", fmt.Errorf("BMC reports no computer systems")
+	}
+
+	c.systemPath = collection.Members[0].ODataID
+	c.logger.Debug("discovered Redfish system", "path", c.systemPath)
+	return c.systemPath, nil
+}
+
+// resolveLogPath returns the path of the system's event log service, discovering it from
+// the LogServices collection if no log service ID was configured.
+func (c *Client) resolveLogPath(ctx context.Context) (string, error) {
+	systemPath, err := c.resolveSystemPath(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.logPath != "" {
+		return c.logPath, nil
+	}
+
+	servicesPath := systemPath + "/LogServices"
+	if c.logServiceID != "" {
+		c.logPath = servicesPath + "/" + url.PathEscape(c.logServiceID)
+		return c.logPath, nil
+	}
+
+	var collection collectionResponse
+	if err := c.getJSON(ctx, servicesPath, &collection); err != nil {
+		return "", fmt.Errorf("failed to list log services: %w", err)
+	}
+	if len(collection.Members) == 0 {
+		return "", fmt.Errorf("BMC reports no log services for %s", systemPath)
+	}
+
+	// Prefer a well-known event log, falling back to the first service
+	c.logPath = collection.Members[0].ODataID
+	for _, id := range eventLogServiceIDs {
+		if member := findMember(collection.Members, id); member != "" {
+			c.logPath = member
+			break
+		}
+	}
+	c.logger.Debug("discovered Redfish log service", "path", c.logPath)
+	return c.logPath, nil
+}
+
+// findMember returns the member whose path ends with the given ID, or "" if there is none.
+func findMember(members []odataLink, id string) string {
+	for _, m := range members {
+		if path.Base(strings.TrimSuffix(m.ODataID, "/")) == id {
+			return m.ODataID
+		}
+	}
+	return ""
+}
+
+// getJSON performs a GET request and decodes the JSON response into v.
+func (c *Client) getJSON(ctx context.Context, path string, v any) error {
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return readError(resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// doRequest performs an HTTP request with basic authentication.
+func (c *Client) doRequest(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	pathURL, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	u := c.baseURL.ResolveReference(pathURL).String()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	c.logger.Debug("Redfish API request", "method", method, "url", u)
+
+	return c.client.Do(req)
+}
+
+// readError builds an error from a non-success response, including the Redfish
+// extended error message if one is present.
+func readError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+
+	var redfishErr errorResponse
+	if err := json.Unmarshal(body, &redfishErr); err == nil && redfishErr.Error.Message != "" {
+		return fmt.Errorf("unexpected status code: %d: %s", resp.StatusCode, redfishErr.Error.Message)
+	}
+	return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+}
@@ -0,0 +1,264 @@
+package redfishclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("valid URL", func(t *testing.T) {
+		client, err := New("https://bmc.example.com", WithCredentials("u", "p"), WithSystemID("1"))
+		require.NoError(t, err)
+		assert.Equal(t, "/redfish/v1/Systems/1", client.systemPath)
+	})
+
+	t.Run("missing scheme", func(t *testing.T) {
+		_, err := New("bmc.example.com")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must include scheme")
+	})
+}
+
+func TestPowerState(t *testing.T) {
+	tests := []struct {
+		name           string
+		systemID       string
+		serverResponse string
+		status         int
+		wantErr        string
+		want           PowerState
+	}{
+		{
+			name:           "on",
+			systemID:       "1",
+			serverResponse: `{"Id": "1", "PowerState": "On"}`,
+			status:         http.StatusOK,
+			want:           PowerStateOn,
+		},
+		{
+			name:           "off with discovered system",
+			serverResponse: `{"Id": "System.Embedded.1", "PowerState": "Off"}`,
+			status:         http.StatusOK,
+			want:           PowerStateOff,
+		},
+		{
+			name:           "http error",
+			systemID:       "1",
+			serverResponse: `{"error": {"code": "Base.1.0.GeneralError", "message": "insufficient privilege"}}`,
+			status:         http.StatusUnauthorized,
+			wantErr:        "unexpected status code: 401: insufficient privilege",
+		},
+		{
+			name:           "invalid json",
+			systemID:       "1",
+			serverResponse: `invalid`,
+			status:         http.StatusOK,
+			wantErr:        "failed to unmarshal response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				user, pass, ok := r.BasicAuth()
+				assert.True(t, ok)
+				assert.Equal(t, "admin", user)
+				assert.Equal(t, "secret", pass)
+
+				switch r.URL.Path {
+				case "/redfish/v1/Systems":
+					w.Write([]byte(`{"Members": [{"@odata.id": "/redfish/v1/Systems/System.Embedded.1"}]}`))
+				case "/redfish/v1/Systems/1", "/redfish/v1/Systems/System.Embedded.1":
+					w.WriteHeader(tt.status)
+					w.Write([]byte(tt.serverResponse))
+				default:
+					t.Errorf("unexpected path %s", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer ts.Close()
+
+			client, err := New(ts.URL, WithCredentials("admin", "secret"), WithSystemID(tt.systemID))
+			require.NoError(t, err)
+
+			state, err := client.PowerState(context.Background())
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, state)
+			}
+		})
+	}
+}
+
+func TestReset(t *testing.T) {
+	tests := []struct {
+		name      string
+		resetType ResetType
+		status    int
+		wantErr   string
+	}{
+		{
+			name:      "power on",
+			resetType: ResetOn,
+			status:    http.StatusNoContent,
+		},
+		{
+			name:      "graceful shutdown accepted",
+			resetType: ResetGracefulShutdown,
+			status:    http.StatusAccepted,
+		},
+		{
+			name:      "force off",
+			resetType: ResetForceOff,
+			status:    http.StatusOK,
+		},
+		{
+			name:      "rejected",
+			resetType: ResetOn,
+			status:    http.StatusBadRequest,
+			wantErr:   "reset On failed: unexpected status code: 400",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/redfish/v1/Systems/1/Actions/ComputerSystem.Reset", r.URL.Path)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+				var req resetRequest
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.Equal(t, tt.resetType, req.ResetType)
+
+				w.WriteHeader(tt.status)
+			}))
+			defer ts.Close()
+
+			client, err := New(ts.URL, WithSystemID("1"))
+			require.NoError(t, err)
+
+			err = client.Reset(context.Background(), tt.resetType)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHealth(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/redfish/v1/Systems/1", r.URL.Path)
+		w.Write([]byte(`{"Id": "1", "PowerState": "On", "Status": {"State": "Enabled", "Health": "OK", "HealthRollup": "Warning"}}`))
+	}))
+	defer ts.Close()
+
+	client, err := New(ts.URL, WithSystemID("1"))
+	require.NoError(t, err)
+
+	status, err := client.Health(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Status{State: "Enabled", Health: HealthOK, HealthRollup: HealthWarning}, status)
+}
+
+func TestEventLog(t *testing.T) {
+	tests := []struct {
+		name         string
+		logServiceID string
+		services     string
+		limit        int
+		wantPath     string
+		wantIDs      []string
+		wantErr      string
+	}{
+		{
+			name:     "discovers SEL and follows next link",
+			services: `{"Members": [{"@odata.id": "/redfish/v1/Systems/1/LogServices/Lclog"}, {"@odata.id": "/redfish/v1/Systems/1/LogServices/SEL"}]}`,
+			wantPath: "/redfish/v1/Systems/1/LogServices/SEL",
+			wantIDs:  []string{"1", "2", "3"},
+		},
+		{
+			name:     "limit stops paging",
+			services: `{"Members": [{"@odata.id": "/redfish/v1/Systems/1/LogServices/EventLog"}]}`,
+			limit:    2,
+			wantPath: "/redfish/v1/Systems/1/LogServices/EventLog",
+			wantIDs:  []string{"1", "2"},
+		},
+		{
+			name:     "falls back to first service",
+			services: `{"Members": [{"@odata.id": "/redfish/v1/Systems/1/LogServices/Vendor"}]}`,
+			wantPath: "/redfish/v1/Systems/1/LogServices/Vendor",
+			wantIDs:  []string{"1", "2", "3"},
+		},
+		{
+			name:         "configured service",
+			logServiceID: "Custom",
+			wantPath:     "/redfish/v1/Systems/1/LogServices/Custom",
+			wantIDs:      []string{"1", "2", "3"},
+		},
+		{
+			name:     "no services",
+			services: `{"Members": []}`,
+			wantErr:  "BMC reports no log services",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/redfish/v1/Systems/1/LogServices":
+					w.Write([]byte(tt.services))
+				case r.URL.Path == tt.wantPath+"/Entries" && r.URL.Query().Get("$skip") == "":
+					w.Write([]byte(`{"Members": [
+						{"Id": "1", "Created": "2026-01-02T03:04:05Z", "Severity": "OK", "Message": "System boot"},
+						{"Id": "2", "Created": "2026-01-02T03:05:00Z", "Severity": "Critical", "Message": "Fan failure", "MessageId": "Fan.1"}
+					], "Members@odata.nextLink": "` + tt.wantPath + `/Entries?$skip=2"}`))
+				case r.URL.Path == tt.wantPath+"/Entries":
+					assert.Equal(t, "2", r.URL.Query().Get("$skip"))
+					w.Write([]byte(`{"Members": [{"Id": "3", "Created": "2026-01-02T03:06:00Z", "Severity": "Warning"}]}`))
+				default:
+					t.Errorf("unexpected path %s", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer ts.Close()
+
+			client, err := New(ts.URL, WithSystemID("1"), WithLogServiceID(tt.logServiceID))
+			require.NoError(t, err)
+
+			entries, err := client.EventLog(context.Background(), tt.limit)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var ids []string
+			for _, e := range entries {
+				ids = append(ids, e.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+			assert.Equal(t, HealthCritical, entries[1].Severity)
+			assert.Equal(t, "Fan failure", entries[1].Message)
+			assert.Equal(t, time.Date(2026, 1, 2, 3, 5, 0, 0, time.UTC), entries[1].Created)
+		})
+	}
+}
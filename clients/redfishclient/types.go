@@ -0,0 +1,94 @@
+package redfishclient
+
+import "time"
+
+// PowerState is the power state of a computer system as reported by Redfish.
+type PowerState string
+
+const (
+	PowerStateUnknown     PowerState = ""
+	PowerStateOn          PowerState = "On"
+	PowerStateOff         PowerState = "Off"
+	PowerStatePoweringOn  PowerState = "PoweringOn"
+	PowerStatePoweringOff PowerState = "PoweringOff"
+)
+
+// ResetType is the type of reset requested by a ComputerSystem.Reset action.
+type ResetType string
+
+const (
+	ResetOn               ResetType = "On"
+	ResetGracefulShutdown ResetType = "GracefulShutdown"
+	ResetForceOff         ResetType = "ForceOff"
+	ResetForceRestart     ResetType = "ForceRestart"
+	ResetGracefulRestart  ResetType = "GracefulRestart"
+)
+
+// Health is the health of a Redfish resource. It is also used for log entry severities.
+type Health string
+
+const (
+	HealthOK       Health = "OK"
+	HealthWarning  Health = "Warning"
+	HealthCritical Health = "Critical"
+)
+
+// Status is the Redfish status object describing the state and health of a resource.
+type Status struct {
+	State        string `json:"State"`        // e.g. "Enabled", "StandbyOffline"
+	Health       Health `json:"Health"`       // health of the resource itself
+	HealthRollup Health `json:"HealthRollup"` // health of the resource and its dependents
+}
+
+// ComputerSystem is the subset of the Redfish ComputerSystem resource used by this client.
+type ComputerSystem struct {
+	ID           string     `json:"Id"`
+	Name         string     `json:"Name"`
+	Manufacturer string     `json:"Manufacturer"`
+	Model        string     `json:"Model"`
+	SerialNumber string     `json:"SerialNumber"`
+	PowerState   PowerState `json:"PowerState"`
+	Status       Status     `json:"Status"`
+}
+
+// LogEntry is an entry from a Redfish log service such as the system event log.
+type LogEntry struct {
+	ID        string    `json:"Id"`
+	Name      string    `json:"Name"`
+	Created   time.Time `json:"Created"`
+	Severity  Health    `json:"Severity"`
+	Message   string    `json:"Message"`
+	MessageID string    `json:"MessageId"`
+	EntryType string    `json:"EntryType"` // e.g. "Event", "SEL", "Oem"
+}
+
+// Internal types
+
+// odataLink is a reference to another Redfish resource.
+type odataLink struct {
+	ODataID string `json:"@odata.id"`
+}
+
+// collectionResponse is a Redfish resource collection.
+type collectionResponse struct {
+	Members []odataLink `json:"Members"`
+}
+
+// logEntryCollection is a page of a log service's Entries collection.
+type logEntryCollection struct {
+	Members  []LogEntry `json:"Members"`
+	NextLink string     `json:"Members@odata.nextLink"`
+}
+
+// resetRequest is the body of a ComputerSystem.Reset action.
+type resetRequest struct {
+	ResetType ResetType `json:"ResetType"`
+}
+
+// errorResponse is a Redfish error payload.
+type errorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
@@ -4,22 +4,24 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 
 	"github.com/nomis52/goback/buildinfo"
 	"github.com/nomis52/goback/config"
 	"github.com/nomis52/goback/logging"
 	"github.com/nomis52/goback/metrics"
-	"github.com/nomis52/goback/workflow"
 	"github.com/nomis52/goback/workflows"
 	"github.com/nomis52/goback/workflows/backup"
-	"github.com/nomis52/goback/workflows/poweroff"
+	"github.com/nomis52/goback/workflows/custom"
 )
 
 type Args struct {
 	ConfigPath  string
 	ShowVersion bool
 	Validate    bool
+	Workflow    string
 }
 
 
@@ -50,6 +52,11 @@ func run() error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	// Check declared workflows before running or reporting the config as valid
+	if err := custom.Validate(&cfg, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		return fmt.Errorf("failed to validate workflows: %w", err)
+	}
+
 	// Handle validation-only request
 	if args.Validate {
 		fmt.Printf("Configuration validation successful: %s\n", args.ConfigPath)
@@ -88,20 +95,18 @@ func run() error {
 		Instance: hostname,
 	})
 
-	// Create backup workflow (PowerOnPBS → BackupDirs → BackupVMs)
-	backupWorkflow, err := backup.NewWorkflow(workflows.Params{
-		Config:           &cfg,
-		Logger:           logger,
-		StatusCollection: nil,
-		LoggerFactory:    nil,
-		Registry:         registry,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create backup workflow: %w", err)
+	// Create backup workflow (PowerOnPBS → BackupDirs → BackupVMs → VerifyBackups → PowerOffPBS)
+	// PowerOffPBS is a finalizer, so PBS is powered off even if the backups fail.
+	// A workflow declared in the config can be run instead with --workflow.
+	name := "backup_poweroff"
+	newWorkflow := backup.NewWorkflowWithPowerOff
+	if args.Workflow != "" {
+		name = args.Workflow
+		newWorkflow = custom.NewWorkflow
 	}
 
-	// Create power off workflow (PowerOffPBS)
-	powerOffWorkflow, err := poweroff.NewWorkflow(workflows.Params{
+	wf, err := newWorkflow(workflows.Params{
+		Name:             name,
 		Config:           &cfg,
 		Logger:           logger,
 		StatusCollection: nil,
@@ -109,15 +114,12 @@ func run() error {
 		Registry:         registry,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to create power off workflow: %w", err)
+		return fmt.Errorf("failed to create %s workflow: %w", name, err)
 	}
 
-	// Compose workflows to run backup then power off
-	composedWorkflow := workflow.Compose(backupWorkflow, powerOffWorkflow)
-
-	// Execute composed workflow
+	// Execute workflow
 	ctx := context.Background()
-	if err := composedWorkflow.Execute(ctx); err != nil {
+	if err := wf.Execute(ctx); err != nil {
 		return fmt.Errorf("workflow execution failed: %w", err)
 	}
 
@@ -137,6 +139,7 @@ func parseArgs() Args {
 	showVersion := flag.Bool("version", false, "Show version information")
 	versionShort := flag.Bool("v", false, "Show version information (shorthand)")
 	validate := flag.Bool("validate", false, "Validate configuration and exit")
+	workflowName := flag.String("workflow", "", "Run the named workflow from the config's workflows section instead of the backup")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n", os.Args[0])
@@ -147,6 +150,7 @@ func parseArgs() Args {
 		fmt.Fprintf(os.Stderr, "  %s --config /etc/goback/config.yaml\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --version\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --config config.yaml --validate\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --config config.yaml --workflow vms_only\n", os.Args[0])
 	}
 
 	flag.Parse()
@@ -162,5 +166,6 @@ func parseArgs() Args {
 		ConfigPath:  path,
 		ShowVersion: version,
 		Validate:    *validate,
+		Workflow:    *workflowName,
 	}
 }
@@ -2,8 +2,12 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"path"
 	"reflect"
+	"regexp"
+	"slices"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -15,11 +19,26 @@ const (
 	defaultServiceWaitTime  = 30 * time.Second
 	defaultShutdownTimeout  = 2 * time.Minute
 	defaultBackupJobTimeout = 2 * time.Hour
+	defaultPBSAPITimeout    = 10 * time.Second
+
+	defaultMaintenanceTaskTimeout = 4 * time.Hour // verifying a large datastore is slow
+	defaultRestoreTimeout         = 2 * time.Hour
+	defaultAgentTimeout           = 5 * time.Minute
+
+	// minVMID is the lowest VMID Proxmox allows for guests
+	minVMID = 100
 
 	// Default backup settings
 	defaultMaxAge     = 24 * time.Hour // 24 hours default
 	defaultBackupMode = "snapshot"     // default backup mode
 	defaultCompress   = "1"            // default compression enabled (1 = gzip)
+	defaultMaxPerNode = 1              // Proxmox holds a per-node vzdump lock
+	defaultPriority   = "oldest_first" // back up the stalest resources first
+
+	// Default power settings
+	defaultWOLBroadcast    = "255.255.255.255:9"
+	defaultStatusOnPattern = `(?i)"(ison|power|output)"\s*:\s*(true|"on")` // Shelly and Tasmota
+	defaultShutdownCommand = "shutdown -h now"
 
 	// Default monitoring settings
 	defaultMetricsPrefix = "pbs_automation"
@@ -31,14 +50,46 @@ const (
 	defaultLogOutput = "stdout"
 )
 
+// Power backend types for PowerConfig.Type
+const (
+	PowerTypeIPMI      = "ipmi"
+	PowerTypeRedfish   = "redfish"
+	PowerTypeWOL       = "wol"
+	PowerTypeSmartPlug = "smartplug"
+)
+
+// Notification sink types for NotifySinkConfig.Type
+const (
+	NotifyTypeSMTP    = "smtp"
+	NotifyTypeWebhook = "webhook"
+	NotifyTypeNtfy    = "ntfy"
+	NotifyTypeGotify  = "gotify"
+	NotifyTypeSlack   = "slack"
+	NotifyTypeDiscord = "discord"
+	NotifyTypeMatrix  = "matrix"
+)
+
+// Run outcomes a notification sink can subscribe to with NotifySinkConfig.On
+const (
+	NotifyOnSuccess   = "success"
+	NotifyOnFailure   = "failure"
+	NotifyOnCancelled = "cancelled"
+	NotifyOnDeadline  = "deadline" // a run still going when its deadline and grace period passed
+	NotifyOnRecovery  = "recovery" // a successful run following an unsuccessful one
+)
+
 // Config represents the complete application configuration
 type Config struct {
-	PBS        PBSConfig        `yaml:"pbs"`
-	Proxmox    ProxmoxConfig    `yaml:"proxmox"`
-	Compute    ComputeConfig    `yaml:"compute"`
-	Files      FilesConfig      `yaml:"files"`
-	Monitoring MonitoringConfig `yaml:"monitoring"`
-	Logging    LoggingConfig    `yaml:"logging"`
+	PBS         PBSConfig         `yaml:"pbs"`
+	Proxmox     ProxmoxConfig     `yaml:"proxmox"`
+	Compute     ComputeConfig     `yaml:"compute"`
+	Files       FileJobs          `yaml:"files"`
+	Monitoring  MonitoringConfig  `yaml:"monitoring"`
+	Logging     LoggingConfig     `yaml:"logging"`
+	Notify      NotifyConfig      `yaml:"notify"`
+	Maintenance MaintenanceConfig `yaml:"maintenance"`
+	RestoreTest RestoreTestConfig `yaml:"restore_test"`
+	Workflows   []WorkflowConfig  `yaml:"workflows"`
 }
 
 // IPMIConfig holds IPMI connection settings
@@ -48,14 +99,65 @@ type IPMIConfig struct {
 	Password string `yaml:"password" sensitive:"true"`
 }
 
+// PowerConfig selects and configures the power management backend.
+// The IPMI backend uses the settings in pbs.ipmi.
+type PowerConfig struct {
+	// Type is the backend: "ipmi" (default), "redfish", "wol" or "smartplug"
+	Type      string            `yaml:"type"`
+	Redfish   RedfishConfig     `yaml:"redfish"`
+	WOL       WOLConfig         `yaml:"wol"`
+	SmartPlug SmartPlugConfig   `yaml:"smart_plug"`
+	SSH       SSHShutdownConfig `yaml:"ssh"` // graceful shutdown for the wol and smartplug backends
+}
+
+// RedfishConfig holds Redfish BMC connection settings
+type RedfishConfig struct {
+	Host               string `yaml:"host"` // including scheme, e.g. https://bmc.example.com
+	Username           string `yaml:"username"`
+	Password           string `yaml:"password" sensitive:"true"`
+	SystemID           string `yaml:"system_id"` // optional, defaults to the first system
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// WOLConfig holds Wake-on-LAN settings
+type WOLConfig struct {
+	MAC       string `yaml:"mac"`
+	Broadcast string `yaml:"broadcast"` // host:port the magic packet is sent to
+}
+
+// SmartPlugConfig holds the HTTP endpoints of a smart plug
+type SmartPlugConfig struct {
+	OnURL     string `yaml:"on_url"`
+	OffURL    string `yaml:"off_url"`
+	StatusURL string `yaml:"status_url"`
+	// StatusOnPattern is a regular expression matched against the status response body
+	// to determine whether the plug is on, e.g. '"ison":\s*true'
+	StatusOnPattern string `yaml:"status_on_pattern"`
+}
+
+// SSHShutdownConfig holds the SSH settings used to shut down a host without a BMC
+type SSHShutdownConfig struct {
+	Host           string `yaml:"host"`
+	User           string `yaml:"user"`
+	PrivateKeyPath string `yaml:"private_key_path"`
+	Command        string `yaml:"command"`
+}
+
 // PBSConfig holds Proxmox Backup Server settings
 type PBSConfig struct {
 	// Host is the address of the Proxmox Backup Server
 	Host string `yaml:"host"`
 
+	// Token is the PBS API token ("user@realm!tokenname:secret") used for datastore,
+	// snapshot and task operations. It is not needed to power PBS on and off.
+	Token string `yaml:"token" sensitive:"true"`
+
 	// IPMI holds BMC connection settings for the PBS server
 	IPMI IPMIConfig `yaml:"ipmi"`
 
+	// Power selects the backend used to power the PBS server on and off
+	Power PowerConfig `yaml:"power"`
+
 	// BootTimeout is the maximum time to wait for the PBS server to become available after boot
 	BootTimeout time.Duration `yaml:"boot_timeout"`
 
@@ -64,31 +166,145 @@ type PBSConfig struct {
 
 	// ShutdownTimeout is the maximum time to wait for graceful shutdown
 	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	// Username and Password log in to PBS (e.g. "backup@pbs") as an alternative to Token
+	Username string `yaml:"username"`
+	Password string `yaml:"password" sensitive:"true"`
+
+	// TLS controls how the PBS certificate is verified
+	TLS TLSConfig `yaml:"tls"`
+
+	// Timeout is the timeout for each PBS API request
+	Timeout time.Duration `yaml:"timeout"`
 }
 
 // ProxmoxConfig holds Proxmox API connection settings
 type ProxmoxConfig struct {
 	Host          string        `yaml:"host"`
 	Token         string        `yaml:"token" sensitive:"true"`
+	Username      string        `yaml:"username"` // ticket login as an alternative to token, e.g. "root@pam"
+	Password      string        `yaml:"password" sensitive:"true"`
+	TLS           TLSConfig     `yaml:"tls"`
+	Timeout       time.Duration `yaml:"timeout"` // per API request, no timeout if unset
 	Storage       string        `yaml:"storage"`
 	BackupTimeout time.Duration `yaml:"backup_timeout"`
 }
 
+// TLSConfig controls how the certificate of a Proxmox VE or PBS host is verified.
+// By default the system trust store is used. At most one of CABundle and Fingerprint may be set.
+type TLSConfig struct {
+	// CABundle is a PEM file of CA certificates to trust instead of the system roots,
+	// e.g. a copy of the cluster's /etc/pve/pve-root-ca.pem
+	CABundle string `yaml:"ca_bundle"`
+
+	// Fingerprint pins the host's certificate by its SHA-256 fingerprint, as shown in the
+	// Proxmox web UI ("AB:CD:..."). The certificate chain is not checked when it is set.
+	Fingerprint string `yaml:"fingerprint"`
+}
+
 // ComputeConfig defines backup behavior settings for VMs and LXCs
 type ComputeConfig struct {
 	MaxBackupAge time.Duration `yaml:"max_backup_age"`
 	Mode         string        `yaml:"mode"`     // backup mode: snapshot, suspend, stop
 	Compress     string        `yaml:"compress"` // compression: "0", "1", "gzip", "lzo", "zstd"
+
+	// MaxConcurrent limits the number of backups running at once across the cluster. Zero means unlimited.
+	MaxConcurrent int `yaml:"max_concurrent"`
+
+	// MaxConcurrentPerNode limits the number of backups running at once on each Proxmox node.
+	// Zero selects the default of 1, as Proxmox holds a per-node vzdump lock.
+	MaxConcurrentPerNode int `yaml:"max_concurrent_per_node"`
+
+	// Priority controls the order backups are started: "oldest_first" or "vmid".
+	Priority string `yaml:"priority"`
+
+	// Include limits backups to resources matching at least one selector. Empty means all resources.
+	Include []ResourceSelector `yaml:"include"`
+
+	// Exclude skips resources matching any selector. Exclusions take precedence over Include.
+	Exclude []ResourceSelector `yaml:"exclude"`
+
+	// Overrides adjust backup settings for matching resources. The first matching override applies.
+	Overrides []ComputeOverride `yaml:"overrides"`
+
+	// MinBackupSizeRatio fails post-backup verification when a new backup is smaller than this
+	// fraction of the resource's previous backup. Zero only requires the backup to be non-empty.
+	MinBackupSizeRatio float64 `yaml:"min_backup_size_ratio"`
+}
+
+// ResourceSelector matches VMs and LXCs.
+// A resource matches when it satisfies every criterion that is set; within a
+// criterion, matching any one value is sufficient. An empty selector is invalid.
+type ResourceSelector struct {
+	VMIDs    []int    `yaml:"vmids"`
+	Names    []string `yaml:"names"` // glob patterns, e.g. "scratch-*"
+	Nodes    []string `yaml:"nodes"`
+	Tags     []string `yaml:"tags"`
+	Pools    []string `yaml:"pools"`
+	Template *bool    `yaml:"template"` // true matches only templates, false only non-templates
 }
 
-// FilesConfig defines a single SSH backup job for file-based backups
-type FilesConfig struct {
+// ComputeOverride replaces the compute backup settings for resources matching a selector.
+// Zero-valued settings fall back to the top-level compute settings.
+type ComputeOverride struct {
+	Match        ResourceSelector `yaml:"match"`
+	MaxBackupAge time.Duration    `yaml:"max_backup_age"`
+	Mode         string           `yaml:"mode"`
+	Compress     string           `yaml:"compress"`
+}
+
+// FileJobConfig defines a named SSH backup job for file-based backups.
+// Each job runs proxmox-backup-client on its host with its own target.
+type FileJobConfig struct {
+	// Name identifies the job in logs, status and metrics. Must be unique.
+	Name           string   `yaml:"name"`
 	Host           string   `yaml:"host"`
 	User           string   `yaml:"user"`
 	PrivateKeyPath string   `yaml:"private_key_path"`
 	Token          string   `yaml:"token" sensitive:"true"`
 	Target         string   `yaml:"target"`
+	Namespace      string   `yaml:"namespace"` // optional PBS namespace
+	BackupID       string   `yaml:"backup_id"` // optional, defaults to the host's hostname
 	Sources        []string `yaml:"sources"`
+	Exclude        []string `yaml:"exclude"` // optional exclude patterns
+	// MaxBackupAge is how old the job's last successful backup can be before it is
+	// flagged as overdue. Optional, 0 disables the check.
+	MaxBackupAge time.Duration `yaml:"max_backup_age"`
+	// Interval is how often the job backs up. A run skips the job if PBS already has a
+	// snapshot of it taken less than Interval ago, so jobs can back up less often than
+	// the workflow runs. Optional, 0 backs up on every run. Requires BackupID.
+	Interval time.Duration `yaml:"interval"`
+}
+
+// FileJobs is the list of file backup jobs. It also accepts the single unnamed job
+// mapping used by earlier versions, which becomes a job named after its host.
+type FileJobs []FileJobConfig
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (f *FileJobs) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		var jobs []FileJobConfig
+		if err := value.Decode(&jobs); err != nil {
+			return err
+		}
+		*f = jobs
+		return nil
+	}
+
+	var job FileJobConfig
+	if err := value.Decode(&job); err != nil {
+		return err
+	}
+	if job.Host == "" {
+		// File backups were disabled by leaving the host empty
+		*f = nil
+		return nil
+	}
+	if job.Name == "" {
+		job.Name = job.Host
+	}
+	*f = FileJobs{job}
+	return nil
 }
 
 // MonitoringConfig holds metrics and monitoring settings
@@ -98,6 +314,134 @@ type MonitoringConfig struct {
 	JobName            string `yaml:"jobname"`
 }
 
+// NotifyConfig holds the sinks that are sent a summary when a run finishes
+type NotifyConfig struct {
+	Sinks []NotifySinkConfig `yaml:"sinks"`
+}
+
+// NotifySinkConfig configures a single notification destination.
+// Which fields are used depends on Type.
+type NotifySinkConfig struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"` // smtp, webhook, ntfy, gotify, slack, discord or matrix
+	// On lists the run outcomes to notify on: success, failure, cancelled, deadline and recovery.
+	// If empty, every run is notified.
+	On []string `yaml:"on"`
+	// Template is an optional text/template for the message body
+	Template string `yaml:"template"`
+
+	// URL is the webhook URL, ntfy topic URL, Gotify server or Matrix homeserver
+	URL     string            `yaml:"url" sensitive:"true"` // Slack and Discord URLs embed a secret
+	Token   string            `yaml:"token" sensitive:"true"`
+	Headers map[string]string `yaml:"headers" sensitive:"true"` // extra HTTP headers for webhook sinks
+	// Priority is the ntfy (1-5) or Gotify (0-10) message priority, 0 uses the server default
+	Priority int    `yaml:"priority"`
+	Room     string `yaml:"room"` // Matrix room ID, e.g. !abc123:matrix.org
+
+	SMTP SMTPConfig `yaml:"smtp"`
+}
+
+// SMTPConfig holds the settings for sending email notifications
+type SMTPConfig struct {
+	Host     string   `yaml:"host"` // host:port, e.g. smtp.example.com:587
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password" sensitive:"true"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+}
+
+// MaintenanceConfig configures the PBS maintenance workflow, which runs prune, garbage
+// collection and verification on the listed datastores while PBS is powered on.
+type MaintenanceConfig struct {
+	// Datastores lists the PBS datastores to maintain. Requires pbs.token.
+	Datastores []string `yaml:"datastores"`
+
+	// Prune sets how many snapshots to keep. Pruning is skipped if no keep option is set.
+	Prune PruneConfig `yaml:"prune"`
+
+	// GarbageCollection runs garbage collection after pruning to reclaim space
+	GarbageCollection bool `yaml:"garbage_collection"`
+
+	// Verify configures verification of the datastore's snapshots
+	Verify VerifyConfig `yaml:"verify"`
+
+	// TaskTimeout is the maximum time to wait for each PBS task to finish
+	TaskTimeout time.Duration `yaml:"task_timeout"`
+}
+
+// PruneConfig holds the keep options applied when pruning a datastore
+type PruneConfig struct {
+	KeepLast    int `yaml:"keep_last"`
+	KeepDaily   int `yaml:"keep_daily"`
+	KeepWeekly  int `yaml:"keep_weekly"`
+	KeepMonthly int `yaml:"keep_monthly"`
+	KeepYearly  int `yaml:"keep_yearly"`
+}
+
+// Enabled reports whether any keep option is set.
+func (p PruneConfig) Enabled() bool {
+	return p.KeepLast > 0 || p.KeepDaily > 0 || p.KeepWeekly > 0 || p.KeepMonthly > 0 || p.KeepYearly > 0
+}
+
+// VerifyConfig configures snapshot verification
+type VerifyConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// IgnoreVerified skips snapshots that have already been verified
+	IgnoreVerified bool `yaml:"ignore_verified"`
+
+	// OutdatedAfter re-verifies snapshots whose last verification is older than this.
+	// Only used with IgnoreVerified. PBS works in whole days.
+	OutdatedAfter time.Duration `yaml:"outdated_after"`
+}
+
+// RestoreTestConfig configures the restoretest workflow, which restores a backup to a
+// scratch guest, boots it and then destroys it.
+type RestoreTestConfig struct {
+	// VMIDs lists the guests whose latest backup may be restored; one is picked at random.
+	// If empty, any guest with a backup in proxmox.storage may be picked.
+	VMIDs []int `yaml:"vmids"`
+
+	// Node is the Proxmox node the backup is restored on
+	Node string `yaml:"node"`
+
+	// Storage is where the restored guest's disks are created
+	Storage string `yaml:"storage"`
+
+	// ScratchVMID is the VMID of the restored guest. It must not be used by any other guest;
+	// the restore test refuses to run if it is.
+	ScratchVMID int `yaml:"scratch_vmid"`
+
+	// WaitForAgent waits for the QEMU guest agent to respond after boot. Ignored for containers.
+	WaitForAgent bool `yaml:"wait_for_agent"`
+
+	// AgentTimeout is the maximum time to wait for the guest agent
+	AgentTimeout time.Duration `yaml:"agent_timeout"`
+
+	// RestoreTimeout is the maximum time to wait for the restore to finish
+	RestoreTimeout time.Duration `yaml:"restore_timeout"`
+}
+
+// WorkflowConfig declares a workflow built from registered activity types, such as
+// PowerOnPBS or BackupVMs. Activities run in the order their dependencies require;
+// Ordering adds constraints between activities that don't depend on each other.
+type WorkflowConfig struct {
+	// Name is the workflow name used by cron triggers and the /run endpoint
+	Name string `yaml:"name"`
+
+	// Activities lists the activity types the workflow runs
+	Activities []string `yaml:"activities"`
+
+	// Ordering lists extra ordering constraints between the activities
+	Ordering []OrderingConfig `yaml:"ordering"`
+}
+
+// OrderingConfig requires activity Before to finish before activity After starts.
+type OrderingConfig struct {
+	Before string `yaml:"before"`
+	After  string `yaml:"after"`
+}
+
 // LoggingConfig defines logging behavior settings
 type LoggingConfig struct {
 	Level     string `yaml:"level"`
@@ -109,14 +453,8 @@ type LoggingConfig struct {
 // Validate performs basic validation on the configuration
 func (c *Config) Validate() error {
 	// PBS validation
-	if c.PBS.IPMI.Host == "" {
-		return fmt.Errorf("PBS IPMI host is required")
-	}
-	if c.PBS.IPMI.Username == "" {
-		return fmt.Errorf("PBS IPMI username is required")
-	}
-	if c.PBS.IPMI.Password == "" {
-		return fmt.Errorf("PBS IPMI password is required")
+	if err := c.PBS.validatePower(); err != nil {
+		return err
 	}
 	if c.PBS.Host == "" {
 		return fmt.Errorf("PBS host is required")
@@ -127,6 +465,9 @@ func (c *Config) Validate() error {
 	if c.PBS.ShutdownTimeout <= 0 {
 		return fmt.Errorf("PBS shutdown timeout must be positive")
 	}
+	if err := validateAPIAccess(c.PBS.Token, c.PBS.Username, c.PBS.Password, c.PBS.TLS, c.PBS.Timeout); err != nil {
+		return fmt.Errorf("PBS %w", err)
+	}
 
 	// Proxmox validation
 	if c.Proxmox.Host == "" {
@@ -138,37 +479,62 @@ func (c *Config) Validate() error {
 	if c.Proxmox.BackupTimeout <= 0 {
 		return fmt.Errorf("proxmox backup timeout must be positive")
 	}
+	if err := validateAPIAccess(c.Proxmox.Token, c.Proxmox.Username, c.Proxmox.Password, c.Proxmox.TLS, c.Proxmox.Timeout); err != nil {
+		return fmt.Errorf("proxmox %w", err)
+	}
 
 	// Monitoring validation
 	if c.Monitoring.VictoriaMetricsURL == "" {
 		return fmt.Errorf("VictoriaMetrics URL is required")
 	}
 
-	// Files validation (if files backup is configured)
-	if c.Files.Host != "" {
-		if c.Files.User == "" {
-			return fmt.Errorf("files user is required when host is set")
+	// Files validation
+	jobNames := make(map[string]bool, len(c.Files))
+	for i, job := range c.Files {
+		if err := job.Validate(); err != nil {
+			return fmt.Errorf("files[%d]: %w", i, err)
 		}
-		if c.Files.PrivateKeyPath == "" {
-			return fmt.Errorf("files private_key_path is required when host is set")
+		if jobNames[job.Name] {
+			return fmt.Errorf("files[%d]: duplicate job name %q", i, job.Name)
 		}
-		if c.Files.Token == "" {
-			return fmt.Errorf("files token is required when host is set")
+		if job.Interval > 0 && c.PBS.Token == "" && c.PBS.Username == "" {
+			return fmt.Errorf("files[%d]: job %q: interval requires pbs token or username", i, job.Name)
 		}
-		if c.Files.Target == "" {
-			return fmt.Errorf("files target is required when host is set")
+		jobNames[job.Name] = true
+	}
+
+	// Notify validation
+	sinkNames := make(map[string]bool, len(c.Notify.Sinks))
+	for i, sink := range c.Notify.Sinks {
+		if err := sink.Validate(); err != nil {
+			return fmt.Errorf("notify sinks[%d]: %w", i, err)
 		}
-		if len(c.Files.Sources) == 0 {
-			return fmt.Errorf("files sources cannot be empty when host is set")
+		if sinkNames[sink.Name] {
+			return fmt.Errorf("notify sinks[%d]: duplicate sink name %q", i, sink.Name)
 		}
+		sinkNames[sink.Name] = true
+	}
 
-		// Validate SSH private key file exists and is readable
-		if _, err := os.Stat(c.Files.PrivateKeyPath); err != nil {
-			if os.IsNotExist(err) {
-				return fmt.Errorf("files private key file not found: %s", c.Files.PrivateKeyPath)
-			}
-			return fmt.Errorf("files private key file not accessible: %s (%w)", c.Files.PrivateKeyPath, err)
+	// Maintenance validation
+	if err := c.Maintenance.validate(c.PBS.Token != "" || c.PBS.Username != ""); err != nil {
+		return fmt.Errorf("maintenance %w", err)
+	}
+
+	// Restore test validation
+	if err := c.RestoreTest.validate(); err != nil {
+		return fmt.Errorf("restore_test %w", err)
+	}
+
+	// Workflows validation
+	workflowNames := make(map[string]bool, len(c.Workflows))
+	for i, w := range c.Workflows {
+		if err := w.Validate(); err != nil {
+			return fmt.Errorf("workflows[%d]: %w", i, err)
+		}
+		if workflowNames[w.Name] {
+			return fmt.Errorf("workflows[%d]: duplicate workflow name %q", i, w.Name)
 		}
+		workflowNames[w.Name] = true
 	}
 
 	// Compute validation
@@ -177,35 +543,318 @@ func (c *Config) Validate() error {
 	}
 
 	// Validate compute mode if specified
-	if c.Compute.Mode != "" {
-		validModes := []string{"snapshot", "suspend", "stop"}
-		found := false
-		for _, mode := range validModes {
-			if c.Compute.Mode == mode {
-				found = true
-				break
-			}
+	if err := validateMode(c.Compute.Mode); err != nil {
+		return fmt.Errorf("compute %w", err)
+	}
+
+	// Validate compute compression if specified
+	if err := validateCompress(c.Compute.Compress); err != nil {
+		return fmt.Errorf("compute %w", err)
+	}
+
+	if c.Compute.MaxConcurrent < 0 {
+		return fmt.Errorf("compute max_concurrent cannot be negative")
+	}
+	if c.Compute.MaxConcurrentPerNode < 0 {
+		return fmt.Errorf("compute max_concurrent_per_node cannot be negative")
+	}
+	if c.Compute.MinBackupSizeRatio < 0 || c.Compute.MinBackupSizeRatio > 1 {
+		return fmt.Errorf("compute min_backup_size_ratio must be between 0 and 1")
+	}
+	validPriorities := []string{"oldest_first", "vmid"}
+	if c.Compute.Priority != "" && !slices.Contains(validPriorities, c.Compute.Priority) {
+		return fmt.Errorf("compute priority must be one of: %v", validPriorities)
+	}
+
+	// Validate resource selection
+	for i, sel := range c.Compute.Include {
+		if err := sel.Validate(); err != nil {
+			return fmt.Errorf("compute include[%d]: %w", i, err)
+		}
+	}
+	for i, sel := range c.Compute.Exclude {
+		if err := sel.Validate(); err != nil {
+			return fmt.Errorf("compute exclude[%d]: %w", i, err)
+		}
+	}
+	for i, override := range c.Compute.Overrides {
+		if err := override.Match.Validate(); err != nil {
+			return fmt.Errorf("compute overrides[%d] match: %w", i, err)
 		}
-		if !found {
-			return fmt.Errorf("compute mode must be one of: %v", validModes)
+		if override.MaxBackupAge < 0 {
+			return fmt.Errorf("compute overrides[%d] max_backup_age cannot be negative", i)
+		}
+		if err := validateMode(override.Mode); err != nil {
+			return fmt.Errorf("compute overrides[%d] %w", i, err)
+		}
+		if err := validateCompress(override.Compress); err != nil {
+			return fmt.Errorf("compute overrides[%d] %w", i, err)
 		}
 	}
 
-	// Validate compute compression if specified
-	if c.Compute.Compress != "" {
-		validCompress := []string{"0", "1", "gzip", "lzo", "zstd"}
-		found := false
-		for _, comp := range validCompress {
-			if c.Compute.Compress == comp {
-				found = true
-				break
-			}
+	return nil
+}
+
+// validate checks the maintenance settings. pbsAuth reports whether PBS API credentials are configured.
+func (m *MaintenanceConfig) validate(pbsAuth bool) error {
+	for _, keep := range []int{m.Prune.KeepLast, m.Prune.KeepDaily, m.Prune.KeepWeekly, m.Prune.KeepMonthly, m.Prune.KeepYearly} {
+		if keep < 0 {
+			return fmt.Errorf("prune keep options cannot be negative")
+		}
+	}
+	if m.Verify.OutdatedAfter < 0 {
+		return fmt.Errorf("verify outdated_after cannot be negative")
+	}
+	if m.TaskTimeout < 0 {
+		return fmt.Errorf("task_timeout cannot be negative")
+	}
+	for i, store := range m.Datastores {
+		if store == "" {
+			return fmt.Errorf("datastores[%d] cannot be empty", i)
+		}
+	}
+	if len(m.Datastores) > 0 && !pbsAuth {
+		return fmt.Errorf("datastores require pbs token or username")
+	}
+	return nil
+}
+
+// fingerprintPattern matches a SHA-256 certificate fingerprint, with or without colons
+var fingerprintPattern = regexp.MustCompile(`^([0-9a-fA-F]{2}:){31}[0-9a-fA-F]{2}$|^[0-9a-fA-F]{64}$`)
+
+// validateAPIAccess checks the authentication and TLS settings of a Proxmox VE or PBS API client.
+func validateAPIAccess(token, username, password string, tls TLSConfig, timeout time.Duration) error {
+	if token != "" && username != "" {
+		return fmt.Errorf("token and username cannot both be set")
+	}
+	if username != "" && password == "" {
+		return fmt.Errorf("password is required with username")
+	}
+	if tls.CABundle != "" && tls.Fingerprint != "" {
+		return fmt.Errorf("tls ca_bundle and fingerprint cannot both be set")
+	}
+	if tls.Fingerprint != "" && !fingerprintPattern.MatchString(tls.Fingerprint) {
+		return fmt.Errorf("tls fingerprint must be a SHA-256 fingerprint, e.g. AB:CD:...:EF")
+	}
+	if timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	return nil
+}
+
+// validate checks the restore test settings. An empty configuration is valid; the
+// restoretest workflow reports that it is not configured when run.
+func (r *RestoreTestConfig) validate() error {
+	if r.Node == "" && r.Storage == "" && r.ScratchVMID == 0 {
+		return nil
+	}
+	if r.Node == "" || r.Storage == "" {
+		return fmt.Errorf("node and storage are required")
+	}
+	if r.ScratchVMID < minVMID {
+		return fmt.Errorf("scratch_vmid must be at least %d", minVMID)
+	}
+	if slices.Contains(r.VMIDs, r.ScratchVMID) {
+		return fmt.Errorf("scratch_vmid %d cannot also be listed in vmids", r.ScratchVMID)
+	}
+	if r.AgentTimeout < 0 || r.RestoreTimeout < 0 {
+		return fmt.Errorf("timeouts cannot be negative")
+	}
+	return nil
+}
+
+// validatePower checks the settings required by the selected power backend.
+func (p *PBSConfig) validatePower() error {
+	switch p.Power.Type {
+	case "", PowerTypeIPMI:
+		if p.IPMI.Host == "" {
+			return fmt.Errorf("PBS IPMI host is required")
+		}
+		if p.IPMI.Username == "" {
+			return fmt.Errorf("PBS IPMI username is required")
+		}
+		if p.IPMI.Password == "" {
+			return fmt.Errorf("PBS IPMI password is required")
+		}
+	case PowerTypeRedfish:
+		if p.Power.Redfish.Host == "" {
+			return fmt.Errorf("PBS power redfish host is required")
+		}
+	case PowerTypeWOL:
+		if _, err := net.ParseMAC(p.Power.WOL.MAC); err != nil {
+			return fmt.Errorf("PBS power wol mac is invalid: %w", err)
+		}
+		if p.Power.SSH.Host == "" {
+			return fmt.Errorf("PBS power ssh host is required for wol")
+		}
+	case PowerTypeSmartPlug:
+		if p.Power.SmartPlug.OnURL == "" || p.Power.SmartPlug.OffURL == "" || p.Power.SmartPlug.StatusURL == "" {
+			return fmt.Errorf("PBS power smart_plug on_url, off_url and status_url are required")
+		}
+		if _, err := regexp.Compile(p.Power.SmartPlug.StatusOnPattern); err != nil {
+			return fmt.Errorf("PBS power smart_plug status_on_pattern is invalid: %w", err)
+		}
+		if p.Power.SSH.Host == "" {
+			return fmt.Errorf("PBS power ssh host is required for smartplug")
+		}
+	default:
+		return fmt.Errorf("PBS power type must be one of: %v", []string{PowerTypeIPMI, PowerTypeRedfish, PowerTypeWOL, PowerTypeSmartPlug})
+	}
+
+	if p.Power.SSH.Host != "" {
+		if p.Power.SSH.PrivateKeyPath == "" {
+			return fmt.Errorf("PBS power ssh private_key_path is required when host is set")
+		}
+		if _, err := os.Stat(p.Power.SSH.PrivateKeyPath); err != nil {
+			return fmt.Errorf("PBS power ssh private key file not accessible: %s (%w)", p.Power.SSH.PrivateKeyPath, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the file backup job is complete and its private key is readable.
+func (j *FileJobConfig) Validate() error {
+	if j.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if j.Host == "" {
+		return fmt.Errorf("job %q: host is required", j.Name)
+	}
+	if j.User == "" {
+		return fmt.Errorf("job %q: user is required", j.Name)
+	}
+	if j.PrivateKeyPath == "" {
+		return fmt.Errorf("job %q: private_key_path is required", j.Name)
+	}
+	if j.Token == "" {
+		return fmt.Errorf("job %q: token is required", j.Name)
+	}
+	if j.Target == "" {
+		return fmt.Errorf("job %q: target is required", j.Name)
+	}
+	if len(j.Sources) == 0 {
+		return fmt.Errorf("job %q: sources cannot be empty", j.Name)
+	}
+	if j.MaxBackupAge < 0 {
+		return fmt.Errorf("job %q: max_backup_age cannot be negative", j.Name)
+	}
+	if j.Interval < 0 {
+		return fmt.Errorf("job %q: interval cannot be negative", j.Name)
+	}
+	if j.Interval > 0 && j.BackupID == "" {
+		return fmt.Errorf("job %q: backup_id is required with interval", j.Name)
+	}
+
+	// Validate SSH private key file exists and is readable
+	if _, err := os.Stat(j.PrivateKeyPath); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("job %q: private key file not found: %s", j.Name, j.PrivateKeyPath)
+		}
+		return fmt.Errorf("job %q: private key file not accessible: %s (%w)", j.Name, j.PrivateKeyPath, err)
+	}
+	return nil
+}
+
+// Validate checks that the workflow lists its activities once each and that its ordering
+// constraints refer to listed activities. Whether the activity types exist and their
+// dependencies are met is checked when the workflow is built.
+func (w *WorkflowConfig) Validate() error {
+	if w.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(w.Activities) == 0 {
+		return fmt.Errorf("workflow %q: activities cannot be empty", w.Name)
+	}
+	for i, a := range w.Activities {
+		if slices.Contains(w.Activities[:i], a) {
+			return fmt.Errorf("workflow %q: duplicate activity %q", w.Name, a)
+		}
+	}
+	for i, o := range w.Ordering {
+		if !slices.Contains(w.Activities, o.Before) {
+			return fmt.Errorf("workflow %q: ordering[%d] before %q is not listed in activities", w.Name, i, o.Before)
+		}
+		if !slices.Contains(w.Activities, o.After) {
+			return fmt.Errorf("workflow %q: ordering[%d] after %q is not listed in activities", w.Name, i, o.After)
+		}
+		if o.Before == o.After {
+			return fmt.Errorf("workflow %q: ordering[%d] activity %q cannot run before itself", w.Name, i, o.Before)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the notification sink has the settings its type requires.
+func (n *NotifySinkConfig) Validate() error {
+	if n.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	validOn := []string{NotifyOnSuccess, NotifyOnFailure, NotifyOnCancelled, NotifyOnDeadline, NotifyOnRecovery}
+	for _, on := range n.On {
+		if !slices.Contains(validOn, on) {
+			return fmt.Errorf("sink %q: on must be one of: %v", n.Name, validOn)
+		}
+	}
+
+	switch n.Type {
+	case NotifyTypeSMTP:
+		if n.SMTP.Host == "" || n.SMTP.From == "" || len(n.SMTP.To) == 0 {
+			return fmt.Errorf("sink %q: smtp host, from and to are required", n.Name)
+		}
+		if _, _, err := net.SplitHostPort(n.SMTP.Host); err != nil {
+			return fmt.Errorf("sink %q: smtp host must be host:port: %w", n.Name, err)
+		}
+	case NotifyTypeWebhook, NotifyTypeNtfy, NotifyTypeSlack, NotifyTypeDiscord:
+		if n.URL == "" {
+			return fmt.Errorf("sink %q: url is required", n.Name)
+		}
+	case NotifyTypeGotify:
+		if n.URL == "" || n.Token == "" {
+			return fmt.Errorf("sink %q: url and token are required", n.Name)
+		}
+	case NotifyTypeMatrix:
+		if n.URL == "" || n.Token == "" || n.Room == "" {
+			return fmt.Errorf("sink %q: url, token and room are required", n.Name)
 		}
-		if !found {
-			return fmt.Errorf("compute compress must be one of: %v", validCompress)
+	default:
+		return fmt.Errorf("sink %q: type must be one of: %v", n.Name, []string{
+			NotifyTypeSMTP, NotifyTypeWebhook, NotifyTypeNtfy, NotifyTypeGotify,
+			NotifyTypeSlack, NotifyTypeDiscord, NotifyTypeMatrix,
+		})
+	}
+	return nil
+}
+
+// Validate checks that the selector has at least one criterion and that name globs are well formed.
+func (s *ResourceSelector) Validate() error {
+	if len(s.VMIDs) == 0 && len(s.Names) == 0 && len(s.Nodes) == 0 &&
+		len(s.Tags) == 0 && len(s.Pools) == 0 && s.Template == nil {
+		return fmt.Errorf("selector must set at least one of vmids, names, nodes, tags, pools or template")
+	}
+	for _, pattern := range s.Names {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid name pattern %q: %w", pattern, err)
 		}
 	}
+	return nil
+}
 
+// validateMode checks that a backup mode, if specified, is supported.
+func validateMode(mode string) error {
+	validModes := []string{"snapshot", "suspend", "stop"}
+	if mode != "" && !slices.Contains(validModes, mode) {
+		return fmt.Errorf("mode must be one of: %v", validModes)
+	}
+	return nil
+}
+
+// validateCompress checks that a compression setting, if specified, is supported.
+func validateCompress(compress string) error {
+	validCompress := []string{"0", "1", "gzip", "lzo", "zstd"}
+	if compress != "" && !slices.Contains(validCompress, compress) {
+		return fmt.Errorf("compress must be one of: %v", validCompress)
+	}
 	return nil
 }
 
@@ -220,9 +869,21 @@ func (c *Config) SetDefaults() {
 	if c.PBS.ShutdownTimeout == 0 {
 		c.PBS.ShutdownTimeout = defaultShutdownTimeout
 	}
+	if c.PBS.Timeout == 0 {
+		c.PBS.Timeout = defaultPBSAPITimeout
+	}
 	if c.Proxmox.BackupTimeout == 0 {
 		c.Proxmox.BackupTimeout = defaultBackupJobTimeout
 	}
+	if c.Maintenance.TaskTimeout == 0 {
+		c.Maintenance.TaskTimeout = defaultMaintenanceTaskTimeout
+	}
+	if c.RestoreTest.AgentTimeout == 0 {
+		c.RestoreTest.AgentTimeout = defaultAgentTimeout
+	}
+	if c.RestoreTest.RestoreTimeout == 0 {
+		c.RestoreTest.RestoreTimeout = defaultRestoreTimeout
+	}
 	if c.Monitoring.MetricsPrefix == "" {
 		c.Monitoring.MetricsPrefix = defaultMetricsPrefix
 	}
@@ -238,6 +899,12 @@ func (c *Config) SetDefaults() {
 	if c.Compute.Compress == "" {
 		c.Compute.Compress = defaultCompress
 	}
+	if c.Compute.MaxConcurrentPerNode == 0 {
+		c.Compute.MaxConcurrentPerNode = defaultMaxPerNode
+	}
+	if c.Compute.Priority == "" {
+		c.Compute.Priority = defaultPriority
+	}
 	// Set logging defaults
 	if c.Logging.Level == "" {
 		c.Logging.Level = defaultLogLevel
@@ -249,9 +916,28 @@ func (c *Config) SetDefaults() {
 		c.Logging.Output = defaultLogOutput
 	}
 
-	// Set files defaults (if files backup is configured but user not specified)
-	if c.Files.Host != "" && c.Files.User == "" {
-		c.Files.User = "root" // Default SSH user
+	// Set power defaults
+	if c.PBS.Power.Type == "" {
+		c.PBS.Power.Type = PowerTypeIPMI
+	}
+	if c.PBS.Power.WOL.Broadcast == "" {
+		c.PBS.Power.WOL.Broadcast = defaultWOLBroadcast
+	}
+	if c.PBS.Power.SmartPlug.StatusOnPattern == "" {
+		c.PBS.Power.SmartPlug.StatusOnPattern = defaultStatusOnPattern
+	}
+	if c.PBS.Power.SSH.Host != "" && c.PBS.Power.SSH.User == "" {
+		c.PBS.Power.SSH.User = "root" // Default SSH user
+	}
+	if c.PBS.Power.SSH.Command == "" {
+		c.PBS.Power.SSH.Command = defaultShutdownCommand
+	}
+
+	// Set files defaults (if user not specified)
+	for i := range c.Files {
+		if c.Files[i].User == "" {
+			c.Files[i].User = "root" // Default SSH user
+		}
 	}
 
 	// Defaults for boolean fields are already false, which is appropriate
@@ -305,10 +991,30 @@ func redactSensitiveFields(v reflect.Value) {
 						field.SetString("***REDACTED***")
 					}
 				}
+				// Replace maps of strings (e.g. headers) with a copy holding redacted values
+				if field.Kind() == reflect.Map && field.Type().Elem().Kind() == reflect.String && !field.IsNil() {
+					copied := reflect.MakeMapWithSize(field.Type(), field.Len())
+					iter := field.MapRange()
+					for iter.Next() {
+						copied.SetMapIndex(iter.Key(), reflect.ValueOf("***REDACTED***").Convert(field.Type().Elem()))
+					}
+					field.Set(copied)
+				}
 			} else {
 				// Recursively process nested structs
 				redactSensitiveFields(field)
 			}
 		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		// Copy the slice so redaction doesn't modify the original's backing array
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(copied, v)
+		v.Set(copied)
+		for i := 0; i < v.Len(); i++ {
+			redactSensitiveFields(v.Index(i))
+		}
 	}
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -102,6 +103,9 @@ func TestConfig_SetDefaults(t *testing.T) {
 	assert.Equal(t, 2*time.Hour, cfg.Proxmox.BackupTimeout, "BackupTimeout default")
 	assert.Equal(t, 2*time.Minute, cfg.PBS.ShutdownTimeout, "ShutdownTimeout default")
 	assert.Equal(t, testMaxBackupAge, cfg.Compute.MaxBackupAge, "MaxBackupAge default")
+	assert.Equal(t, 0, cfg.Compute.MaxConcurrent, "MaxConcurrent default")
+	assert.Equal(t, 1, cfg.Compute.MaxConcurrentPerNode, "MaxConcurrentPerNode default")
+	assert.Equal(t, "oldest_first", cfg.Compute.Priority, "Priority default")
 	assert.Equal(t, "pbs_automation", cfg.Monitoring.MetricsPrefix, "MetricsPrefix default")
 	assert.Equal(t, "goback", cfg.Monitoring.JobName, "JobName default")
 }
@@ -221,14 +225,27 @@ compute:
 monitoring:
   victoriametrics_url: http://vm
 files:
-  host: pve2
-  user: root
-  private_key_path: %s
-  token: mytoken
-  target: backup-client@pbs!token-name@10.6.0.10:tank
-  sources:
-    - home.pxar:/p1/home
-    - root.pxar:/p1/root
+  - name: pve2
+    host: pve2
+    private_key_path: %[1]s
+    token: mytoken
+    target: backup-client@pbs!token-name@10.6.0.10:tank
+    sources:
+      - home.pxar:/p1/home
+      - root.pxar:/p1/root
+  - name: nas
+    host: nas.example.com
+    user: backup
+    private_key_path: %[1]s
+    token: othertoken
+    target: backup-client@pbs!token-name@10.6.0.10:media
+    namespace: hosts/nas
+    backup_id: nas01
+    sources:
+      - data.pxar:/srv/data
+    exclude:
+      - "*.tmp"
+      - /srv/data/cache
 `, tmpKeyFile.Name())
 	_, err = tmpfile.Write([]byte(content))
 	require.NoError(t, err, "failed to write temp config")
@@ -237,7 +254,9 @@ files:
 	cfg, err := LoadConfig(tmpfile.Name())
 	require.NoError(t, err, "LoadConfig should succeed")
 	
-	b := cfg.Files
+	require.Len(t, cfg.Files, 2, "Files jobs length")
+	b := cfg.Files[0]
+	assert.Equal(t, "pve2", b.Name, "Files name")
 	assert.Equal(t, "pve2", b.Host, "Files host")
 	assert.Equal(t, "root", b.User, "Files user")
 	assert.Equal(t, tmpKeyFile.Name(), b.PrivateKeyPath, "Files private key path")
@@ -246,4 +265,853 @@ files:
 	assert.Len(t, b.Sources, 2, "Files sources length")
 	assert.Equal(t, "home.pxar:/p1/home", b.Sources[0], "Files first source")
 	assert.Equal(t, "root.pxar:/p1/root", b.Sources[1], "Files second source")
+
+	n := cfg.Files[1]
+	assert.Equal(t, "nas", n.Name, "Files name")
+	assert.Equal(t, "backup", n.User, "Files user")
+	assert.Equal(t, "hosts/nas", n.Namespace, "Files namespace")
+	assert.Equal(t, "nas01", n.BackupID, "Files backup id")
+	assert.Equal(t, []string{"*.tmp", "/srv/data/cache"}, n.Exclude, "Files exclude")
+}
+
+func TestConfig_ValidateFiles(t *testing.T) {
+	keyFile, err := os.CreateTemp("", "test_key")
+	require.NoError(t, err, "failed to create temp key file")
+	defer os.Remove(keyFile.Name())
+	keyFile.Close()
+
+	job := func(name string) FileJobConfig {
+		return FileJobConfig{
+			Name:           name,
+			Host:           "pve.example.com",
+			User:           "root",
+			PrivateKeyPath: keyFile.Name(),
+			Token:          "t",
+			Target:         "u@pbs!t@pbs.example.com:store",
+			Sources:        []string{"root.pxar:/"},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		files    []FileJobConfig
+		pbsToken string
+		wantErr  string
+	}{
+		{
+			name:  "valid jobs",
+			files: []FileJobConfig{job("a"), job("b")},
+		},
+		{
+			name:    "missing name",
+			files:   []FileJobConfig{job("")},
+			wantErr: "files[0]: name is required",
+		},
+		{
+			name:    "duplicate name",
+			files:   []FileJobConfig{job("a"), job("a")},
+			wantErr: `files[1]: duplicate job name "a"`,
+		},
+		{
+			name: "missing sources",
+			files: func() []FileJobConfig {
+				j := job("a")
+				j.Sources = nil
+				return []FileJobConfig{j}
+			}(),
+			wantErr: `job "a": sources cannot be empty`,
+		},
+		{
+			name: "negative max backup age",
+			files: func() []FileJobConfig {
+				j := job("a")
+				j.MaxBackupAge = -time.Hour
+				return []FileJobConfig{j}
+			}(),
+			wantErr: `job "a": max_backup_age cannot be negative`,
+		},
+		{
+			name: "interval",
+			files: func() []FileJobConfig {
+				j := job("a")
+				j.Interval = test48Hours
+				j.BackupID = "pve"
+				return []FileJobConfig{j}
+			}(),
+			pbsToken: "t",
+		},
+		{
+			name: "interval without backup id",
+			files: func() []FileJobConfig {
+				j := job("a")
+				j.Interval = test48Hours
+				return []FileJobConfig{j}
+			}(),
+			pbsToken: "t",
+			wantErr:  `job "a": backup_id is required with interval`,
+		},
+		{
+			name: "interval without pbs auth",
+			files: func() []FileJobConfig {
+				j := job("a")
+				j.Interval = test48Hours
+				j.BackupID = "pve"
+				return []FileJobConfig{j}
+			}(),
+			wantErr: `files[0]: job "a": interval requires pbs token or username`,
+		},
+		{
+			name: "negative interval",
+			files: func() []FileJobConfig {
+				j := job("a")
+				j.Interval = -time.Hour
+				return []FileJobConfig{j}
+			}(),
+			wantErr: `job "a": interval cannot be negative`,
+		},
+		{
+			name: "missing key file",
+			files: func() []FileJobConfig {
+				j := job("a")
+				j.PrivateKeyPath = "/nonexistent/key"
+				return []FileJobConfig{j}
+			}(),
+			wantErr: "private key file not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				PBS: PBSConfig{
+					Host:            "h",
+					Token:           tt.pbsToken,
+					IPMI:            IPMIConfig{Host: "h", Username: "u", Password: "p"},
+					BootTimeout:     testBootTimeout,
+					ShutdownTimeout: testShutdownTimeout,
+				},
+				Proxmox:    ProxmoxConfig{Host: "h", Token: "t", Storage: "s", BackupTimeout: testBackupTimeout},
+				Files:      tt.files,
+				Monitoring: MonitoringConfig{VictoriaMetricsURL: "u"},
+			}
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_LegacyFiles(t *testing.T) {
+	keyFile, err := os.CreateTemp("", "test_key")
+	require.NoError(t, err, "failed to create temp key file")
+	defer os.Remove(keyFile.Name())
+	keyFile.Close()
+
+	tests := []struct {
+		name     string
+		files    string
+		wantJobs []string
+	}{
+		{
+			name: "single job mapping",
+			files: fmt.Sprintf(`files:
+  host: pve2
+  private_key_path: %s
+  token: mytoken
+  target: backup-client@pbs!token-name@10.6.0.10:tank
+  sources:
+    - root.pxar:/
+`, keyFile.Name()),
+			wantJobs: []string{"pve2"},
+		},
+		{
+			name: "disabled mapping",
+			files: `files:
+  host: ""
+`,
+		},
+		{
+			name: "no files",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := t.TempDir() + "/config.yaml"
+			content := `pbs:
+  host: localhost
+  ipmi:
+    host: localhost
+    username: user
+    password: pass
+proxmox:
+  host: localhost
+  token: token123
+  storage: storage1
+monitoring:
+  victoriametrics_url: http://vm
+` + tt.files
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+			cfg, err := LoadConfig(path)
+			require.NoError(t, err)
+
+			var names []string
+			for _, job := range cfg.Files {
+				names = append(names, job.Name)
+			}
+			assert.Equal(t, tt.wantJobs, names)
+		})
+	}
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := Config{
+		PBS:     PBSConfig{IPMI: IPMIConfig{Password: "secret"}},
+		Proxmox: ProxmoxConfig{Token: "secret"},
+		Files:   []FileJobConfig{{Name: "a", Token: "secret"}},
+		Notify: NotifyConfig{Sinks: []NotifySinkConfig{{
+			Name:    "hook",
+			URL:     "https://hooks.slack.com/services/secret",
+			Headers: map[string]string{"Authorization": "Bearer secret"},
+			SMTP:    SMTPConfig{Password: "secret"},
+		}}},
+	}
+
+	redacted := cfg.Redacted()
+
+	assert.Equal(t, "***REDACTED***", redacted.PBS.IPMI.Password)
+	assert.Equal(t, "***REDACTED***", redacted.Proxmox.Token)
+	assert.Equal(t, "***REDACTED***", redacted.Files[0].Token)
+	assert.Equal(t, "a", redacted.Files[0].Name)
+	assert.Equal(t, "***REDACTED***", redacted.Notify.Sinks[0].URL)
+	assert.Equal(t, "***REDACTED***", redacted.Notify.Sinks[0].Headers["Authorization"])
+	assert.Equal(t, "***REDACTED***", redacted.Notify.Sinks[0].SMTP.Password)
+	// The original must not be modified
+	assert.Equal(t, "secret", cfg.Files[0].Token)
+	assert.Equal(t, "Bearer secret", cfg.Notify.Sinks[0].Headers["Authorization"])
+}
+
+func TestLoadConfig_ComputeSelection(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "goback_config_test.yaml")
+	require.NoError(t, err, "failed to create temp file")
+	defer os.Remove(tmpfile.Name())
+
+	content := `pbs:
+  host: localhost
+  ipmi:
+    host: localhost
+    username: user
+    password: pass
+proxmox:
+  host: localhost
+  token: token123
+  storage: storage1
+compute:
+  max_backup_age: 24h
+  include:
+    - nodes: [pve1, pve2]
+  exclude:
+    - template: true
+    - names: ["scratch-*"]
+  overrides:
+    - match:
+        tags: [media]
+        pools: [bulk]
+      max_backup_age: 168h
+      mode: stop
+      compress: zstd
+monitoring:
+  victoriametrics_url: http://vm
+`
+	_, err = tmpfile.Write([]byte(content))
+	require.NoError(t, err, "failed to write temp config")
+	tmpfile.Close()
+
+	cfg, err := LoadConfig(tmpfile.Name())
+	require.NoError(t, err, "LoadConfig should succeed")
+
+	c := cfg.Compute
+	require.Len(t, c.Include, 1)
+	assert.Equal(t, []string{"pve1", "pve2"}, c.Include[0].Nodes)
+	require.Len(t, c.Exclude, 2)
+	require.NotNil(t, c.Exclude[0].Template)
+	assert.True(t, *c.Exclude[0].Template)
+	assert.Equal(t, []string{"scratch-*"}, c.Exclude[1].Names)
+	require.Len(t, c.Overrides, 1)
+	assert.Equal(t, []string{"media"}, c.Overrides[0].Match.Tags)
+	assert.Equal(t, []string{"bulk"}, c.Overrides[0].Match.Pools)
+	assert.Equal(t, 7*test24Hours, c.Overrides[0].MaxBackupAge)
+	assert.Equal(t, "stop", c.Overrides[0].Mode)
+	assert.Equal(t, "zstd", c.Overrides[0].Compress)
+}
+
+func TestConfig_ValidateCompute(t *testing.T) {
+	tests := []struct {
+		name    string
+		compute ComputeConfig
+		wantErr string
+	}{
+		{
+			name: "valid selection",
+			compute: ComputeConfig{
+				Include:   []ResourceSelector{{VMIDs: []int{100}}},
+				Exclude:   []ResourceSelector{{Names: []string{"tmp-*"}}},
+				Overrides: []ComputeOverride{{Match: ResourceSelector{Tags: []string{"media"}}, Mode: "stop"}},
+			},
+		},
+		{
+			name:    "empty include selector",
+			compute: ComputeConfig{Include: []ResourceSelector{{}}},
+			wantErr: "compute include[0]: selector must set at least one",
+		},
+		{
+			name:    "bad name glob",
+			compute: ComputeConfig{Exclude: []ResourceSelector{{Names: []string{"web-["}}}},
+			wantErr: "invalid name pattern",
+		},
+		{
+			name:    "override without match",
+			compute: ComputeConfig{Overrides: []ComputeOverride{{Mode: "stop"}}},
+			wantErr: "compute overrides[0] match",
+		},
+		{
+			name:    "override bad mode",
+			compute: ComputeConfig{Overrides: []ComputeOverride{{Match: ResourceSelector{VMIDs: []int{1}}, Mode: "pause"}}},
+			wantErr: "compute overrides[0] mode must be one of",
+		},
+		{
+			name:    "override bad compress",
+			compute: ComputeConfig{Overrides: []ComputeOverride{{Match: ResourceSelector{VMIDs: []int{1}}, Compress: "xz"}}},
+			wantErr: "compute overrides[0] compress must be one of",
+		},
+		{
+			name:    "negative max_concurrent",
+			compute: ComputeConfig{MaxConcurrent: -1},
+			wantErr: "compute max_concurrent cannot be negative",
+		},
+		{
+			name:    "unknown priority",
+			compute: ComputeConfig{Priority: "largest_first"},
+			wantErr: "compute priority must be one of",
+		},
+		{
+			name:    "size ratio above one",
+			compute: ComputeConfig{MinBackupSizeRatio: 1.5},
+			wantErr: "compute min_backup_size_ratio must be between 0 and 1",
+		},
+		{
+			name:    "override negative age",
+			compute: ComputeConfig{Overrides: []ComputeOverride{{Match: ResourceSelector{VMIDs: []int{1}}, MaxBackupAge: -time.Hour}}},
+			wantErr: "max_backup_age cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				PBS: PBSConfig{
+					Host:            "h",
+					IPMI:            IPMIConfig{Host: "h", Username: "u", Password: "p"},
+					BootTimeout:     testBootTimeout,
+					ShutdownTimeout: testShutdownTimeout,
+				},
+				Proxmox:    ProxmoxConfig{Host: "h", Token: "t", Storage: "s", BackupTimeout: testBackupTimeout},
+				Compute:    tt.compute,
+				Monitoring: MonitoringConfig{VictoriaMetricsURL: "u"},
+			}
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidatePower(t *testing.T) {
+	keyFile, err := os.CreateTemp(t.TempDir(), "id_ed25519")
+	require.NoError(t, err)
+	keyFile.Close()
+
+	tests := []struct {
+		name    string
+		power   PowerConfig
+		ipmi    IPMIConfig
+		wantErr string
+	}{
+		{
+			name: "ipmi",
+			ipmi: IPMIConfig{Host: "h", Username: "u", Password: "p"},
+		},
+		{
+			name:    "ipmi missing host",
+			power:   PowerConfig{Type: PowerTypeIPMI},
+			wantErr: "PBS IPMI host is required",
+		},
+		{
+			name:  "redfish",
+			power: PowerConfig{Type: PowerTypeRedfish, Redfish: RedfishConfig{Host: "https://bmc"}},
+		},
+		{
+			name:    "redfish missing host",
+			power:   PowerConfig{Type: PowerTypeRedfish},
+			wantErr: "PBS power redfish host is required",
+		},
+		{
+			name: "wol",
+			power: PowerConfig{
+				Type: PowerTypeWOL,
+				WOL:  WOLConfig{MAC: "aa:bb:cc:dd:ee:ff"},
+				SSH:  SSHShutdownConfig{Host: "pbs", PrivateKeyPath: keyFile.Name()},
+			},
+		},
+		{
+			name:    "wol bad mac",
+			power:   PowerConfig{Type: PowerTypeWOL, WOL: WOLConfig{MAC: "zz"}},
+			wantErr: "PBS power wol mac is invalid",
+		},
+		{
+			name:    "wol missing ssh",
+			power:   PowerConfig{Type: PowerTypeWOL, WOL: WOLConfig{MAC: "aa:bb:cc:dd:ee:ff"}},
+			wantErr: "PBS power ssh host is required for wol",
+		},
+		{
+			name: "wol missing key",
+			power: PowerConfig{
+				Type: PowerTypeWOL,
+				WOL:  WOLConfig{MAC: "aa:bb:cc:dd:ee:ff"},
+				SSH:  SSHShutdownConfig{Host: "pbs", PrivateKeyPath: "/nonexistent/key"},
+			},
+			wantErr: "PBS power ssh private key file not accessible",
+		},
+		{
+			name: "smartplug",
+			power: PowerConfig{
+				Type: PowerTypeSmartPlug,
+				SmartPlug: SmartPlugConfig{
+					OnURL: "http://p/on", OffURL: "http://p/off", StatusURL: "http://p/status", StatusOnPattern: "on",
+				},
+				SSH: SSHShutdownConfig{Host: "pbs", PrivateKeyPath: keyFile.Name()},
+			},
+		},
+		{
+			name: "smartplug missing ssh",
+			power: PowerConfig{Type: PowerTypeSmartPlug, SmartPlug: SmartPlugConfig{
+				OnURL: "http://p/on", OffURL: "http://p/off", StatusURL: "http://p/status", StatusOnPattern: "on",
+			}},
+			wantErr: "PBS power ssh host is required for smartplug",
+		},
+		{
+			name:    "smartplug missing urls",
+			power:   PowerConfig{Type: PowerTypeSmartPlug, SmartPlug: SmartPlugConfig{OnURL: "http://p/on"}},
+			wantErr: "on_url, off_url and status_url are required",
+		},
+		{
+			name: "smartplug bad pattern",
+			power: PowerConfig{Type: PowerTypeSmartPlug, SmartPlug: SmartPlugConfig{
+				OnURL: "http://p/on", OffURL: "http://p/off", StatusURL: "http://p/status", StatusOnPattern: "(",
+			}},
+			wantErr: "status_on_pattern is invalid",
+		},
+		{
+			name:    "unknown type",
+			power:   PowerConfig{Type: "x10"},
+			wantErr: "PBS power type must be one of",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pbs := PBSConfig{IPMI: tt.ipmi, Power: tt.power}
+			err := pbs.validatePower()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateNotify(t *testing.T) {
+	tests := []struct {
+		name    string
+		sinks   []NotifySinkConfig
+		wantErr string
+	}{
+		{
+			name: "valid sinks",
+			sinks: []NotifySinkConfig{
+				{Name: "mail", Type: NotifyTypeSMTP, On: []string{"failure", "recovery"}, SMTP: SMTPConfig{Host: "smtp:587", From: "a@b", To: []string{"c@d"}}},
+				{Name: "chat", Type: NotifyTypeDiscord, URL: "https://discord.com/api/webhooks/x"},
+				{Name: "matrix", Type: NotifyTypeMatrix, URL: "https://matrix.org", Token: "t", Room: "!r:matrix.org"},
+			},
+		},
+		{
+			name:    "missing name",
+			sinks:   []NotifySinkConfig{{Type: NotifyTypeWebhook, URL: "http://x"}},
+			wantErr: "notify sinks[0]: name is required",
+		},
+		{
+			name: "duplicate name",
+			sinks: []NotifySinkConfig{
+				{Name: "a", Type: NotifyTypeWebhook, URL: "http://x"},
+				{Name: "a", Type: NotifyTypeNtfy, URL: "http://y"},
+			},
+			wantErr: `notify sinks[1]: duplicate sink name "a"`,
+		},
+		{
+			name:    "unknown outcome",
+			sinks:   []NotifySinkConfig{{Name: "a", Type: NotifyTypeWebhook, URL: "http://x", On: []string{"sometimes"}}},
+			wantErr: "on must be one of",
+		},
+		{
+			name:    "unknown type",
+			sinks:   []NotifySinkConfig{{Name: "a", Type: "pager"}},
+			wantErr: "type must be one of",
+		},
+		{
+			name:    "smtp missing recipients",
+			sinks:   []NotifySinkConfig{{Name: "a", Type: NotifyTypeSMTP, SMTP: SMTPConfig{Host: "smtp:25", From: "a@b"}}},
+			wantErr: "smtp host, from and to are required",
+		},
+		{
+			name:    "smtp host without port",
+			sinks:   []NotifySinkConfig{{Name: "a", Type: NotifyTypeSMTP, SMTP: SMTPConfig{Host: "smtp", From: "a@b", To: []string{"c@d"}}}},
+			wantErr: "smtp host must be host:port",
+		},
+		{
+			name:    "gotify missing token",
+			sinks:   []NotifySinkConfig{{Name: "a", Type: NotifyTypeGotify, URL: "http://x"}},
+			wantErr: "url and token are required",
+		},
+		{
+			name:    "matrix missing room",
+			sinks:   []NotifySinkConfig{{Name: "a", Type: NotifyTypeMatrix, URL: "http://x", Token: "t"}},
+			wantErr: "url, token and room are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				PBS: PBSConfig{
+					Host:            "h",
+					IPMI:            IPMIConfig{Host: "h", Username: "u", Password: "p"},
+					BootTimeout:     testBootTimeout,
+					ShutdownTimeout: testShutdownTimeout,
+				},
+				Proxmox:    ProxmoxConfig{Host: "h", Token: "t", Storage: "s", BackupTimeout: testBackupTimeout},
+				Notify:     NotifyConfig{Sinks: tt.sinks},
+				Monitoring: MonitoringConfig{VictoriaMetricsURL: "u"},
+			}
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateMaintenance(t *testing.T) {
+	tests := []struct {
+		name        string
+		token       string
+		pbsUsername string
+		maintenance MaintenanceConfig
+		wantErr     string
+	}{
+		{
+			name:  "valid",
+			token: "goback@pbs!goback:secret",
+			maintenance: MaintenanceConfig{
+				Datastores:        []string{"backups"},
+				Prune:             PruneConfig{KeepDaily: 7, KeepWeekly: 4},
+				GarbageCollection: true,
+				Verify:            VerifyConfig{Enabled: true, IgnoreVerified: true, OutdatedAfter: 30 * 24 * time.Hour},
+			},
+		},
+		{
+			name: "not configured",
+		},
+		{
+			name:        "datastores with pbs username",
+			pbsUsername: "backup@pbs",
+			maintenance: MaintenanceConfig{Datastores: []string{"backups"}},
+		},
+		{
+			name:        "datastores without token",
+			maintenance: MaintenanceConfig{Datastores: []string{"backups"}},
+			wantErr:     "maintenance datastores require pbs token",
+		},
+		{
+			name:        "empty datastore",
+			token:       "t",
+			maintenance: MaintenanceConfig{Datastores: []string{""}},
+			wantErr:     "maintenance datastores[0] cannot be empty",
+		},
+		{
+			name:        "negative keep",
+			maintenance: MaintenanceConfig{Prune: PruneConfig{KeepMonthly: -1}},
+			wantErr:     "maintenance prune keep options cannot be negative",
+		},
+		{
+			name:        "negative outdated after",
+			maintenance: MaintenanceConfig{Verify: VerifyConfig{OutdatedAfter: -time.Hour}},
+			wantErr:     "maintenance verify outdated_after cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				PBS: PBSConfig{
+					Host:            "h",
+					Token:           tt.token,
+					Username:        tt.pbsUsername,
+					Password:        "p",
+					IPMI:            IPMIConfig{Host: "h", Username: "u", Password: "p"},
+					BootTimeout:     testBootTimeout,
+					ShutdownTimeout: testShutdownTimeout,
+				},
+				Proxmox:     ProxmoxConfig{Host: "h", Token: "t", Storage: "s", BackupTimeout: testBackupTimeout},
+				Monitoring:  MonitoringConfig{VictoriaMetricsURL: "u"},
+				Maintenance: tt.maintenance,
+			}
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateAPIAccess(t *testing.T) {
+	const fingerprint = "AB:CD:EF:01:23:45:67:89:AB:CD:EF:01:23:45:67:89:AB:CD:EF:01:23:45:67:89:AB:CD:EF:01:23:45:67:89"
+
+	tests := []struct {
+		name    string
+		pbs     PBSConfig
+		proxmox ProxmoxConfig
+		wantErr string
+	}{
+		{
+			name:    "tokens",
+			pbs:     PBSConfig{Token: "goback@pbs!goback:secret"},
+			proxmox: ProxmoxConfig{Token: "t"},
+		},
+		{
+			name:    "ticket auth and tls",
+			pbs:     PBSConfig{Username: "backup@pbs", Password: "p", TLS: TLSConfig{Fingerprint: fingerprint}, Timeout: time.Minute},
+			proxmox: ProxmoxConfig{Username: "root@pam", Password: "p", TLS: TLSConfig{CABundle: "/etc/goback/pve-root-ca.pem"}},
+		},
+		{
+			name:    "fingerprint without colons",
+			proxmox: ProxmoxConfig{Token: "t", TLS: TLSConfig{Fingerprint: strings.ReplaceAll(fingerprint, ":", "")}},
+		},
+		{
+			name:    "token and username",
+			proxmox: ProxmoxConfig{Token: "t", Username: "root@pam", Password: "p"},
+			wantErr: "proxmox token and username cannot both be set",
+		},
+		{
+			name:    "username without password",
+			pbs:     PBSConfig{Username: "backup@pbs"},
+			proxmox: ProxmoxConfig{Token: "t"},
+			wantErr: "PBS password is required with username",
+		},
+		{
+			name:    "ca bundle and fingerprint",
+			proxmox: ProxmoxConfig{Token: "t", TLS: TLSConfig{CABundle: "ca.pem", Fingerprint: fingerprint}},
+			wantErr: "proxmox tls ca_bundle and fingerprint cannot both be set",
+		},
+		{
+			name:    "invalid fingerprint",
+			pbs:     PBSConfig{TLS: TLSConfig{Fingerprint: "AB:CD"}},
+			proxmox: ProxmoxConfig{Token: "t"},
+			wantErr: "PBS tls fingerprint must be a SHA-256 fingerprint",
+		},
+		{
+			name:    "negative timeout",
+			proxmox: ProxmoxConfig{Token: "t", Timeout: -time.Second},
+			wantErr: "proxmox timeout cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				PBS:        tt.pbs,
+				Proxmox:    tt.proxmox,
+				Monitoring: MonitoringConfig{VictoriaMetricsURL: "u"},
+			}
+			cfg.PBS.Host = "h"
+			cfg.PBS.IPMI = IPMIConfig{Host: "h", Username: "u", Password: "p"}
+			cfg.PBS.BootTimeout = testBootTimeout
+			cfg.PBS.ShutdownTimeout = testShutdownTimeout
+			cfg.Proxmox.Host = "h"
+			cfg.Proxmox.Storage = "s"
+			cfg.Proxmox.BackupTimeout = testBackupTimeout
+
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateRestoreTest(t *testing.T) {
+	tests := []struct {
+		name        string
+		restoreTest RestoreTestConfig
+		wantErr     string
+	}{
+		{
+			name:        "valid",
+			restoreTest: RestoreTestConfig{VMIDs: []int{100, 101}, Node: "pve1", Storage: "local-lvm", ScratchVMID: 9999, WaitForAgent: true},
+		},
+		{
+			name: "not configured",
+		},
+		{
+			name:        "missing storage",
+			restoreTest: RestoreTestConfig{Node: "pve1", ScratchVMID: 9999},
+			wantErr:     "restore_test node and storage are required",
+		},
+		{
+			name:        "missing scratch vmid",
+			restoreTest: RestoreTestConfig{Node: "pve1", Storage: "local-lvm"},
+			wantErr:     "restore_test scratch_vmid must be at least 100",
+		},
+		{
+			name:        "scratch vmid is a candidate",
+			restoreTest: RestoreTestConfig{VMIDs: []int{100, 9999}, Node: "pve1", Storage: "local-lvm", ScratchVMID: 9999},
+			wantErr:     "restore_test scratch_vmid 9999 cannot also be listed in vmids",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				PBS: PBSConfig{
+					Host:            "h",
+					IPMI:            IPMIConfig{Host: "h", Username: "u", Password: "p"},
+					BootTimeout:     testBootTimeout,
+					ShutdownTimeout: testShutdownTimeout,
+				},
+				Proxmox:     ProxmoxConfig{Host: "h", Token: "t", Storage: "s", BackupTimeout: testBackupTimeout},
+				Monitoring:  MonitoringConfig{VictoriaMetricsURL: "u"},
+				RestoreTest: tt.restoreTest,
+			}
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateWorkflows(t *testing.T) {
+	tests := []struct {
+		name      string
+		workflows []WorkflowConfig
+		wantErr   string
+	}{
+		{
+			name: "valid",
+			workflows: []WorkflowConfig{
+				{Name: "vms_only", Activities: []string{"PowerOnPBS", "BackupVMs", "VerifyBackups"}},
+				{
+					Name:       "backup_then_maintenance",
+					Activities: []string{"PowerOnPBS", "BackupVMs", "PruneDatastores"},
+					Ordering:   []OrderingConfig{{Before: "BackupVMs", After: "PruneDatastores"}},
+				},
+			},
+		},
+		{
+			name:      "missing name",
+			workflows: []WorkflowConfig{{Activities: []string{"PowerOnPBS"}}},
+			wantErr:   "workflows[0]: name is required",
+		},
+		{
+			name: "duplicate name",
+			workflows: []WorkflowConfig{
+				{Name: "on", Activities: []string{"PowerOnPBS"}},
+				{Name: "on", Activities: []string{"PowerOnPBS"}},
+			},
+			wantErr: `workflows[1]: duplicate workflow name "on"`,
+		},
+		{
+			name:      "no activities",
+			workflows: []WorkflowConfig{{Name: "empty"}},
+			wantErr:   "activities cannot be empty",
+		},
+		{
+			name:      "duplicate activity",
+			workflows: []WorkflowConfig{{Name: "on", Activities: []string{"PowerOnPBS", "PowerOnPBS"}}},
+			wantErr:   `duplicate activity "PowerOnPBS"`,
+		},
+		{
+			name: "ordering activity not listed",
+			workflows: []WorkflowConfig{{
+				Name:       "on",
+				Activities: []string{"PowerOnPBS"},
+				Ordering:   []OrderingConfig{{Before: "PowerOnPBS", After: "BackupVMs"}},
+			}},
+			wantErr: `ordering[0] after "BackupVMs" is not listed in activities`,
+		},
+		{
+			name: "ordering activity before itself",
+			workflows: []WorkflowConfig{{
+				Name:       "on",
+				Activities: []string{"PowerOnPBS"},
+				Ordering:   []OrderingConfig{{Before: "PowerOnPBS", After: "PowerOnPBS"}},
+			}},
+			wantErr: "cannot run before itself",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				PBS: PBSConfig{
+					Host:            "h",
+					IPMI:            IPMIConfig{Host: "h", Username: "u", Password: "p"},
+					BootTimeout:     testBootTimeout,
+					ShutdownTimeout: testShutdownTimeout,
+				},
+				Proxmox:    ProxmoxConfig{Host: "h", Token: "t", Storage: "s", BackupTimeout: testBackupTimeout},
+				Monitoring: MonitoringConfig{VictoriaMetricsURL: "u"},
+				Workflows:  tt.workflows,
+			}
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
 }
@@ -0,0 +1,54 @@
+package ledger
+
+import "sync"
+
+// Collector provides thread-safe storage for the backups recorded by each activity
+// during a run.
+type Collector struct {
+	mu      sync.Mutex
+	entries map[string][]Entry // activityID -> entries
+}
+
+// NewCollector creates a new Collector.
+func NewCollector() *Collector {
+	return &Collector{
+		entries: make(map[string][]Entry),
+	}
+}
+
+// Recorder returns a Recorder that adds entries for the specified activity.
+func (c *Collector) Recorder(activityID string) Recorder {
+	return activityRecorder{collector: c, activityID: activityID}
+}
+
+// Add adds an entry for the specified activity (thread-safe).
+func (c *Collector) Add(activityID string, entry Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[activityID] = append(c.entries[activityID], entry)
+}
+
+// Get returns a copy of the entries recorded by a specific activity.
+func (c *Collector) Get(activityID string) []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := c.entries[activityID]
+	if entries == nil {
+		return nil
+	}
+	result := make([]Entry, len(entries))
+	copy(result, entries)
+	return result
+}
+
+// activityRecorder records entries for a single activity.
+type activityRecorder struct {
+	collector  *Collector
+	activityID string
+}
+
+// Record implements Recorder.
+func (r activityRecorder) Record(entry Entry) {
+	r.collector.Add(r.activityID, entry)
+}
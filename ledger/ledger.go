@@ -0,0 +1,88 @@
+// Package ledger records the outcome of each resource backup, so the history of a
+// single VM, container or file job can be followed across runs.
+//
+// Activities are given a Recorder and record one Entry for every backup they attempt:
+//
+//	a.Ledger.Record(ledger.Entry{
+//		ResourceID: ledger.ComputeID(resource.VMID),
+//		Kind:       ledger.KindCompute,
+//		Name:       resource.Name,
+//		StartedAt:  started,
+//		EndedAt:    time.Now(),
+//		Outcome:    ledger.OutcomeOf(err),
+//	})
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nomis52/goback/notify"
+)
+
+// Kind is the kind of resource that was backed up.
+type Kind string
+
+const (
+	// KindCompute is a Proxmox VM or container, backed up with vzdump.
+	KindCompute Kind = "compute"
+	// KindFiles is a file backup job, backed up with proxmox-backup-client.
+	KindFiles Kind = "files"
+)
+
+// Entry is a single backup of a resource.
+type Entry struct {
+	// ResourceID identifies the resource across runs, see ComputeID and FilesID.
+	ResourceID string `json:"resource_id"`
+	Kind       Kind   `json:"kind"`
+	Name       string `json:"name"`
+	// Node is the Proxmox node for compute resources, or the host for file jobs.
+	Node string `json:"node,omitempty"`
+	// RunID is the run the backup was part of. Set by the runner.
+	RunID string `json:"run_id,omitempty"`
+	// TaskID is the Proxmox task that ran the backup, if any.
+	TaskID    string    `json:"task_id,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	// Size is the size of the backup in bytes, or 0 if unknown.
+	Size    int64          `json:"size,omitempty"`
+	Outcome notify.Outcome `json:"outcome"`
+	Error   string         `json:"error,omitempty"`
+	// MaxBackupAge is the resource's max_backup_age when it was backed up, or 0 if
+	// backups of the resource have no maximum age.
+	MaxBackupAge time.Duration `json:"max_backup_age,omitempty"`
+}
+
+// Succeeded returns true if the backup completed successfully.
+func (e Entry) Succeeded() bool {
+	return e.Outcome == notify.OutcomeSuccess
+}
+
+// Recorder records backups as they finish. Implementations must be safe for concurrent use.
+type Recorder interface {
+	Record(Entry)
+}
+
+// ComputeID returns the resource ID of a Proxmox VM or container.
+func ComputeID(vmid int) string {
+	return fmt.Sprintf("%s-%d", KindCompute, vmid)
+}
+
+// FilesID returns the resource ID of a file backup job.
+func FilesID(job string) string {
+	return fmt.Sprintf("%s-%s", KindFiles, job)
+}
+
+// OutcomeOf returns the outcome of a backup that returned err.
+func OutcomeOf(err error) notify.Outcome {
+	switch {
+	case err == nil:
+		return notify.OutcomeSuccess
+	case errors.Is(err, context.Canceled):
+		return notify.OutcomeCancelled
+	default:
+		return notify.OutcomeFailure
+	}
+}
@@ -0,0 +1,64 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nomis52/goback/notify"
+)
+
+func TestOutcomeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want notify.Outcome
+	}{
+		{name: "success", err: nil, want: notify.OutcomeSuccess},
+		{name: "failure", err: errors.New("backup failed with exit status: ERROR"), want: notify.OutcomeFailure},
+		{name: "cancelled", err: fmt.Errorf("job not started: %w", context.Canceled), want: notify.OutcomeCancelled},
+		{name: "timed out", err: context.DeadlineExceeded, want: notify.OutcomeFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, OutcomeOf(tt.err))
+		})
+	}
+}
+
+func TestResourceIDs(t *testing.T) {
+	assert.Equal(t, "compute-105", ComputeID(105))
+	assert.Equal(t, "files-home", FilesID("home"))
+}
+
+func TestCollector(t *testing.T) {
+	collector := NewCollector()
+	assert.Nil(t, collector.Get("backup.BackupVMs"))
+
+	recorder := collector.Recorder("backup.BackupVMs")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(vmid int) {
+			defer wg.Done()
+			recorder.Record(Entry{ResourceID: ComputeID(vmid), Kind: KindCompute})
+		}(100 + i)
+	}
+	wg.Wait()
+	collector.Recorder("backup.BackupDirs").Record(Entry{ResourceID: FilesID("home"), Kind: KindFiles})
+
+	assert.Len(t, collector.Get("backup.BackupVMs"), 10)
+	entries := collector.Get("backup.BackupDirs")
+	require.Len(t, entries, 1)
+	assert.Equal(t, "files-home", entries[0].ResourceID)
+
+	// Get returns a copy
+	entries[0].Name = "changed"
+	assert.Empty(t, collector.Get("backup.BackupDirs")[0].Name)
+}
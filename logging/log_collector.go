@@ -15,23 +15,44 @@ type LogEntry struct {
 
 // LogCollector provides thread-safe storage for activity logs.
 type LogCollector struct {
-	mu   sync.RWMutex
-	logs map[string][]LogEntry // activityID -> log entries
+	mu       sync.RWMutex
+	logs     map[string][]LogEntry // activityID -> log entries
+	listener LogListener
+}
+
+// LogListener is called after each log entry is added. It must not block.
+type LogListener func(activityID string, entry LogEntry)
+
+// LogCollectorOption configures a LogCollector.
+type LogCollectorOption func(*LogCollector)
+
+// WithLogListener sets a function that is called after each log entry is added.
+func WithLogListener(listener LogListener) LogCollectorOption {
+	return func(c *LogCollector) {
+		c.listener = listener
+	}
 }
 
 // NewLogCollector creates a new LogCollector.
-func NewLogCollector() *LogCollector {
-	return &LogCollector{
+func NewLogCollector(opts ...LogCollectorOption) *LogCollector {
+	c := &LogCollector{
 		logs: make(map[string][]LogEntry),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // AddLog adds a log entry for the specified activity (thread-safe).
 func (c *LogCollector) AddLog(activityID string, entry LogEntry) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	c.logs[activityID] = append(c.logs[activityID], entry)
+	c.mu.Unlock()
+
+	if c.listener != nil {
+		c.listener(activityID, entry)
+	}
 }
 
 // GetLogs retrieves all log entries for a specific activity (thread-safe).
@@ -34,6 +34,22 @@ func TestLogCollector_AddLog(t *testing.T) {
 	assert.Equal(t, entry.Attributes["key"], logs[0].Attributes["key"])
 }
 
+func TestLogCollector_Listener(t *testing.T) {
+	var gotID string
+	var gotEntry LogEntry
+	collector := NewLogCollector(WithLogListener(func(activityID string, entry LogEntry) {
+		gotID = activityID
+		gotEntry = entry
+	}))
+
+	entry := LogEntry{Time: time.Now(), Level: "warn", Message: "disk almost full"}
+	collector.AddLog("activity1", entry)
+
+	assert.Equal(t, "activity1", gotID)
+	assert.Equal(t, entry, gotEntry)
+	assert.Len(t, collector.GetLogs("activity1"), 1)
+}
+
 func TestLogCollector_AddLog_Concurrent(t *testing.T) {
 	collector := NewLogCollector()
 	const numGoroutines = 100
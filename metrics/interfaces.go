@@ -21,6 +21,19 @@ type Counter interface {
 	Add(float64)
 }
 
+// Histogram is a metric that samples observations into configurable buckets.
+type Histogram interface {
+	// Observe adds a single observation to the histogram.
+	Observe(float64)
+}
+
+// Summary is a metric that samples observations and tracks their count, sum and
+// configurable quantiles.
+type Summary interface {
+	// Observe adds a single observation to the summary.
+	Observe(float64)
+}
+
 // GaugeVec is a Gauge with labels.
 type GaugeVec interface {
 	// With returns the Gauge for the given Labels.
@@ -33,6 +46,18 @@ type CounterVec interface {
 	With(prometheus.Labels) Counter
 }
 
+// HistogramVec is a Histogram with labels.
+type HistogramVec interface {
+	// With returns the Histogram for the given Labels.
+	With(prometheus.Labels) Histogram
+}
+
+// SummaryVec is a Summary with labels.
+type SummaryVec interface {
+	// With returns the Summary for the given Labels.
+	With(prometheus.Labels) Summary
+}
+
 // Registry creates and registers metrics.
 // Implementations handle the differences between push and scrape modes.
 type Registry interface {
@@ -47,4 +72,20 @@ type Registry interface {
 
 	// NewCounterVec creates and registers a new CounterVec.
 	NewCounterVec(opts prometheus.CounterOpts, labels []string) (CounterVec, error)
+
+	// NewHistogram creates and registers a new Histogram.
+	// If opts.Buckets is empty, prometheus.DefBuckets is used.
+	NewHistogram(opts prometheus.HistogramOpts) (Histogram, error)
+
+	// NewHistogramVec creates and registers a new HistogramVec.
+	// If opts.Buckets is empty, prometheus.DefBuckets is used.
+	NewHistogramVec(opts prometheus.HistogramOpts, labels []string) (HistogramVec, error)
+
+	// NewSummary creates and registers a new Summary.
+	// If opts.Objectives is empty, only the count and sum are tracked.
+	NewSummary(opts prometheus.SummaryOpts) (Summary, error)
+
+	// NewSummaryVec creates and registers a new SummaryVec.
+	// If opts.Objectives is empty, only the count and sum are tracked.
+	NewSummaryVec(opts prometheus.SummaryOpts, labels []string) (SummaryVec, error)
 }
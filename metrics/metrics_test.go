@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -416,4 +417,232 @@ func TestScrapeRegistry_DuplicateRegistration(t *testing.T) {
 		handler.ServeHTTP(w, req)
 		assert.Contains(t, w.Body.String(), `duplicate_counter_vec{label="b"} 3`)
 	})
+
+	t.Run("HistogramVec", func(t *testing.T) {
+		opts := prometheus.HistogramOpts{
+			Name:    "duplicate_histogram_vec",
+			Help:    "A test histogram vec",
+			Buckets: []float64{1, 10},
+		}
+		histogramVec1, err := registry.NewHistogramVec(opts, []string{"label"})
+		require.NoError(t, err)
+		histogramVec1.With(prometheus.Labels{"label": "c"}).Observe(0.5)
+
+		// Register again - should return existing histogram vec without error
+		histogramVec2, err := registry.NewHistogramVec(opts, []string{"label"})
+		require.NoError(t, err)
+
+		// Both should reference the same underlying metric
+		histogramVec2.With(prometheus.Labels{"label": "c"}).Observe(5)
+
+		handler := registry.Handler()
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		body := w.Body.String()
+		assert.Contains(t, body, `duplicate_histogram_vec_bucket{label="c",le="1"} 1`)
+		assert.Contains(t, body, `duplicate_histogram_vec_bucket{label="c",le="10"} 2`)
+		assert.Contains(t, body, `duplicate_histogram_vec_count{label="c"} 2`)
+	})
+
+	t.Run("Histogram", func(t *testing.T) {
+		opts := prometheus.HistogramOpts{
+			Name:    "duplicate_histogram",
+			Help:    "A test histogram",
+			Buckets: []float64{1, 10},
+		}
+		histogram1, err := registry.NewHistogram(opts)
+		require.NoError(t, err)
+		histogram1.Observe(5)
+
+		// Register again - should return existing histogram without error
+		histogram2, err := registry.NewHistogram(opts)
+		require.NoError(t, err)
+
+		// Both should reference the same underlying metric
+		histogram2.Observe(20)
+
+		handler := registry.Handler()
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		body := w.Body.String()
+		assert.Contains(t, body, `duplicate_histogram_bucket{le="10"} 1`)
+		assert.Contains(t, body, "duplicate_histogram_sum 25")
+		assert.Contains(t, body, "duplicate_histogram_count 2")
+	})
+
+	t.Run("SummaryVec", func(t *testing.T) {
+		opts := prometheus.SummaryOpts{
+			Name:       "duplicate_summary_vec",
+			Help:       "A test summary vec",
+			Objectives: map[float64]float64{0.5: 0.05},
+		}
+		summaryVec1, err := registry.NewSummaryVec(opts, []string{"label"})
+		require.NoError(t, err)
+		summaryVec1.With(prometheus.Labels{"label": "d"}).Observe(1)
+
+		// Register again - should return existing summary vec without error
+		summaryVec2, err := registry.NewSummaryVec(opts, []string{"label"})
+		require.NoError(t, err)
+
+		// Both should reference the same underlying metric
+		summaryVec2.With(prometheus.Labels{"label": "d"}).Observe(3)
+
+		handler := registry.Handler()
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		body := w.Body.String()
+		assert.Contains(t, body, `duplicate_summary_vec{label="d",quantile="0.5"} 1`)
+		assert.Contains(t, body, `duplicate_summary_vec_sum{label="d"} 4`)
+		assert.Contains(t, body, `duplicate_summary_vec_count{label="d"} 2`)
+	})
+}
+
+func TestPushHistogramVec_Observe(t *testing.T) {
+	receivedMetrics := make(chan []prompb.TimeSeries, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		decoded, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+
+		var writeReq prompb.WriteRequest
+		require.NoError(t, proto.Unmarshal(decoded, &writeReq))
+
+		receivedMetrics <- writeReq.Timeseries
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	registry := NewPushRegistry(PushConfig{URL: server.URL, Prefix: "test"})
+
+	histogramVec, err := registry.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "test_duration_seconds",
+		Help:    "A test histogram",
+		Buckets: []float64{1, 10, math.Inf(+1)},
+	}, []string{"activity"})
+	require.NoError(t, err)
+
+	histogramVec.With(prometheus.Labels{"activity": "a"}).Observe(0.5)
+	histogramVec.With(prometheus.Labels{"activity": "a"}).Observe(5)
+
+	findLabel := func(labels []prompb.Label, name string) string {
+		for _, l := range labels {
+			if l.Name == name {
+				return l.Value
+			}
+		}
+		return ""
+	}
+
+	// Each observation pushes every series of the histogram in one request
+	var received []prompb.TimeSeries
+	for i := 0; i < 2; i++ {
+		select {
+		case received = <-receivedMetrics:
+			require.Len(t, received, 5)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for observation %d", i+1)
+		}
+	}
+
+	got := make(map[string]float64)
+	for _, ts := range received {
+		assert.Equal(t, "a", findLabel(ts.Labels, "activity"))
+		require.Len(t, ts.Samples, 1)
+		key := findLabel(ts.Labels, "__name__")
+		if le := findLabel(ts.Labels, "le"); le != "" {
+			key += "{le=" + le + "}"
+		}
+		got[key] = ts.Samples[0].Value
+	}
+	assert.Equal(t, map[string]float64{
+		"test_test_duration_seconds_bucket{le=1}":    1,
+		"test_test_duration_seconds_bucket{le=10}":   2,
+		"test_test_duration_seconds_bucket{le=+Inf}": 2,
+		"test_test_duration_seconds_sum":             5.5,
+		"test_test_duration_seconds_count":           2,
+	}, got)
+}
+
+func TestPushHistogram_InvalidBuckets(t *testing.T) {
+	registry := NewPushRegistry(PushConfig{URL: "http://localhost:9090"})
+
+	_, err := registry.NewHistogram(prometheus.HistogramOpts{
+		Name:    "test_histogram",
+		Help:    "A test histogram",
+		Buckets: []float64{10, 1},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "buckets are not in increasing order")
+}
+
+func TestPushSummary_Observe(t *testing.T) {
+	receivedMetrics := make(chan []prompb.TimeSeries, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		decoded, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+
+		var writeReq prompb.WriteRequest
+		require.NoError(t, proto.Unmarshal(decoded, &writeReq))
+
+		receivedMetrics <- writeReq.Timeseries
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	registry := NewPushRegistry(PushConfig{URL: server.URL})
+
+	summary, err := registry.NewSummary(prometheus.SummaryOpts{
+		Name:       "test_size_bytes",
+		Help:       "A test summary",
+		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01},
+	})
+	require.NoError(t, err)
+
+	summary.Observe(1)
+	summary.Observe(2)
+	summary.Observe(3)
+
+	findLabel := func(labels []prompb.Label, name string) string {
+		for _, l := range labels {
+			if l.Name == name {
+				return l.Value
+			}
+		}
+		return ""
+	}
+
+	// Each observation pushes every series of the summary in one request
+	var received []prompb.TimeSeries
+	for i := 0; i < 3; i++ {
+		select {
+		case received = <-receivedMetrics:
+			require.Len(t, received, 4)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for observation %d", i+1)
+		}
+	}
+
+	got := make(map[string]float64)
+	for _, ts := range received {
+		require.Len(t, ts.Samples, 1)
+		key := findLabel(ts.Labels, "__name__")
+		if q := findLabel(ts.Labels, "quantile"); q != "" {
+			key += "{quantile=" + q + "}"
+		}
+		got[key] = ts.Samples[0].Value
+	}
+	assert.Equal(t, map[string]float64{
+		"test_size_bytes{quantile=0.5}": 2,
+		"test_size_bytes{quantile=0.9}": 3,
+		"test_size_bytes_sum":           6,
+		"test_size_bytes_count":         3,
+	}, got)
 }
@@ -5,13 +5,17 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/snappy"
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/prometheus/prompb"
 )
 
@@ -93,6 +97,63 @@ func (r *PushRegistry) NewCounterVec(opts prometheus.CounterOpts, labels []strin
 	}, nil
 }
 
+// NewHistogram creates a new push-based Histogram.
+// Each observation pushes the histogram's _bucket, _sum and _count series in a single request.
+func (r *PushRegistry) NewHistogram(opts prometheus.HistogramOpts) (Histogram, error) {
+	buckets, err := histogramBuckets(opts)
+	if err != nil {
+		return nil, err
+	}
+	return newPushHistogram(r.pusher, opts.Name, nil, buckets), nil
+}
+
+// NewHistogramVec creates a new push-based HistogramVec.
+// Each observation pushes the histogram's _bucket, _sum and _count series in a single request.
+func (r *PushRegistry) NewHistogramVec(opts prometheus.HistogramOpts, labels []string) (HistogramVec, error) {
+	buckets, err := histogramBuckets(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &pushHistogramVec{
+		pusher:  r.pusher,
+		name:    opts.Name,
+		labels:  labels,
+		buckets: buckets,
+	}, nil
+}
+
+// NewSummary creates a new push-based Summary.
+// Each observation pushes the summary's quantile, _sum and _count series in a single request.
+func (r *PushRegistry) NewSummary(opts prometheus.SummaryOpts) (Summary, error) {
+	return newPushSummary(r.pusher, opts, nil), nil
+}
+
+// NewSummaryVec creates a new push-based SummaryVec.
+// Each observation pushes the summary's quantile, _sum and _count series in a single request.
+func (r *PushRegistry) NewSummaryVec(opts prometheus.SummaryOpts, labels []string) (SummaryVec, error) {
+	return &pushSummaryVec{
+		pusher: r.pusher,
+		opts:   opts,
+		labels: labels,
+	}, nil
+}
+
+// histogramBuckets returns the upper bounds of a histogram's buckets, excluding +Inf.
+func histogramBuckets(opts prometheus.HistogramOpts) ([]float64, error) {
+	buckets := opts.Buckets
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+	if n := len(buckets); math.IsInf(buckets[n-1], +1) {
+		// The +Inf bucket is always pushed, so drop it if it was given explicitly
+		buckets = buckets[:n-1]
+	}
+	if !sort.Float64sAreSorted(buckets) {
+		return nil, fmt.Errorf("histogram %q buckets are not in increasing order", opts.Name)
+	}
+	return buckets, nil
+}
+
 // pusher handles remote write to VictoriaMetrics/Prometheus.
 type pusher struct {
 	url        string
@@ -105,10 +166,13 @@ type pusher struct {
 
 // push sends a single metric to the remote write endpoint.
 func (p *pusher) push(name string, value float64, labels map[string]string) error {
-	ts := p.metricToTimeSeries(name, value, labels)
+	return p.write([]prompb.TimeSeries{p.metricToTimeSeries(name, value, labels)})
+}
 
+// write sends the given time series to the remote write endpoint in a single request.
+func (p *pusher) write(series []prompb.TimeSeries) error {
 	req := &prompb.WriteRequest{
-		Timeseries: []prompb.TimeSeries{ts},
+		Timeseries: series,
 	}
 
 	data, err := proto.Marshal(req)
@@ -274,11 +338,182 @@ func (c *pushCounterVec) With(labels prometheus.Labels) Counter {
 	return counter
 }
 
+// pushHistogram implements Histogram for push mode.
+type pushHistogram struct {
+	mu      sync.Mutex
+	pusher  *pusher
+	name    string
+	labels  map[string]string
+	buckets []float64 // upper bounds, excluding +Inf
+	counts  []uint64  // observations per bucket, not cumulative
+	sum     float64
+	count   uint64
+}
+
+func newPushHistogram(p *pusher, name string, labels map[string]string, buckets []float64) *pushHistogram {
+	return &pushHistogram{
+		pusher:  p,
+		name:    name,
+		labels:  labels,
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+func (h *pushHistogram) Observe(v float64) {
+	h.mu.Lock()
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+	series := h.series()
+	h.mu.Unlock()
+	_ = h.pusher.write(series)
+}
+
+// series returns the cumulative _bucket series followed by _sum and _count.
+// The caller must hold h.mu.
+func (h *pushHistogram) series() []prompb.TimeSeries {
+	series := make([]prompb.TimeSeries, 0, len(h.buckets)+3)
+	var cumulative uint64
+	for i, upper := range h.buckets {
+		cumulative += h.counts[i]
+		series = append(series, h.pusher.metricToTimeSeries(h.name+"_bucket", float64(cumulative), withLabel(h.labels, "le", formatFloat(upper))))
+	}
+	series = append(series,
+		h.pusher.metricToTimeSeries(h.name+"_bucket", float64(h.count), withLabel(h.labels, "le", "+Inf")),
+		h.pusher.metricToTimeSeries(h.name+"_sum", h.sum, h.labels),
+		h.pusher.metricToTimeSeries(h.name+"_count", float64(h.count), h.labels),
+	)
+	return series
+}
+
+// pushHistogramVec implements HistogramVec for push mode.
+type pushHistogramVec struct {
+	mu         sync.Mutex
+	pusher     *pusher
+	name       string
+	labels     []string
+	buckets    []float64
+	histograms map[string]*pushHistogram
+}
+
+func (h *pushHistogramVec) With(labels prometheus.Labels) Histogram {
+	key := labelsToKey(labels)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.histograms == nil {
+		h.histograms = make(map[string]*pushHistogram)
+	}
+
+	if histogram, ok := h.histograms[key]; ok {
+		return histogram
+	}
+
+	histogram := newPushHistogram(h.pusher, h.name, labels, h.buckets)
+	h.histograms[key] = histogram
+	return histogram
+}
+
+// pushSummary implements Summary for push mode.
+// Quantiles are calculated by a prometheus.Summary that is never registered.
+type pushSummary struct {
+	mu      sync.Mutex
+	pusher  *pusher
+	name    string
+	labels  map[string]string
+	summary prometheus.Summary
+}
+
+func newPushSummary(p *pusher, opts prometheus.SummaryOpts, labels map[string]string) *pushSummary {
+	return &pushSummary{
+		pusher:  p,
+		name:    opts.Name,
+		labels:  labels,
+		summary: prometheus.NewSummary(opts),
+	}
+}
+
+func (s *pushSummary) Observe(v float64) {
+	s.mu.Lock()
+	s.summary.Observe(v)
+	var m dto.Metric
+	err := s.summary.Write(&m)
+	s.mu.Unlock()
+	if err != nil {
+		return
+	}
+
+	quantiles := m.GetSummary().GetQuantile()
+	series := make([]prompb.TimeSeries, 0, len(quantiles)+2)
+	for _, q := range quantiles {
+		series = append(series, s.pusher.metricToTimeSeries(s.name, q.GetValue(), withLabel(s.labels, "quantile", formatFloat(q.GetQuantile()))))
+	}
+	series = append(series,
+		s.pusher.metricToTimeSeries(s.name+"_sum", m.GetSummary().GetSampleSum(), s.labels),
+		s.pusher.metricToTimeSeries(s.name+"_count", float64(m.GetSummary().GetSampleCount()), s.labels),
+	)
+	_ = s.pusher.write(series)
+}
+
+// pushSummaryVec implements SummaryVec for push mode.
+type pushSummaryVec struct {
+	mu        sync.Mutex
+	pusher    *pusher
+	opts      prometheus.SummaryOpts
+	labels    []string
+	summaries map[string]*pushSummary
+}
+
+func (s *pushSummaryVec) With(labels prometheus.Labels) Summary {
+	key := labelsToKey(labels)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.summaries == nil {
+		s.summaries = make(map[string]*pushSummary)
+	}
+
+	if summary, ok := s.summaries[key]; ok {
+		return summary
+	}
+
+	summary := newPushSummary(s.pusher, s.opts, labels)
+	s.summaries[key] = summary
+	return summary
+}
+
+// withLabel returns a copy of labels with an extra label added.
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	result := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		result[k] = v
+	}
+	result[name] = value
+	return result
+}
+
+// formatFloat formats a bucket bound or quantile the way Prometheus does in label values.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
 // labelsToKey creates a string key from labels for map lookup.
+// Names are sorted so the same labels always produce the same key.
 func labelsToKey(labels prometheus.Labels) string {
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
 	var key string
-	for k, v := range labels {
-		key += k + "=" + v + ","
+	for _, k := range names {
+		key += k + "=" + labels[k] + ","
 	}
 	return key
 }
@@ -121,6 +121,78 @@ func (r *ScrapeRegistry) NewCounterVec(opts prometheus.CounterOpts, labels []str
 	return &scrapeCounterVec{counterVec: c}, nil
 }
 
+// NewHistogram creates and registers a new Histogram.
+// If a histogram with the same name is already registered, the existing one is returned.
+func (r *ScrapeRegistry) NewHistogram(opts prometheus.HistogramOpts) (Histogram, error) {
+	h := prometheus.NewHistogram(opts)
+	if err := r.prom.Register(h); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			existing, ok := alreadyRegistered.ExistingCollector.(prometheus.Histogram)
+			if !ok {
+				return nil, fmt.Errorf("existing collector %q is not a Histogram", opts.Name)
+			}
+			return existing, nil
+		}
+		return nil, fmt.Errorf("registering histogram %q: %w", opts.Name, err)
+	}
+	return h, nil
+}
+
+// NewHistogramVec creates and registers a new HistogramVec.
+// If a histogram vec with the same name is already registered, the existing one is returned.
+func (r *ScrapeRegistry) NewHistogramVec(opts prometheus.HistogramOpts, labels []string) (HistogramVec, error) {
+	h := prometheus.NewHistogramVec(opts, labels)
+	if err := r.prom.Register(h); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			existing, ok := alreadyRegistered.ExistingCollector.(*prometheus.HistogramVec)
+			if !ok {
+				return nil, fmt.Errorf("existing collector %q is not a HistogramVec", opts.Name)
+			}
+			return &scrapeHistogramVec{histogramVec: existing}, nil
+		}
+		return nil, fmt.Errorf("registering histogram vec %q: %w", opts.Name, err)
+	}
+	return &scrapeHistogramVec{histogramVec: h}, nil
+}
+
+// NewSummary creates and registers a new Summary.
+// If a summary with the same name is already registered, the existing one is returned.
+func (r *ScrapeRegistry) NewSummary(opts prometheus.SummaryOpts) (Summary, error) {
+	sm := prometheus.NewSummary(opts)
+	if err := r.prom.Register(sm); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			existing, ok := alreadyRegistered.ExistingCollector.(prometheus.Summary)
+			if !ok {
+				return nil, fmt.Errorf("existing collector %q is not a Summary", opts.Name)
+			}
+			return existing, nil
+		}
+		return nil, fmt.Errorf("registering summary %q: %w", opts.Name, err)
+	}
+	return sm, nil
+}
+
+// NewSummaryVec creates and registers a new SummaryVec.
+// If a summary vec with the same name is already registered, the existing one is returned.
+func (r *ScrapeRegistry) NewSummaryVec(opts prometheus.SummaryOpts, labels []string) (SummaryVec, error) {
+	sm := prometheus.NewSummaryVec(opts, labels)
+	if err := r.prom.Register(sm); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			existing, ok := alreadyRegistered.ExistingCollector.(*prometheus.SummaryVec)
+			if !ok {
+				return nil, fmt.Errorf("existing collector %q is not a SummaryVec", opts.Name)
+			}
+			return &scrapeSummaryVec{summaryVec: existing}, nil
+		}
+		return nil, fmt.Errorf("registering summary vec %q: %w", opts.Name, err)
+	}
+	return &scrapeSummaryVec{summaryVec: sm}, nil
+}
+
 // scrapeGauge wraps prometheus.Gauge to implement Gauge interface.
 type scrapeGauge struct {
 	gauge prometheus.Gauge
@@ -160,3 +232,21 @@ type scrapeCounterVec struct {
 func (c *scrapeCounterVec) With(labels prometheus.Labels) Counter {
 	return &scrapeCounter{counter: c.counterVec.With(labels)}
 }
+
+// scrapeHistogramVec wraps prometheus.HistogramVec to implement HistogramVec interface.
+type scrapeHistogramVec struct {
+	histogramVec *prometheus.HistogramVec
+}
+
+func (h *scrapeHistogramVec) With(labels prometheus.Labels) Histogram {
+	return h.histogramVec.With(labels)
+}
+
+// scrapeSummaryVec wraps prometheus.SummaryVec to implement SummaryVec interface.
+type scrapeSummaryVec struct {
+	summaryVec *prometheus.SummaryVec
+}
+
+func (s *scrapeSummaryVec) With(labels prometheus.Labels) Summary {
+	return s.summaryVec.With(labels)
+}
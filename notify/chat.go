@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// discordMaxContent is the maximum length of a Discord message.
+const discordMaxContent = 2000
+
+// Chat posts the notification to a Slack or Discord incoming webhook.
+type Chat struct {
+	url     string
+	payload func(msg Message) any
+	client  *http.Client
+}
+
+// NewSlack creates a sink for a Slack-compatible incoming webhook.
+func NewSlack(url string) *Chat {
+	return &Chat{
+		url: url,
+		payload: func(msg Message) any {
+			return map[string]string{"text": fmt.Sprintf("*%s*\n```\n%s\n```", msg.Title, msg.Body)}
+		},
+		client: &http.Client{},
+	}
+}
+
+// NewDiscord creates a sink for a Discord incoming webhook.
+func NewDiscord(url string) *Chat {
+	return &Chat{
+		url: url,
+		payload: func(msg Message) any {
+			content := fmt.Sprintf("**%s**\n```\n%s\n```", msg.Title, msg.Body)
+			if runes := []rune(content); len(runes) > discordMaxContent {
+				suffix := "…\n```"
+				content = string(runes[:discordMaxContent-len([]rune(suffix))]) + suffix
+			}
+			return map[string]string{"content": content}
+		},
+		client: &http.Client{},
+	}
+}
+
+// Send implements Sink.
+func (c *Chat) Send(ctx context.Context, msg Message) error {
+	return doJSON(ctx, c.client, http.MethodPost, c.url, nil, c.payload(msg))
+}
+
+// Matrix sends the notification to a Matrix room.
+type Matrix struct {
+	homeserver string
+	token      string
+	room       string
+	client     *http.Client
+}
+
+// matrixMessage is an m.room.message event with a plain-text body.
+type matrixMessage struct {
+	MsgType string `json:"msgtype"`
+	Body    string `json:"body"`
+}
+
+// NewMatrix creates a Matrix sink. The token is the access token of the sending user,
+// which must already have joined the room.
+func NewMatrix(homeserver, token, room string) *Matrix {
+	return &Matrix{homeserver: homeserver, token: token, room: room, client: &http.Client{}}
+}
+
+// Send implements Sink.
+// It calls PUT /_matrix/client/v3/rooms/{room}/send/m.room.message/{txnId}
+func (m *Matrix) Send(ctx context.Context, msg Message) error {
+	txnID := fmt.Sprintf("goback-%s-%d", msg.Event.RunID, time.Now().UnixNano())
+	u := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimSuffix(m.homeserver, "/"), url.PathEscape(m.room), url.PathEscape(txnID))
+
+	headers := map[string]string{"Authorization": "Bearer " + m.token}
+	return doJSON(ctx, m.client, http.MethodPut, u, headers, matrixMessage{
+		MsgType: "m.text",
+		Body:    msg.Title + "\n\n" + msg.Body,
+	})
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Outcome is how a run finished.
+type Outcome string
+
+const (
+	OutcomeSuccess   Outcome = "success"
+	OutcomeFailure   Outcome = "failure"
+	OutcomeCancelled Outcome = "cancelled"
+	OutcomeDeadline  Outcome = "deadline" // the run was still going when its deadline and grace period passed
+)
+
+// Event describes a finished run.
+type Event struct {
+	RunID     string    `json:"run_id"`
+	Workflows []string  `json:"workflows"`
+	Outcome   Outcome   `json:"outcome"`
+	Recovered bool      `json:"recovered"` // true if the run succeeded and the previous run did not
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	Error     string    `json:"error,omitempty"`
+	// Activities holds the per-activity results of the run
+	Activities []Activity `json:"activities"`
+}
+
+// Activity is the result of a single activity within a run.
+type Activity struct {
+	Module   string `json:"module"`
+	Type     string `json:"type"`
+	State    string `json:"state"`
+	Error    string `json:"error,omitempty"`
+	Attempts int    `json:"attempts,omitempty"`
+}
+
+// Duration returns how long the run took, rounded to the second.
+func (e Event) Duration() time.Duration {
+	return e.EndedAt.Sub(e.StartedAt).Round(time.Second)
+}
+
+// Title returns a one line summary of the event, e.g. "goback backup+poweroff failed".
+func (e Event) Title() string {
+	var verb string
+	switch {
+	case e.Recovered:
+		verb = "recovered"
+	case e.Outcome == OutcomeSuccess:
+		verb = "succeeded"
+	case e.Outcome == OutcomeCancelled:
+		verb = "was cancelled"
+	case e.Outcome == OutcomeDeadline:
+		verb = "overran its deadline"
+	default:
+		verb = "failed"
+	}
+	return fmt.Sprintf("goback %s %s", strings.Join(e.Workflows, "+"), verb)
+}
@@ -0,0 +1,147 @@
+// Package notify sends a summary of each finished run to configurable sinks.
+//
+// Supported sinks:
+//   - smtp: plain-text email
+//   - webhook: generic JSON POST
+//   - ntfy and gotify: push notifications
+//   - slack and discord: incoming webhooks
+//   - matrix: a message to a room via the client-server API
+//
+// Each sink has rules selecting which run outcomes it is notified of, e.g. only
+// failures, or the first successful run after a failure (a recovery).
+//
+// Example usage:
+//
+//	n, err := notify.New(cfg.Notify, logger)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = n.Notify(ctx, notify.Event{RunID: "abc", Outcome: notify.OutcomeFailure, ...})
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"text/template"
+	"time"
+
+	"github.com/nomis52/goback/config"
+)
+
+// sendTimeout bounds the time spent delivering a notification to a single sink.
+const sendTimeout = 30 * time.Second
+
+// Sink delivers a rendered notification.
+type Sink interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Message is a rendered notification.
+type Message struct {
+	Title string
+	Body  string
+	Event Event
+}
+
+// Notifier dispatches run events to the configured sinks.
+type Notifier struct {
+	sinks  []*rule
+	logger *slog.Logger
+}
+
+// rule pairs a sink with the outcomes it is notified of and its body template.
+type rule struct {
+	name string
+	on   []string
+	tmpl *template.Template
+	sink Sink
+}
+
+// New creates a Notifier for the sinks in the notify config.
+func New(cfg config.NotifyConfig, logger *slog.Logger) (*Notifier, error) {
+	n := &Notifier{logger: logger}
+	for _, sc := range cfg.Sinks {
+		sink, err := newSink(sc)
+		if err != nil {
+			return nil, fmt.Errorf("sink %q: %w", sc.Name, err)
+		}
+
+		tmpl, err := parseTemplate(sc.Name, sc.Template)
+		if err != nil {
+			return nil, fmt.Errorf("sink %q: %w", sc.Name, err)
+		}
+
+		n.sinks = append(n.sinks, &rule{
+			name: sc.Name,
+			on:   sc.On,
+			tmpl: tmpl,
+			sink: sink,
+		})
+	}
+	return n, nil
+}
+
+// Notify sends the event to every sink whose rules match it.
+// All matching sinks are attempted; the errors from any that fail are joined.
+func (n *Notifier) Notify(ctx context.Context, event Event) error {
+	var errs []error
+	for _, r := range n.sinks {
+		if !r.matches(event) {
+			continue
+		}
+
+		body, err := render(r.tmpl, event)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sink %q: %w", r.name, err))
+			continue
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err = r.sink.Send(sendCtx, Message{Title: event.Title(), Body: body, Event: event})
+		cancel()
+		if err != nil {
+			n.logger.Error("failed to send notification", "sink", r.name, "run_id", event.RunID, "error", err)
+			errs = append(errs, fmt.Errorf("sink %q: %w", r.name, err))
+			continue
+		}
+		n.logger.Debug("sent notification", "sink", r.name, "run_id", event.RunID, "outcome", event.Outcome)
+	}
+	return errors.Join(errs...)
+}
+
+// matches reports whether the sink should be notified of the event.
+// A sink without rules is notified of every run.
+func (r *rule) matches(event Event) bool {
+	if len(r.on) == 0 {
+		return true
+	}
+	if event.Recovered && slices.Contains(r.on, config.NotifyOnRecovery) {
+		return true
+	}
+	return slices.Contains(r.on, string(event.Outcome))
+}
+
+// newSink creates the sink for the configured type.
+func newSink(sc config.NotifySinkConfig) (Sink, error) {
+	switch sc.Type {
+	case config.NotifyTypeSMTP:
+		return NewSMTP(sc.SMTP), nil
+	case config.NotifyTypeWebhook:
+		return NewWebhook(sc.URL, sc.Headers), nil
+	case config.NotifyTypeNtfy:
+		return NewNtfy(sc.URL, sc.Token, sc.Priority), nil
+	case config.NotifyTypeGotify:
+		return NewGotify(sc.URL, sc.Token, sc.Priority), nil
+	case config.NotifyTypeSlack:
+		return NewSlack(sc.URL), nil
+	case config.NotifyTypeDiscord:
+		return NewDiscord(sc.URL), nil
+	case config.NotifyTypeMatrix:
+		return NewMatrix(sc.URL, sc.Token, sc.Room), nil
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", sc.Type)
+	}
+}
@@ -0,0 +1,191 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nomis52/goback/config"
+)
+
+func testEvent(outcome Outcome) Event {
+	start := time.Date(2026, 3, 1, 4, 5, 0, 0, time.UTC)
+	return Event{
+		RunID:     "1772337900",
+		Workflows: []string{"backup", "poweroff"},
+		Outcome:   outcome,
+		StartedAt: start,
+		EndedAt:   start.Add(42*time.Minute + 10*time.Second),
+		Activities: []Activity{
+			{Module: "backup", Type: "PowerOnPBS", State: "completed", Attempts: 2},
+			{Module: "backup", Type: "BackupVMs", State: "completed"},
+		},
+	}
+}
+
+func TestRule_Matches(t *testing.T) {
+	recovered := testEvent(OutcomeSuccess)
+	recovered.Recovered = true
+
+	tests := []struct {
+		name  string
+		on    []string
+		event Event
+		want  bool
+	}{
+		{name: "no rules matches success", event: testEvent(OutcomeSuccess), want: true},
+		{name: "no rules matches failure", event: testEvent(OutcomeFailure), want: true},
+		{name: "failure only skips success", on: []string{"failure"}, event: testEvent(OutcomeSuccess), want: false},
+		{name: "failure only matches failure", on: []string{"failure"}, event: testEvent(OutcomeFailure), want: true},
+		{name: "recovery skips plain success", on: []string{"recovery"}, event: testEvent(OutcomeSuccess), want: false},
+		{name: "recovery matches recovered run", on: []string{"recovery"}, event: recovered, want: true},
+		{name: "cancelled", on: []string{"failure", "cancelled"}, event: testEvent(OutcomeCancelled), want: true},
+		{name: "failure only skips deadline", on: []string{"failure"}, event: testEvent(OutcomeDeadline), want: false},
+		{name: "deadline", on: []string{"failure", "deadline"}, event: testEvent(OutcomeDeadline), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &rule{on: tt.on}
+			assert.Equal(t, tt.want, r.matches(tt.event))
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Run("default template", func(t *testing.T) {
+		tmpl, err := parseTemplate("test", "")
+		require.NoError(t, err)
+
+		event := testEvent(OutcomeFailure)
+		event.Error = "workflow execution failed"
+		event.Activities[1].State = "failed"
+		event.Activities[1].Error = "2 of 3 backups failed"
+
+		body, err := render(tmpl, event)
+		require.NoError(t, err)
+		assert.Equal(t, `Run 1772337900 failure
+Workflows: backup, poweroff
+Duration: 42m10s
+Error: workflow execution failed
+
+Activities:
+  - PowerOnPBS: completed (2 attempts)
+  - BackupVMs: failed: 2 of 3 backups failed
+`, body)
+	})
+
+	t.Run("custom template", func(t *testing.T) {
+		tmpl, err := parseTemplate("test", `{{.Outcome}} after {{.Duration}} ({{join .Workflows "/"}})`)
+		require.NoError(t, err)
+
+		body, err := render(tmpl, testEvent(OutcomeSuccess))
+		require.NoError(t, err)
+		assert.Equal(t, "success after 42m10s (backup/poweroff)", body)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, err := parseTemplate("test", "{{.Outcome")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid template")
+	})
+}
+
+func TestEvent_Title(t *testing.T) {
+	recovered := testEvent(OutcomeSuccess)
+	recovered.Recovered = true
+
+	assert.Equal(t, "goback backup+poweroff succeeded", testEvent(OutcomeSuccess).Title())
+	assert.Equal(t, "goback backup+poweroff failed", testEvent(OutcomeFailure).Title())
+	assert.Equal(t, "goback backup+poweroff was cancelled", testEvent(OutcomeCancelled).Title())
+	assert.Equal(t, "goback backup+poweroff overran its deadline", testEvent(OutcomeDeadline).Title())
+	assert.Equal(t, "goback backup+poweroff recovered", recovered.Title())
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	failures := &mockSink{}
+	everything := &mockSink{}
+	broken := &mockSink{err: errors.New("connection refused")}
+
+	tmpl, err := parseTemplate("test", "")
+	require.NoError(t, err)
+
+	n := &Notifier{
+		logger: slog.Default(),
+		sinks: []*rule{
+			{name: "failures", on: []string{config.NotifyOnFailure}, tmpl: tmpl, sink: failures},
+			{name: "everything", tmpl: tmpl, sink: everything},
+			{name: "broken", tmpl: tmpl, sink: broken},
+		},
+	}
+
+	err = n.Notify(context.Background(), testEvent(OutcomeSuccess))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `sink "broken": connection refused`)
+
+	assert.Empty(t, failures.sent)
+	require.Len(t, everything.sent, 1)
+	assert.Equal(t, "goback backup+poweroff succeeded", everything.sent[0].Title)
+	assert.Contains(t, everything.sent[0].Body, "Run 1772337900 success")
+	assert.Len(t, broken.sent, 1, "failing sinks are still attempted")
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.NotifyConfig
+		wantErr string
+	}{
+		{
+			name: "all sink types",
+			cfg: config.NotifyConfig{Sinks: []config.NotifySinkConfig{
+				{Name: "mail", Type: config.NotifyTypeSMTP, SMTP: config.SMTPConfig{Host: "smtp:25", From: "a@b", To: []string{"c@d"}}},
+				{Name: "hook", Type: config.NotifyTypeWebhook, URL: "http://hook"},
+				{Name: "ntfy", Type: config.NotifyTypeNtfy, URL: "https://ntfy.sh/backups"},
+				{Name: "gotify", Type: config.NotifyTypeGotify, URL: "https://gotify", Token: "t"},
+				{Name: "slack", Type: config.NotifyTypeSlack, URL: "https://hooks.slack.com/x"},
+				{Name: "discord", Type: config.NotifyTypeDiscord, URL: "https://discord.com/api/webhooks/x"},
+				{Name: "matrix", Type: config.NotifyTypeMatrix, URL: "https://matrix.org", Token: "t", Room: "!r:matrix.org"},
+			}},
+		},
+		{
+			name:    "unknown type",
+			cfg:     config.NotifyConfig{Sinks: []config.NotifySinkConfig{{Name: "pager", Type: "pager"}}},
+			wantErr: `sink "pager": unknown sink type "pager"`,
+		},
+		{
+			name:    "bad template",
+			cfg:     config.NotifyConfig{Sinks: []config.NotifySinkConfig{{Name: "hook", Type: config.NotifyTypeWebhook, Template: "{{"}}},
+			wantErr: `sink "hook": invalid template`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := New(tt.cfg, slog.Default())
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, n.sinks, len(tt.cfg.Sinks))
+		})
+	}
+}
+
+// mockSink records the messages it is sent.
+type mockSink struct {
+	sent []Message
+	err  error
+}
+
+func (m *mockSink) Send(ctx context.Context, msg Message) error {
+	m.sent = append(m.sent, msg)
+	return m.err
+}
@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Ntfy publishes the notification to an ntfy topic.
+type Ntfy struct {
+	url      string // topic URL, e.g. https://ntfy.sh/my-backups
+	token    string // optional access token
+	priority int
+	client   *http.Client
+}
+
+// NewNtfy creates an ntfy sink. token and priority are optional.
+func NewNtfy(url, token string, priority int) *Ntfy {
+	return &Ntfy{url: url, token: token, priority: priority, client: &http.Client{}}
+}
+
+// Send implements Sink.
+func (n *Ntfy) Send(ctx context.Context, msg Message) error {
+	headers := map[string]string{
+		"Title": msg.Title,
+		"Tags":  ntfyTag(msg.Event),
+	}
+	if n.priority > 0 {
+		headers["Priority"] = strconv.Itoa(n.priority)
+	}
+	if n.token != "" {
+		headers["Authorization"] = "Bearer " + n.token
+	}
+	return do(ctx, n.client, http.MethodPost, n.url, headers, []byte(msg.Body))
+}
+
+// ntfyTag returns the emoji tag shown next to the notification.
+func ntfyTag(event Event) string {
+	switch event.Outcome {
+	case OutcomeSuccess:
+		return "white_check_mark"
+	case OutcomeCancelled, OutcomeDeadline:
+		return "warning"
+	default:
+		return "rotating_light"
+	}
+}
+
+// Gotify sends the notification to a Gotify server.
+type Gotify struct {
+	url      string // server URL, e.g. https://gotify.example.com
+	token    string // application token
+	priority int
+	client   *http.Client
+}
+
+// gotifyMessage is the body of a Gotify POST /message request.
+type gotifyMessage struct {
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Priority int    `json:"priority,omitempty"`
+}
+
+// NewGotify creates a Gotify sink using an application token.
+func NewGotify(url, token string, priority int) *Gotify {
+	return &Gotify{url: url, token: token, priority: priority, client: &http.Client{}}
+}
+
+// Send implements Sink.
+func (g *Gotify) Send(ctx context.Context, msg Message) error {
+	headers := map[string]string{"X-Gotify-Key": g.token}
+	return doJSON(ctx, g.client, http.MethodPost, strings.TrimSuffix(g.url, "/")+"/message", headers, gotifyMessage{
+		Title:    msg.Title,
+		Message:  msg.Body,
+		Priority: g.priority,
+	})
+}
@@ -0,0 +1,202 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nomis52/goback/config"
+)
+
+func TestHTTPSinks(t *testing.T) {
+	msg := Message{Title: "goback backup failed", Body: "Run 1 failure", Event: testEvent(OutcomeFailure)}
+
+	tests := []struct {
+		name     string
+		sink     func(url string) Sink
+		status   int
+		wantErr  string
+		verifyFn func(t *testing.T, r *http.Request, body []byte)
+	}{
+		{
+			name:   "webhook",
+			sink:   func(url string) Sink { return NewWebhook(url+"/hook", map[string]string{"X-Api-Key": "k"}) },
+			status: http.StatusOK,
+			verifyFn: func(t *testing.T, r *http.Request, body []byte) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/hook", r.URL.Path)
+				assert.Equal(t, "k", r.Header.Get("X-Api-Key"))
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+				var payload webhookPayload
+				require.NoError(t, json.Unmarshal(body, &payload))
+				assert.Equal(t, msg.Title, payload.Title)
+				assert.Equal(t, msg.Body, payload.Body)
+				assert.Equal(t, OutcomeFailure, payload.Event.Outcome)
+				assert.Len(t, payload.Event.Activities, 2)
+			},
+		},
+		{
+			name:   "ntfy",
+			sink:   func(url string) Sink { return NewNtfy(url+"/backups", "tk", 4) },
+			status: http.StatusOK,
+			verifyFn: func(t *testing.T, r *http.Request, body []byte) {
+				assert.Equal(t, "/backups", r.URL.Path)
+				assert.Equal(t, msg.Title, r.Header.Get("Title"))
+				assert.Equal(t, "4", r.Header.Get("Priority"))
+				assert.Equal(t, "rotating_light", r.Header.Get("Tags"))
+				assert.Equal(t, "Bearer tk", r.Header.Get("Authorization"))
+				assert.Equal(t, msg.Body, string(body))
+			},
+		},
+		{
+			name:   "gotify",
+			sink:   func(url string) Sink { return NewGotify(url+"/", "app-token", 8) },
+			status: http.StatusOK,
+			verifyFn: func(t *testing.T, r *http.Request, body []byte) {
+				assert.Equal(t, "/message", r.URL.Path)
+				assert.Equal(t, "app-token", r.Header.Get("X-Gotify-Key"))
+				assert.JSONEq(t, `{"title": "goback backup failed", "message": "Run 1 failure", "priority": 8}`, string(body))
+			},
+		},
+		{
+			name:   "slack",
+			sink:   func(url string) Sink { return NewSlack(url) },
+			status: http.StatusOK,
+			verifyFn: func(t *testing.T, r *http.Request, body []byte) {
+				assert.JSONEq(t, `{"text": "*goback backup failed*\n`+"```"+`\nRun 1 failure\n`+"```"+`"}`, string(body))
+			},
+		},
+		{
+			name:   "discord",
+			sink:   func(url string) Sink { return NewDiscord(url) },
+			status: http.StatusNoContent,
+			verifyFn: func(t *testing.T, r *http.Request, body []byte) {
+				assert.JSONEq(t, `{"content": "**goback backup failed**\n`+"```"+`\nRun 1 failure\n`+"```"+`"}`, string(body))
+			},
+		},
+		{
+			name:   "matrix",
+			sink:   func(url string) Sink { return NewMatrix(url, "mx-token", "!room:example.org") },
+			status: http.StatusOK,
+			verifyFn: func(t *testing.T, r *http.Request, body []byte) {
+				assert.Equal(t, http.MethodPut, r.Method)
+				assert.True(t, strings.HasPrefix(r.URL.Path, "/_matrix/client/v3/rooms/!room:example.org/send/m.room.message/goback-1772337900-"), r.URL.Path)
+				assert.Equal(t, "Bearer mx-token", r.Header.Get("Authorization"))
+				assert.JSONEq(t, `{"msgtype": "m.text", "body": "goback backup failed\n\nRun 1 failure"}`, string(body))
+			},
+		},
+		{
+			name:    "error status",
+			sink:    func(url string) Sink { return NewWebhook(url, nil) },
+			status:  http.StatusBadGateway,
+			wantErr: "unexpected status code: 502: upstream down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				if tt.verifyFn != nil {
+					tt.verifyFn(t, r, body)
+				}
+				w.WriteHeader(tt.status)
+				if tt.status >= 300 {
+					w.Write([]byte("upstream down\n"))
+				}
+			}))
+			defer ts.Close()
+
+			err := tt.sink(ts.URL).Send(context.Background(), msg)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDiscord_Truncates(t *testing.T) {
+	var content string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		content = payload["content"]
+	}))
+	defer ts.Close()
+
+	err := NewDiscord(ts.URL).Send(context.Background(), Message{Title: "t", Body: strings.Repeat("é", 3000)})
+	require.NoError(t, err)
+	assert.Len(t, []rune(content), discordMaxContent)
+	assert.True(t, strings.HasSuffix(content, "…\n```"))
+}
+
+func TestSMTP_Send(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.SMTPConfig
+		sendErr  error
+		wantErr  string
+		wantAuth bool
+	}{
+		{
+			name:     "authenticated",
+			cfg:      config.SMTPConfig{Host: "smtp.example.com:587", Username: "u", Password: "p", From: "goback@example.com", To: []string{"a@example.com", "b@example.com"}},
+			wantAuth: true,
+		},
+		{
+			name: "unauthenticated relay",
+			cfg:  config.SMTPConfig{Host: "relay:25", From: "goback@example.com", To: []string{"a@example.com"}},
+		},
+		{
+			name:    "send failure",
+			cfg:     config.SMTPConfig{Host: "relay:25", From: "goback@example.com", To: []string{"a@example.com"}},
+			sendErr: errors.New("550 mailbox unavailable"),
+			wantErr: "failed to send email: 550 mailbox unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotAddr, gotFrom string
+			var gotTo []string
+			var gotMsg []byte
+			var gotAuth smtp.Auth
+
+			s := NewSMTP(tt.cfg)
+			s.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+				gotAddr, gotAuth, gotFrom, gotTo, gotMsg = addr, a, from, to, msg
+				return tt.sendErr
+			}
+
+			err := s.Send(context.Background(), Message{Title: "goback backup failed", Body: "line 1\nline 2"})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.cfg.Host, gotAddr)
+			assert.Equal(t, tt.cfg.From, gotFrom)
+			assert.Equal(t, tt.cfg.To, gotTo)
+			assert.Equal(t, tt.wantAuth, gotAuth != nil)
+			assert.Contains(t, string(gotMsg), "Subject: goback backup failed\r\n")
+			assert.Contains(t, string(gotMsg), "To: "+strings.Join(tt.cfg.To, ", ")+"\r\n")
+			assert.True(t, strings.HasSuffix(string(gotMsg), "\r\n\r\nline 1\r\nline 2"))
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/nomis52/goback/config"
+)
+
+// sendMailFunc matches smtp.SendMail so it can be replaced in tests.
+type sendMailFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+// SMTP emails the notification as plain text.
+// STARTTLS is used when the server supports it.
+type SMTP struct {
+	cfg      config.SMTPConfig
+	sendMail sendMailFunc
+}
+
+// NewSMTP creates an SMTP sink.
+func NewSMTP(cfg config.SMTPConfig) *SMTP {
+	return &SMTP{cfg: cfg, sendMail: smtp.SendMail}
+}
+
+// Send implements Sink.
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		host, _, err := net.SplitHostPort(s.cfg.Host)
+		if err != nil {
+			return fmt.Errorf("invalid smtp host %q: %w", s.cfg.Host, err)
+		}
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, host)
+	}
+
+	// smtp.SendMail doesn't take a context, so run it in the background and
+	// abandon it if the context expires first.
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.sendMail(s.cfg.Host, auth, s.cfg.From, s.cfg.To, s.buildMessage(msg))
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send email: %w", ctx.Err())
+	}
+}
+
+// buildMessage formats the RFC 5322 message.
+func (s *SMTP) buildMessage(msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + s.cfg.From + "\r\n")
+	b.WriteString("To: " + strings.Join(s.cfg.To, ", ") + "\r\n")
+	b.WriteString("Subject: " + msg.Title + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// defaultTemplate is the message body used when a sink doesn't configure one.
+const defaultTemplate = `Run {{.RunID}} {{.Outcome}}{{if .Recovered}} (recovered){{end}}
+Workflows: {{join .Workflows ", "}}
+Duration: {{.Duration}}
+{{- if .Error}}
+Error: {{.Error}}
+{{- end}}
+{{- if .Activities}}
+
+Activities:
+{{- range .Activities}}
+  - {{.Type}}: {{.State}}{{if gt .Attempts 1}} ({{.Attempts}} attempts){{end}}{{if .Error}}: {{.Error}}{{end}}
+{{- end}}
+{{- end}}
+`
+
+// templateFuncs are the functions available to body templates.
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// parseTemplate parses a sink's body template, falling back to the default template.
+func parseTemplate(name, text string) (*template.Template, error) {
+	if text == "" {
+		text = defaultTemplate
+	}
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// render executes the template against the event.
+func render(tmpl *template.Template, event Event) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"context"
+	"maps"
+	"net/http"
+)
+
+// Webhook POSTs the notification as JSON to an arbitrary URL.
+//
+// The payload is:
+//
+//	{"title": "...", "body": "...", "event": {...}}
+type Webhook struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// webhookPayload is the JSON body sent by Webhook.
+type webhookPayload struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	Event Event  `json:"event"`
+}
+
+// NewWebhook creates a Webhook sink. headers are added to every request.
+func NewWebhook(url string, headers map[string]string) *Webhook {
+	return &Webhook{url: url, headers: headers, client: &http.Client{}}
+}
+
+// Send implements Sink.
+func (w *Webhook) Send(ctx context.Context, msg Message) error {
+	return doJSON(ctx, w.client, http.MethodPost, w.url, maps.Clone(w.headers), webhookPayload{
+		Title: msg.Title,
+		Body:  msg.Body,
+		Event: msg.Event,
+	})
+}
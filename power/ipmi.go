@@ -0,0 +1,48 @@
+package power
+
+import (
+	"context"
+
+	"github.com/nomis52/goback/clients/ipmiclient"
+)
+
+// IPMI is a PowerController backed by ipmitool.
+type IPMI struct {
+	ctrl *ipmiclient.IPMIController
+}
+
+// NewIPMI creates a PowerController that uses the given IPMI controller.
+func NewIPMI(ctrl *ipmiclient.IPMIController) *IPMI {
+	return &IPMI{ctrl: ctrl}
+}
+
+// Status implements PowerController.
+func (p *IPMI) Status(_ context.Context) (PowerState, error) {
+	state, err := p.ctrl.Status()
+	if err != nil {
+		return PowerStateUnknown, err
+	}
+	switch state {
+	case ipmiclient.PowerStateOn:
+		return PowerStateOn, nil
+	case ipmiclient.PowerStateOff, ipmiclient.PowerStateSoftOff:
+		return PowerStateOff, nil
+	default:
+		return PowerStateUnknown, nil
+	}
+}
+
+// PowerOn implements PowerController.
+func (p *IPMI) PowerOn(_ context.Context) error {
+	return p.ctrl.PowerOn()
+}
+
+// PowerOff implements PowerController using "chassis power soft".
+func (p *IPMI) PowerOff(_ context.Context) error {
+	return p.ctrl.PowerOff()
+}
+
+// PowerOffHard implements PowerController using "chassis power off".
+func (p *IPMI) PowerOffHard(_ context.Context) error {
+	return p.ctrl.PowerOffHard()
+}
@@ -0,0 +1,84 @@
+// Package power provides a common interface for powering the PBS host on and off.
+//
+// Backends:
+//   - IPMI: chassis power commands via ipmitool
+//   - Redfish: ComputerSystem.Reset actions via the BMC's REST API
+//   - Wake-on-LAN: magic packet to power on, SSH shutdown to power off
+//   - Smart plug: HTTP on/off/status endpoints, SSH shutdown before the plug is switched off
+//
+// The backend is selected with pbs.power.type in the config; use New to build
+// the configured PowerController.
+package power
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"regexp"
+
+	"github.com/nomis52/goback/clients/ipmiclient"
+	"github.com/nomis52/goback/clients/redfishclient"
+	"github.com/nomis52/goback/config"
+)
+
+// ErrUnsupported is returned when a backend cannot perform an operation,
+// e.g. a hard power-off over Wake-on-LAN.
+var ErrUnsupported = errors.New("operation not supported by power backend")
+
+// PowerController controls the power state of a host.
+type PowerController interface {
+	// Status returns the current power state.
+	Status(ctx context.Context) (PowerState, error)
+	// PowerOn powers the host on.
+	PowerOn(ctx context.Context) error
+	// PowerOff requests a graceful shutdown.
+	PowerOff(ctx context.Context) error
+	// PowerOffHard cuts power immediately.
+	PowerOffHard(ctx context.Context) error
+}
+
+// New creates the PowerController selected by the PBS power config.
+func New(cfg config.PBSConfig, logger *slog.Logger) (PowerController, error) {
+	switch cfg.Power.Type {
+	case "", config.PowerTypeIPMI:
+		return NewIPMI(ipmiclient.NewIPMIController(
+			cfg.IPMI.Host,
+			ipmiclient.WithUsername(cfg.IPMI.Username),
+			ipmiclient.WithPassword(cfg.IPMI.Password),
+			ipmiclient.WithLogger(logger),
+		)), nil
+
+	case config.PowerTypeRedfish:
+		rf := cfg.Power.Redfish
+		client, err := redfishclient.New(rf.Host,
+			redfishclient.WithCredentials(rf.Username, rf.Password),
+			redfishclient.WithSystemID(rf.SystemID),
+			redfishclient.WithInsecureSkipVerify(rf.InsecureSkipVerify),
+			redfishclient.WithLogger(logger),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Redfish client: %w", err)
+		}
+		return NewRedfish(client), nil
+
+	case config.PowerTypeWOL:
+		mac, err := net.ParseMAC(cfg.Power.WOL.MAC)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Wake-on-LAN MAC address: %w", err)
+		}
+		return NewWakeOnLAN(mac, cfg.Power.WOL.Broadcast, NewSSHShutdown(cfg.Power.SSH, logger), logger), nil
+
+	case config.PowerTypeSmartPlug:
+		sp := cfg.Power.SmartPlug
+		pattern, err := regexp.Compile(sp.StatusOnPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid smart plug status pattern: %w", err)
+		}
+		return NewSmartPlug(sp.OnURL, sp.OffURL, sp.StatusURL, pattern, NewSSHShutdown(cfg.Power.SSH, logger), logger), nil
+
+	default:
+		return nil, fmt.Errorf("unknown power type %q", cfg.Power.Type)
+	}
+}
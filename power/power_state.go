@@ -0,0 +1,21 @@
+package power
+
+// PowerState represents the power state of a host.
+type PowerState int
+
+const (
+	PowerStateUnknown PowerState = iota
+	PowerStateOn
+	PowerStateOff
+)
+
+func (p PowerState) String() string {
+	switch p {
+	case PowerStateOn:
+		return "on"
+	case PowerStateOff:
+		return "off"
+	default:
+		return "unknown"
+	}
+}
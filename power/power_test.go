@@ -0,0 +1,336 @@
+package power
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nomis52/goback/clients/redfishclient"
+	"github.com/nomis52/goback/config"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.PBSConfig
+		wantErr  string
+		wantType any
+	}{
+		{
+			name:     "default is ipmi",
+			cfg:      config.PBSConfig{IPMI: config.IPMIConfig{Host: "bmc"}},
+			wantType: &IPMI{},
+		},
+		{
+			name: "redfish",
+			cfg: config.PBSConfig{Power: config.PowerConfig{
+				Type:    config.PowerTypeRedfish,
+				Redfish: config.RedfishConfig{Host: "https://bmc.example.com"},
+			}},
+			wantType: &Redfish{},
+		},
+		{
+			name: "wol",
+			cfg: config.PBSConfig{Power: config.PowerConfig{
+				Type: config.PowerTypeWOL,
+				WOL:  config.WOLConfig{MAC: "aa:bb:cc:dd:ee:ff", Broadcast: "255.255.255.255:9"},
+				SSH:  config.SSHShutdownConfig{Host: "pbs"},
+			}},
+			wantType: &WakeOnLAN{},
+		},
+		{
+			name: "wol invalid mac",
+			cfg: config.PBSConfig{Power: config.PowerConfig{
+				Type: config.PowerTypeWOL,
+				WOL:  config.WOLConfig{MAC: "nope"},
+			}},
+			wantErr: "invalid Wake-on-LAN MAC address",
+		},
+		{
+			name: "smartplug",
+			cfg: config.PBSConfig{Power: config.PowerConfig{
+				Type: config.PowerTypeSmartPlug,
+				SmartPlug: config.SmartPlugConfig{
+					OnURL:           "http://plug/on",
+					OffURL:          "http://plug/off",
+					StatusURL:       "http://plug/status",
+					StatusOnPattern: "on",
+				},
+			}},
+			wantType: &SmartPlug{},
+		},
+		{
+			name:    "unknown type",
+			cfg:     config.PBSConfig{Power: config.PowerConfig{Type: "carrier-pigeon"}},
+			wantErr: `unknown power type "carrier-pigeon"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl, err := New(tt.cfg, slog.Default())
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.wantType, ctrl)
+		})
+	}
+}
+
+func TestRedfish(t *testing.T) {
+	var resets []redfishclient.ResetType
+	powerState := "Off"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/Systems/1":
+			w.Write([]byte(`{"Id": "1", "PowerState": "` + powerState + `"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/redfish/v1/Systems/1/Actions/ComputerSystem.Reset":
+			var req struct {
+				ResetType redfishclient.ResetType
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			resets = append(resets, req.ResetType)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := redfishclient.New(ts.URL, redfishclient.WithSystemID("1"))
+	require.NoError(t, err)
+	ctrl := NewRedfish(client)
+	ctx := context.Background()
+
+	state, err := ctrl.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, PowerStateOff, state)
+
+	powerState = "PoweringOff"
+	state, err = ctrl.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, PowerStateOn, state)
+
+	require.NoError(t, ctrl.PowerOn(ctx))
+	require.NoError(t, ctrl.PowerOff(ctx))
+	require.NoError(t, ctrl.PowerOffHard(ctx))
+	assert.Equal(t, []redfishclient.ResetType{
+		redfishclient.ResetOn,
+		redfishclient.ResetGracefulShutdown,
+		redfishclient.ResetForceOff,
+	}, resets)
+}
+
+func TestSmartPlug(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusBody string
+		status     int
+		want       PowerState
+		wantErr    string
+	}{
+		{
+			name:       "shelly on",
+			statusBody: `{"ison": true, "source": "http"}`,
+			status:     http.StatusOK,
+			want:       PowerStateOn,
+		},
+		{
+			name:       "tasmota off",
+			statusBody: `{"POWER": "OFF"}`,
+			status:     http.StatusOK,
+			want:       PowerStateOff,
+		},
+		{
+			name:    "http error",
+			status:  http.StatusInternalServerError,
+			wantErr: "unexpected status code: 500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/status", r.URL.Path)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.statusBody))
+			}))
+			defer ts.Close()
+
+			pattern := regexp.MustCompile(`(?i)"(ison|power|output)"\s*:\s*(true|"on")`)
+			ctrl := NewSmartPlug(ts.URL+"/on", ts.URL+"/off", ts.URL+"/status", pattern, nil, slog.Default())
+
+			state, err := ctrl.Status(context.Background())
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, state)
+		})
+	}
+}
+
+func TestSmartPlugSwitching(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	ctrl := NewSmartPlug(ts.URL+"/on", ts.URL+"/off", ts.URL+"/status", regexp.MustCompile("on"), nil, slog.Default())
+	ctx := context.Background()
+
+	require.NoError(t, ctrl.PowerOn(ctx))
+	require.NoError(t, ctrl.PowerOffHard(ctx))
+	assert.Equal(t, []string{"/on", "/off"}, paths)
+}
+
+func TestSmartPlugStatus_ReadOnly(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte("on"))
+	}))
+	defer ts.Close()
+
+	// A host that is halting or still booting doesn't respond on its SSH port, but the plug is on
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	shutdown := NewSSHShutdown(config.SSHShutdownConfig{Host: addr}, slog.Default())
+	ctrl := NewSmartPlug(ts.URL+"/on", ts.URL+"/off", ts.URL+"/status", regexp.MustCompile("on"), shutdown, slog.Default())
+	ctrl.haltGrace = 0
+
+	state, err := ctrl.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, PowerStateOn, state)
+	assert.Equal(t, []string{"/status"}, paths, "Status should not switch the plug")
+}
+
+func TestSmartPlugWaitForHalt(t *testing.T) {
+	tests := []struct {
+		name      string
+		reachable bool
+		wantErr   bool
+	}{
+		{
+			name: "host stopped responding",
+		},
+		{
+			name:      "host still responding",
+			reachable: true,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			addr := ln.Addr().String()
+			if tt.reachable {
+				defer ln.Close()
+				go acceptAndClose(ln)
+			} else {
+				ln.Close()
+			}
+
+			shutdown := NewSSHShutdown(config.SSHShutdownConfig{Host: addr}, slog.Default())
+			ctrl := NewSmartPlug("", "", "", regexp.MustCompile("on"), shutdown, slog.Default())
+			ctrl.haltGrace = 0
+			ctrl.pollInterval = 10 * time.Millisecond
+
+			// Cancel rather than use a deadline, which also times out the dial and can
+			// make the host look unreachable
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			stop := time.AfterFunc(50*time.Millisecond, cancel)
+			defer stop.Stop()
+
+			err = ctrl.waitForHalt(ctx)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, context.Canceled)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// acceptAndClose accepts and closes connections until ln is closed.
+func acceptAndClose(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		conn.Close()
+	}
+}
+
+func TestSSHShutdown_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	shutdown := NewSSHShutdown(config.SSHShutdownConfig{Host: "127.0.0.1:1"}, slog.Default())
+	assert.ErrorIs(t, shutdown.Shutdown(ctx), context.Canceled)
+}
+
+func TestWakeOnLAN(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	mac, err := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	require.NoError(t, err)
+	ctrl := NewWakeOnLAN(mac, conn.LocalAddr().String(), nil, slog.Default())
+
+	require.NoError(t, ctrl.PowerOn(context.Background()))
+
+	buf := make([]byte, 256)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	require.Equal(t, 102, n)
+	assert.Equal(t, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, buf[:6])
+	for i := 0; i < 16; i++ {
+		assert.Equal(t, []byte(mac), buf[6+i*6:12+i*6])
+	}
+
+	assert.ErrorIs(t, ctrl.PowerOffHard(context.Background()), ErrUnsupported)
+}
+
+func TestWakeOnLANStatus(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+
+	shutdown := NewSSHShutdown(config.SSHShutdownConfig{Host: addr}, slog.Default())
+	ctrl := NewWakeOnLAN(nil, "", shutdown, slog.Default())
+
+	state, err := ctrl.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, PowerStateOn, state)
+
+	ln.Close()
+	state, err = ctrl.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, PowerStateOff, state)
+}
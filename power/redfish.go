@@ -0,0 +1,49 @@
+package power
+
+import (
+	"context"
+
+	"github.com/nomis52/goback/clients/redfishclient"
+)
+
+// Redfish is a PowerController backed by a BMC's Redfish API.
+type Redfish struct {
+	client *redfishclient.Client
+}
+
+// NewRedfish creates a PowerController that uses the given Redfish client.
+func NewRedfish(client *redfishclient.Client) *Redfish {
+	return &Redfish{client: client}
+}
+
+// Status implements PowerController.
+// A system that is powering off is still reported as on until it is fully off.
+func (p *Redfish) Status(ctx context.Context) (PowerState, error) {
+	state, err := p.client.PowerState(ctx)
+	if err != nil {
+		return PowerStateUnknown, err
+	}
+	switch state {
+	case redfishclient.PowerStateOn, redfishclient.PowerStatePoweringOn, redfishclient.PowerStatePoweringOff:
+		return PowerStateOn, nil
+	case redfishclient.PowerStateOff:
+		return PowerStateOff, nil
+	default:
+		return PowerStateUnknown, nil
+	}
+}
+
+// PowerOn implements PowerController.
+func (p *Redfish) PowerOn(ctx context.Context) error {
+	return p.client.Reset(ctx, redfishclient.ResetOn)
+}
+
+// PowerOff implements PowerController.
+func (p *Redfish) PowerOff(ctx context.Context) error {
+	return p.client.Reset(ctx, redfishclient.ResetGracefulShutdown)
+}
+
+// PowerOffHard implements PowerController.
+func (p *Redfish) PowerOffHard(ctx context.Context) error {
+	return p.client.Reset(ctx, redfishclient.ResetForceOff)
+}
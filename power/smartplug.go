@@ -0,0 +1,143 @@
+package power
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const (
+	smartPlugTimeout = 10 * time.Second
+	// haltGrace is how long the host must stop responding after a shutdown before the plug
+	// is switched off, so it can finish halting once sshd has stopped.
+	haltGrace = 15 * time.Second
+	// haltPollInterval is how often the host is checked while waiting for it to halt.
+	haltPollInterval = time.Second
+)
+
+// SmartPlug is a PowerController backed by an HTTP-controlled smart plug (e.g. Shelly or Tasmota).
+// Switching the plug off cuts power, so a graceful shutdown runs the shutdown command over SSH
+// and the plug is switched off once the host stops responding.
+type SmartPlug struct {
+	onURL        string
+	offURL       string
+	statusURL    string
+	onPattern    *regexp.Regexp
+	shutdown     *SSHShutdown
+	haltGrace    time.Duration
+	pollInterval time.Duration
+	client       *http.Client
+	logger       *slog.Logger
+}
+
+// NewSmartPlug creates a smart plug PowerController.
+// The status URL's response body is matched against onPattern to decide if the plug is on.
+func NewSmartPlug(onURL, offURL, statusURL string, onPattern *regexp.Regexp, shutdown *SSHShutdown, logger *slog.Logger) *SmartPlug {
+	return &SmartPlug{
+		onURL:        onURL,
+		offURL:       offURL,
+		statusURL:    statusURL,
+		onPattern:    onPattern,
+		shutdown:     shutdown,
+		haltGrace:    haltGrace,
+		pollInterval: haltPollInterval,
+		client:       &http.Client{Timeout: smartPlugTimeout},
+		logger:       logger,
+	}
+}
+
+// Status implements PowerController by reporting the state of the plug's relay.
+func (p *SmartPlug) Status(ctx context.Context) (PowerState, error) {
+	body, err := p.get(ctx, p.statusURL)
+	if err != nil {
+		return PowerStateUnknown, err
+	}
+	if p.onPattern.Match(body) {
+		return PowerStateOn, nil
+	}
+	return PowerStateOff, nil
+}
+
+// PowerOn implements PowerController.
+func (p *SmartPlug) PowerOn(ctx context.Context) error {
+	_, err := p.get(ctx, p.onURL)
+	return err
+}
+
+// PowerOff implements PowerController by running the shutdown command over SSH and
+// switching the plug off once the host has stopped responding for haltGrace.
+// Returns an error without switching the plug off if ctx is done first.
+func (p *SmartPlug) PowerOff(ctx context.Context) error {
+	if err := p.shutdown.Shutdown(ctx); err != nil {
+		return err
+	}
+	if err := p.waitForHalt(ctx); err != nil {
+		return err
+	}
+	p.logger.Info("host stopped responding after shutdown, switching plug off")
+	return p.PowerOffHard(ctx)
+}
+
+// PowerOffHard implements PowerController by switching the plug off.
+func (p *SmartPlug) PowerOffHard(ctx context.Context) error {
+	_, err := p.get(ctx, p.offURL)
+	return err
+}
+
+// waitForHalt polls the host's SSH port until it has been unreachable for haltGrace.
+func (p *SmartPlug) waitForHalt(ctx context.Context) error {
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	var downSince time.Time
+	for {
+		// Check ctx after dialing, as a dial that was cancelled doesn't mean the host is down
+		reachable := p.shutdown.Reachable(ctx)
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("cancelled waiting for host to halt: %w", err)
+		}
+		if reachable {
+			downSince = time.Time{}
+		} else {
+			if downSince.IsZero() {
+				downSince = time.Now()
+			}
+			if time.Since(downSince) >= p.haltGrace {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+// get performs a GET request and returns the body, failing on any non-200 response.
+func (p *SmartPlug) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	p.logger.Debug("smart plug request", "url", url)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("smart plug request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return body, nil
+}
@@ -0,0 +1,92 @@
+package power
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/nomis52/goback/clients/sshclient"
+	"github.com/nomis52/goback/config"
+)
+
+const (
+	defaultSSHPort = "22"
+	reachTimeout   = 3 * time.Second
+)
+
+// SSHShutdown shuts a host down by running a command over SSH.
+// It is used by backends that can't request a graceful shutdown themselves.
+type SSHShutdown struct {
+	host           string
+	user           string
+	privateKeyPath string
+	command        string
+	logger         *slog.Logger
+}
+
+// NewSSHShutdown creates an SSHShutdown from the power SSH config.
+func NewSSHShutdown(cfg config.SSHShutdownConfig, logger *slog.Logger) *SSHShutdown {
+	host := cfg.Host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, defaultSSHPort)
+	}
+	return &SSHShutdown{
+		host:           host,
+		user:           cfg.User,
+		privateKeyPath: cfg.PrivateKeyPath,
+		command:        cfg.Command,
+		logger:         logger,
+	}
+}
+
+// Shutdown runs the shutdown command on the host.
+// The connection usually drops before the command exits, which is treated as success.
+// If ctx is done while the command runs, the connection is closed and ctx's error returned.
+func (s *SSHShutdown) Shutdown(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("shutdown cancelled: %w", err)
+	}
+
+	privateKeyPEM, err := os.ReadFile(s.privateKeyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read private key file %s: %w", s.privateKeyPath, err)
+	}
+
+	client, err := sshclient.New(s.host, s.user, string(privateKeyPEM))
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", s.host, err)
+	}
+	defer client.Close()
+
+	// Closing the connection makes Run return, so a cancelled shutdown doesn't block
+	stop := context.AfterFunc(ctx, func() { client.Close() })
+	defer stop()
+
+	s.logger.Debug("running shutdown command", "host", s.host, "command", s.command)
+	_, stderr, err := client.Run(s.command)
+	if ctx.Err() != nil {
+		return fmt.Errorf("shutdown cancelled: %w", ctx.Err())
+	}
+	var exitMissing *ssh.ExitMissingError
+	if err != nil && !errors.As(err, &exitMissing) {
+		return fmt.Errorf("shutdown command failed: %w (stderr: %s)", err, stderr)
+	}
+	return nil
+}
+
+// Reachable reports whether the host accepts TCP connections on its SSH port.
+func (s *SSHShutdown) Reachable(ctx context.Context) bool {
+	dialer := net.Dialer{Timeout: reachTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.host)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
@@ -0,0 +1,73 @@
+package power
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+)
+
+// WakeOnLAN is a PowerController for hosts without a BMC.
+// It powers on by sending a magic packet and powers off by running a shutdown command over SSH.
+// The power state is inferred from whether the host's SSH port is reachable.
+type WakeOnLAN struct {
+	mac       net.HardwareAddr
+	broadcast string
+	shutdown  *SSHShutdown
+	logger    *slog.Logger
+}
+
+// NewWakeOnLAN creates a Wake-on-LAN PowerController.
+// broadcast is the UDP address the magic packet is sent to, e.g. "255.255.255.255:9".
+func NewWakeOnLAN(mac net.HardwareAddr, broadcast string, shutdown *SSHShutdown, logger *slog.Logger) *WakeOnLAN {
+	return &WakeOnLAN{
+		mac:       mac,
+		broadcast: broadcast,
+		shutdown:  shutdown,
+		logger:    logger,
+	}
+}
+
+// Status implements PowerController.
+func (p *WakeOnLAN) Status(ctx context.Context) (PowerState, error) {
+	if p.shutdown.Reachable(ctx) {
+		return PowerStateOn, nil
+	}
+	return PowerStateOff, nil
+}
+
+// PowerOn implements PowerController by sending a magic packet.
+func (p *WakeOnLAN) PowerOn(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", p.broadcast)
+	if err != nil {
+		return fmt.Errorf("failed to open UDP socket to %s: %w", p.broadcast, err)
+	}
+	defer conn.Close()
+
+	p.logger.Debug("sending Wake-on-LAN packet", "mac", p.mac.String(), "broadcast", p.broadcast)
+	if _, err := conn.Write(magicPacket(p.mac)); err != nil {
+		return fmt.Errorf("failed to send Wake-on-LAN packet: %w", err)
+	}
+	return nil
+}
+
+// PowerOff implements PowerController by running the shutdown command over SSH.
+func (p *WakeOnLAN) PowerOff(ctx context.Context) error {
+	return p.shutdown.Shutdown(ctx)
+}
+
+// PowerOffHard is not supported by Wake-on-LAN.
+func (p *WakeOnLAN) PowerOffHard(_ context.Context) error {
+	return ErrUnsupported
+}
+
+// magicPacket builds a Wake-on-LAN packet: 6 bytes of 0xFF followed by the MAC repeated 16 times.
+func magicPacket(mac net.HardwareAddr) []byte {
+	packet := bytes.Repeat([]byte{0xFF}, 6)
+	for i := 0; i < 16; i++ {
+		packet = append(packet, mac...)
+	}
+	return packet
+}
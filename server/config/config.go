@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -13,11 +14,35 @@ type ServerConfig struct {
 	Cron     []CronTrigger  `yaml:"cron"`
 	// The path to the directory used to store the workflow history
 	StateDir string `yaml:"state_dir"`
-	LogLevel string `yaml:"log_level"`
+	// How the history is stored in StateDir, either "json" (the default) or "db"
+	HistoryStore string `yaml:"history_store"`
+	// The number of runs kept in the history, defaults to 100 for "json" and 5000 for "db"
+	HistorySize int    `yaml:"history_size"`
+	LogLevel    string `yaml:"log_level"`
 	// The path to the workflow config file
 	WorkflowConfig string `yaml:"workflow_config"`
+	// How long backups in progress when a run's backup window closes may continue
+	// before the run is cancelled, defaults to 15m
+	DeadlineGrace time.Duration `yaml:"deadline_grace"`
 }
 
+// defaultDeadlineGrace is how long backups may overrun a run's deadline by default.
+const defaultDeadlineGrace = 15 * time.Minute
+
+const (
+	// HistoryStoreDB stores the history in an embedded database, history.db.
+	HistoryStoreDB = "db"
+	// HistoryStoreJSON stores each run in its own JSON file.
+	HistoryStoreJSON = "json"
+)
+
+const (
+	// defaultJSONHistorySize is the number of runs kept as JSON files by default.
+	defaultJSONHistorySize = 100
+	// defaultDBHistorySize is the number of runs kept in the history database by default.
+	defaultDBHistorySize = 5000
+)
+
 // ListenerConfig holds HTTP server listener settings.
 type ListenerConfig struct {
 	// The listen address, defaults to :8080
@@ -34,6 +59,11 @@ type CronTrigger struct {
 	Workflows []string `yaml:"workflows"`
 	// The cron spec to execute the workflows at
 	Schedule string `yaml:"schedule"`
+	// The time of day, e.g. "07:00", that the run's backup window closes. Optional.
+	Deadline string `yaml:"deadline"`
+	// The length of the run's backup window, e.g. "4h". Optional, and may not be
+	// combined with Deadline.
+	Window time.Duration `yaml:"window"`
 }
 
 // LoadConfig reads the YAML config file at the given path and returns a ServerConfig struct.
@@ -63,4 +93,16 @@ func (c *ServerConfig) SetDefaults() {
 	if c.Listener.Addr == "" {
 		c.Listener.Addr = ":8080"
 	}
+	if c.HistoryStore == "" {
+		c.HistoryStore = HistoryStoreJSON
+	}
+	if c.HistorySize == 0 {
+		c.HistorySize = defaultJSONHistorySize
+		if c.HistoryStore == HistoryStoreDB {
+			c.HistorySize = defaultDBHistorySize
+		}
+	}
+	if c.DeadlineGrace == 0 {
+		c.DeadlineGrace = defaultDeadlineGrace
+	}
 }
@@ -0,0 +1,45 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nomis52/goback/server/config"
+)
+
+// deadlineFunc returns the deadline of a run started at start, or the zero time if the
+// run has no deadline.
+type deadlineFunc func(start time.Time) time.Time
+
+// newDeadlineFunc returns the deadlineFunc for a trigger's deadline or window.
+// A deadline is a time of day in the local timezone, and a run's deadline is the next
+// time it occurs after the run starts.
+func newDeadlineFunc(cfg config.CronTrigger) (deadlineFunc, error) {
+	switch {
+	case cfg.Deadline != "" && cfg.Window != 0:
+		return nil, errors.New("deadline and window cannot both be set")
+	case cfg.Deadline != "":
+		timeOfDay, err := time.Parse("15:04", cfg.Deadline)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deadline %q, expected HH:MM: %w", cfg.Deadline, err)
+		}
+		return func(start time.Time) time.Time {
+			deadline := time.Date(start.Year(), start.Month(), start.Day(), timeOfDay.Hour(), timeOfDay.Minute(), 0, 0, start.Location())
+			if !deadline.After(start) {
+				deadline = deadline.AddDate(0, 0, 1)
+			}
+			return deadline
+		}, nil
+	case cfg.Window < 0:
+		return nil, fmt.Errorf("window must be positive, got %s", cfg.Window)
+	case cfg.Window > 0:
+		return func(start time.Time) time.Time {
+			return start.Add(cfg.Window)
+		}, nil
+	default:
+		return func(time.Time) time.Time {
+			return time.Time{}
+		}, nil
+	}
+}
@@ -0,0 +1,74 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	serverconfig "github.com/nomis52/goback/server/config"
+)
+
+func TestNewDeadlineFunc(t *testing.T) {
+	start := time.Date(2025, 1, 2, 2, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		trigger serverconfig.CronTrigger
+		want    time.Time
+		wantErr string
+	}{
+		{
+			name: "no deadline",
+		},
+		{
+			name:    "window",
+			trigger: serverconfig.CronTrigger{Window: 4 * time.Hour},
+			want:    time.Date(2025, 1, 2, 6, 0, 0, 0, time.Local),
+		},
+		{
+			name:    "deadline later today",
+			trigger: serverconfig.CronTrigger{Deadline: "07:30"},
+			want:    time.Date(2025, 1, 2, 7, 30, 0, 0, time.Local),
+		},
+		{
+			name:    "deadline tomorrow",
+			trigger: serverconfig.CronTrigger{Deadline: "01:00"},
+			want:    time.Date(2025, 1, 3, 1, 0, 0, 0, time.Local),
+		},
+		{
+			name:    "deadline at the start time is tomorrow",
+			trigger: serverconfig.CronTrigger{Deadline: "02:00"},
+			want:    time.Date(2025, 1, 3, 2, 0, 0, 0, time.Local),
+		},
+		{
+			name:    "invalid deadline",
+			trigger: serverconfig.CronTrigger{Deadline: "7am"},
+			wantErr: "invalid deadline",
+		},
+		{
+			name:    "negative window",
+			trigger: serverconfig.CronTrigger{Window: -time.Hour},
+			wantErr: "window must be positive",
+		},
+		{
+			name:    "deadline and window",
+			trigger: serverconfig.CronTrigger{Deadline: "07:00", Window: time.Hour},
+			wantErr: "cannot both be set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deadline, err := newDeadlineFunc(tt.trigger)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, deadline(start))
+		})
+	}
+}
@@ -11,7 +11,9 @@ import (
 
 // Runnable is implemented by anything that can be triggered by the cron scheduler.
 type Runnable interface {
-	Run(workflows []string) error
+	// RunUntil starts a run that must finish by deadline. A zero deadline means the run
+	// has no deadline.
+	RunUntil(workflows []string, deadline time.Time) error
 }
 
 // CronTriggerManager manages multiple CronTrigger instances with different workflows and schedules.
@@ -33,12 +35,17 @@ func NewCronTriggerManager(triggers []config.CronTrigger, runnable Runnable, log
 			return nil, fmt.Errorf("trigger %d: no workflows specified", i)
 		}
 
-		// Create a closure that captures the workflows and runnable
+		deadline, err := newDeadlineFunc(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("trigger %d: %w", i, err)
+		}
+
+		// Create a closure that captures the workflows, deadline and runnable
 		workflowsCopy := make([]string, len(cfg.Workflows))
 		copy(workflowsCopy, cfg.Workflows)
 
 		callback := func() error {
-			return runnable.Run(workflowsCopy)
+			return runnable.RunUntil(workflowsCopy, deadline(time.Now()))
 		}
 
 		trigger, err := NewCronTrigger(cfg.Schedule, callback, logger)
@@ -56,6 +63,8 @@ func NewCronTriggerManager(triggers []config.CronTrigger, runnable Runnable, log
 			"index", i,
 			"workflows", workflows[i],
 			"schedule", triggers[i].Schedule,
+			"deadline", triggers[i].Deadline,
+			"window", triggers[i].Window,
 			"next_run", trigger.NextRun(),
 		)
 	}
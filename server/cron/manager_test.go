@@ -80,6 +80,17 @@ func TestNewCronTriggerManager_InvalidConfig(t *testing.T) {
 			},
 			wantErr: "creating trigger",
 		},
+		{
+			name: "invalid deadline",
+			triggers: []serverconfig.CronTrigger{
+				{
+					Workflows: []string{"backup"},
+					Schedule:  "0 2 * * *",
+					Deadline:  "25:00",
+				},
+			},
+			wantErr: "invalid deadline",
+		},
 	}
 
 	for _, tt := range tests {
@@ -92,6 +103,28 @@ func TestNewCronTriggerManager_InvalidConfig(t *testing.T) {
 	}
 }
 
+func TestNewCronTriggerManager_Window(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	runnable := &mockRunnable{}
+
+	triggers := []serverconfig.CronTrigger{
+		{
+			Workflows: []string{"backup_poweroff"},
+			Schedule:  "0 2 * * *",
+			Window:    4 * time.Hour,
+		},
+	}
+
+	manager, err := NewCronTriggerManager(triggers, runnable, logger)
+	require.NoError(t, err)
+
+	before := time.Now()
+	require.NoError(t, manager.triggers[0].callback())
+
+	assert.Equal(t, []string{"backup_poweroff"}, runnable.workflows)
+	assert.WithinRange(t, runnable.deadline, before.Add(4*time.Hour), time.Now().Add(4*time.Hour))
+}
+
 func TestCronTriggerManager_NextRun_SingleTrigger(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 	runnable := &mockRunnable{}
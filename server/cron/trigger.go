@@ -8,7 +8,7 @@
 // Example usage:
 //
 //	callback := func() error {
-//	    return runner.Run([]string{"backup", "poweroff"})
+//	    return runner.RunUntil([]string{"backup", "poweroff"}, time.Time{})
 //	}
 //	trigger, err := cron.NewCronTrigger("0 2 * * *", callback, logger)
 //	if err != nil {
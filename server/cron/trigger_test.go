@@ -17,11 +17,13 @@ type mockRunnable struct {
 	runCount  atomic.Int32
 	runErr    error
 	workflows []string
+	deadline  time.Time
 }
 
-func (m *mockRunnable) Run(workflows []string) error {
+func (m *mockRunnable) RunUntil(workflows []string, deadline time.Time) error {
 	m.runCount.Add(1)
 	m.workflows = workflows
+	m.deadline = deadline
 	return m.runErr
 }
 
@@ -74,7 +76,7 @@ func TestNewCronTrigger(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			callback := func() error {
-				return runnable.Run([]string{"backup", "poweroff"})
+				return runnable.RunUntil([]string{"backup", "poweroff"}, time.Time{})
 			}
 			trigger, err := NewCronTrigger(tt.spec, callback, logger)
 
@@ -95,7 +97,7 @@ func TestCronTrigger_NextRun(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 	runnable := &mockRunnable{}
 	callback := func() error {
-		return runnable.Run([]string{"backup", "poweroff"})
+		return runnable.RunUntil([]string{"backup", "poweroff"}, time.Time{})
 	}
 
 	trigger, err := NewCronTrigger("0 2 * * *", callback, logger)
@@ -111,7 +113,7 @@ func TestCronTrigger_Start_CancellationStopsLoop(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 	runnable := &mockRunnable{}
 	callback := func() error {
-		return runnable.Run([]string{"test"})
+		return runnable.RunUntil([]string{"test"}, time.Time{})
 	}
 
 	// Use a spec that would run every minute
@@ -0,0 +1,129 @@
+// Package events provides a publish/subscribe broker for live run progress.
+//
+// The runner publishes events as a run progresses and the /api/events handler
+// streams them to clients using server-sent events.
+//
+// Event types:
+//   - run_started / run_finished: the run summary
+//   - activity_state: an activity's state transition (pending, running, completed, skipped)
+//   - activity_status: a StatusLine update
+//   - log: a captured log entry
+//
+// Subscribers that fall behind have events dropped rather than blocking publishers.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultBufferSize is the number of events buffered per subscriber.
+const defaultBufferSize = 256
+
+// Type identifies the kind of event.
+type Type string
+
+const (
+	TypeRunStarted     Type = "run_started"
+	TypeRunFinished    Type = "run_finished"
+	TypeActivityState  Type = "activity_state"
+	TypeActivityStatus Type = "activity_status"
+	TypeLog            Type = "log"
+)
+
+// Event is a single progress update.
+type Event struct {
+	// ID is assigned by the broker and increases monotonically.
+	ID    uint64    `json:"id"`
+	Type  Type      `json:"type"`
+	RunID string    `json:"run_id,omitempty"`
+	Time  time.Time `json:"time"`
+	Data  any       `json:"data"`
+}
+
+// ActivityState is the Data of a TypeActivityState event.
+type ActivityState struct {
+	Module    string     `json:"module"`
+	Type      string     `json:"type"`
+	State     string     `json:"state"`
+	Error     string     `json:"error,omitempty"`
+	TimedOut  bool       `json:"timed_out,omitempty"`
+	Attempts  int        `json:"attempts,omitempty"`
+	StartTime *time.Time `json:"start_time,omitempty"`
+	EndTime   *time.Time `json:"end_time,omitempty"`
+}
+
+// ActivityStatus is the Data of a TypeActivityStatus event.
+type ActivityStatus struct {
+	Module string `json:"module"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// Log is the Data of a TypeLog event.
+type Log struct {
+	Activity string    `json:"activity"`
+	Time     time.Time `json:"time"`
+	Level    string    `json:"level"`
+	Message  string    `json:"message"`
+	// Attributes holds the structured fields of the log record
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// Broker fans out published events to all current subscribers.
+type Broker struct {
+	bufferSize int
+
+	mu          sync.Mutex
+	nextID      uint64
+	subscribers map[chan Event]struct{}
+}
+
+// NewBroker creates a new Broker.
+func NewBroker() *Broker {
+	return &Broker{
+		bufferSize:  defaultBufferSize,
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Publish assigns the event an ID and timestamp, if unset, and delivers it to every subscriber.
+// It never blocks: subscribers whose buffer is full miss the event.
+func (b *Broker) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event.ID = b.nextID
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving all events published from now on, and a function
+// that must be called to unsubscribe. The channel is closed on unsubscribe.
+func (b *Broker) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, b.bufferSize)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
@@ -0,0 +1,54 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroker_PublishSubscribe(t *testing.T) {
+	b := NewBroker()
+
+	ch1, unsub1 := b.Subscribe()
+	ch2, unsub2 := b.Subscribe()
+	defer unsub2()
+
+	b.Publish(Event{Type: TypeRunStarted, RunID: "1"})
+	b.Publish(Event{Type: TypeLog, RunID: "1"})
+
+	for _, ch := range []<-chan Event{ch1, ch2} {
+		first := <-ch
+		second := <-ch
+		assert.Equal(t, TypeRunStarted, first.Type)
+		assert.Equal(t, uint64(1), first.ID)
+		assert.False(t, first.Time.IsZero())
+		assert.Equal(t, TypeLog, second.Type)
+		assert.Equal(t, uint64(2), second.ID)
+	}
+
+	unsub1()
+	unsub1() // unsubscribing twice is a no-op
+	_, ok := <-ch1
+	assert.False(t, ok, "channel should be closed after unsubscribe")
+
+	b.Publish(Event{Type: TypeRunFinished})
+	event := <-ch2
+	assert.Equal(t, TypeRunFinished, event.Type)
+}
+
+func TestBroker_SlowSubscriberDoesNotBlock(t *testing.T) {
+	b := NewBroker()
+	b.bufferSize = 2
+
+	ch, unsub := b.Subscribe()
+	defer unsub()
+
+	for i := 0; i < 5; i++ {
+		b.Publish(Event{Type: TypeLog})
+	}
+
+	require.Len(t, ch, 2)
+	assert.Equal(t, uint64(1), (<-ch).ID)
+	assert.Equal(t, uint64(2), (<-ch).ID)
+}
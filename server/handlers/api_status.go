@@ -5,7 +5,7 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/nomis52/goback/clients/ipmiclient"
+	"github.com/nomis52/goback/power"
 	"github.com/nomis52/goback/server/cron"
 	"github.com/nomis52/goback/server/runner"
 	"github.com/nomis52/goback/server/types"
@@ -39,7 +39,7 @@ type APIStatusResponse struct {
 
 // APIStatusProvider aggregates all the providers needed for the status endpoint.
 type APIStatusProvider interface {
-	IPMIController() *ipmiclient.IPMIController
+	PowerController() power.PowerController
 	Status() (runner.RunSummary, []runner.ActivityExecution)
 	NextTrigger() *cron.NextTriggerInfo
 	Properties() types.ServerProperties
@@ -63,11 +63,11 @@ func NewAPIStatusHandler(logger *slog.Logger, provider APIStatusProvider) *APISt
 func (h *APIStatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Get PBS power state
 	var powerStateStr string
-	ctrl := h.provider.IPMIController()
+	ctrl := h.provider.PowerController()
 	if ctrl != nil {
-		state, err := ctrl.Status()
+		state, err := ctrl.Status(r.Context())
 		if err != nil {
-			h.logger.Error("failed to get IPMI status", "error", err)
+			h.logger.Error("failed to get power status", "error", err)
 			powerStateStr = "unknown"
 		} else {
 			powerStateStr = state.String()
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/nomis52/goback/server/runner"
+)
+
+// CancelHandler handles requests to cancel an in-flight run.
+type CancelHandler struct {
+	canceller RunCanceller
+}
+
+// NewCancelHandler creates a new CancelHandler.
+func NewCancelHandler(c RunCanceller) *CancelHandler {
+	return &CancelHandler{
+		canceller: c,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+// The run ID is taken from the {id} path wildcard.
+func (h *CancelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: "missing run id",
+		})
+		return
+	}
+
+	err := h.canceller.Cancel(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, runner.ErrRunNotFound):
+			writeJSON(w, http.StatusNotFound, ErrorResponse{
+				Error: err.Error(),
+			})
+		case errors.Is(err, runner.ErrNoRunInProgress):
+			writeJSON(w, http.StatusConflict, ErrorResponse{
+				Error: err.Error(),
+			})
+		default:
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
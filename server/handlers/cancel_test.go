@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nomis52/goback/server/runner"
+)
+
+func TestCancelHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "success",
+			id:         "1700000000",
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "missing id",
+			id:         "",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "missing run id",
+		},
+		{
+			name:       "unknown run",
+			id:         "1700000000",
+			err:        fmt.Errorf("%w: 1700000000", runner.ErrRunNotFound),
+			wantStatus: http.StatusNotFound,
+			wantBody:   "run not found",
+		},
+		{
+			name:       "no run in progress",
+			id:         "1700000000",
+			err:        runner.ErrNoRunInProgress,
+			wantStatus: http.StatusConflict,
+			wantBody:   "no backup run in progress",
+		},
+		{
+			name:       "other error",
+			id:         "1700000000",
+			err:        errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canceller := &mockRunCanceller{err: tt.err}
+			handler := NewCancelHandler(canceller)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/runs/"+tt.id+"/cancel", nil)
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.Contains(t, w.Body.String(), tt.wantBody)
+			}
+			if tt.id != "" {
+				assert.Equal(t, tt.id, canceller.id)
+			}
+		})
+	}
+}
+
+type mockRunCanceller struct {
+	id  string
+	err error
+}
+
+func (m *mockRunCanceller) Cancel(id string) error {
+	m.id = id
+	return m.err
+}
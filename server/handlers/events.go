@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nomis52/goback/server/events"
+)
+
+// eventsKeepAliveInterval is how often a comment is sent to keep idle connections open.
+const eventsKeepAliveInterval = 15 * time.Second
+
+// EventsHandler streams live run progress as server-sent events.
+//
+// Each event is written as:
+//
+//	id: <id>
+//	event: <type>
+//	data: <JSON encoded events.Event>
+//
+// The optional "types" query parameter is a comma separated list of event types to stream,
+// e.g. /api/events?types=run_started,run_finished.
+type EventsHandler struct {
+	logger     *slog.Logger
+	subscriber EventSubscriber
+	keepAlive  time.Duration
+}
+
+// NewEventsHandler creates a new EventsHandler.
+func NewEventsHandler(logger *slog.Logger, subscriber EventSubscriber) *EventsHandler {
+	return &EventsHandler{
+		logger:     logger,
+		subscriber: subscriber,
+		keepAlive:  eventsKeepAliveInterval,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *EventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	// The stream is long-lived, so lift the server's write timeout for this request
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && err != http.ErrNotSupported {
+		h.logger.Warn("failed to clear write deadline for event stream", "error", err)
+	}
+
+	var types []events.Type
+	if param := r.URL.Query().Get("types"); param != "" {
+		for _, t := range strings.Split(param, ",") {
+			types = append(types, events.Type(strings.TrimSpace(t)))
+		}
+	}
+
+	ch, unsubscribe := h.subscriber.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		h.logger.Error("event stream not supported", "error", err)
+		return
+	}
+
+	ticker := time.NewTicker(h.keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-ch:
+			if !ok {
+				return
+			}
+			if len(types) > 0 && !slices.Contains(types, event.Type) {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				h.logger.Error("failed to encode event", "type", event.Type, "error", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
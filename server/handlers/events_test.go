@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nomis52/goback/server/events"
+)
+
+func TestEventsHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		wantBody string
+	}{
+		{
+			name:  "all events",
+			query: "",
+			wantBody: "id: 1\nevent: run_started\ndata: {\"id\":1,\"type\":\"run_started\",\"run_id\":\"42\",\"time\":\"0001-01-01T00:00:00Z\",\"data\":null}\n\n" +
+				"id: 2\nevent: activity_status\ndata: {\"id\":2,\"type\":\"activity_status\",\"run_id\":\"42\",\"time\":\"0001-01-01T00:00:00Z\",\"data\":{\"module\":\"m\",\"type\":\"T\",\"status\":\"working\"}}\n\n",
+		},
+		{
+			name:     "filtered by type",
+			query:    "?types=activity_status,log",
+			wantBody: "id: 2\nevent: activity_status\ndata: {\"id\":2,\"type\":\"activity_status\",\"run_id\":\"42\",\"time\":\"0001-01-01T00:00:00Z\",\"data\":{\"module\":\"m\",\"type\":\"T\",\"status\":\"working\"}}\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &mockEventSubscriber{ch: make(chan events.Event, 2)}
+			sub.ch <- events.Event{ID: 1, Type: events.TypeRunStarted, RunID: "42"}
+			sub.ch <- events.Event{ID: 2, Type: events.TypeActivityStatus, RunID: "42", Data: events.ActivityStatus{Module: "m", Type: "T", Status: "working"}}
+			close(sub.ch) // ends the stream once the buffered events are written
+
+			ts := httptest.NewServer(NewEventsHandler(slog.Default(), sub))
+			defer ts.Close()
+
+			resp, err := http.Get(ts.URL + tt.query)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+			var body strings.Builder
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				body.WriteString(scanner.Text() + "\n")
+			}
+			assert.Equal(t, tt.wantBody, body.String())
+			assert.True(t, sub.unsubscribed.Load(), "handler should unsubscribe when the stream ends")
+		})
+	}
+}
+
+func TestEventsHandler_ClientDisconnect(t *testing.T) {
+	sub := &mockEventSubscriber{ch: make(chan events.Event)}
+	handler := NewEventsHandler(slog.Default(), sub)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/api/events", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	cancel()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, sub.unsubscribed.Load())
+}
+
+type mockEventSubscriber struct {
+	ch           chan events.Event
+	unsubscribed atomic.Bool
+}
+
+func (m *mockEventSubscriber) Subscribe() (<-chan events.Event, func()) {
+	return m.ch, func() { m.unsubscribed.Store(true) }
+}
@@ -1,10 +1,29 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nomis52/goback/notify"
+	"github.com/nomis52/goback/server/runner"
 )
 
+// TotalCountHeader is the response header holding the number of runs that matched
+// a history query, across all pages.
+const TotalCountHeader = "X-Total-Count"
+
+// dateLayout is the layout for date-only query parameters.
+const dateLayout = "2006-01-02"
+
 // HistoryHandler handles requests for the run history.
+//
+// The optional query parameters are:
+//   - workflow: only runs that included the workflow
+//   - status: only runs with the outcome success, failure, cancelled or deadline
+//   - since, until: only runs that started in [since, until), as RFC 3339 times or dates
+//   - offset, limit: the page of matching runs to return
 type HistoryHandler struct {
 	provider HistoryProvider
 }
@@ -18,8 +37,74 @@ func NewHistoryHandler(provider HistoryProvider) *HistoryHandler {
 
 // ServeHTTP implements http.Handler.
 func (h *HistoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	history := h.provider.History()
-	writeJSON(w, http.StatusOK, history)
+	query, err := parseHistoryQuery(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	page := h.provider.QueryHistory(query)
+	w.Header().Set(TotalCountHeader, strconv.Itoa(page.Total))
+	writeJSON(w, http.StatusOK, page.Runs)
+}
+
+// parseHistoryQuery builds a history query from the request's query parameters.
+func parseHistoryQuery(r *http.Request) (runner.HistoryQuery, error) {
+	params := r.URL.Query()
+	query := runner.HistoryQuery{Workflow: params.Get("workflow")}
+
+	switch status := notify.Outcome(params.Get("status")); status {
+	case "", notify.OutcomeSuccess, notify.OutcomeFailure, notify.OutcomeCancelled, notify.OutcomeDeadline:
+		query.Outcome = status
+	default:
+		return query, fmt.Errorf("invalid status %q, must be success, failure, cancelled or deadline", status)
+	}
+
+	var err error
+	if query.Since, err = parseTimeParam(params.Get("since")); err != nil {
+		return query, fmt.Errorf("invalid since: %w", err)
+	}
+	if query.Until, err = parseTimeParam(params.Get("until")); err != nil {
+		return query, fmt.Errorf("invalid until: %w", err)
+	}
+	if !query.Since.IsZero() && !query.Until.IsZero() && !query.Since.Before(query.Until) {
+		return query, fmt.Errorf("since must be before until")
+	}
+
+	if query.Offset, err = parseCountParam(params.Get("offset")); err != nil {
+		return query, fmt.Errorf("invalid offset: %w", err)
+	}
+	if query.Limit, err = parseCountParam(params.Get("limit")); err != nil {
+		return query, fmt.Errorf("invalid limit: %w", err)
+	}
+	return query, nil
+}
+
+// parseTimeParam parses an RFC 3339 time or a date, which is taken as midnight UTC.
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not an RFC 3339 time or YYYY-MM-DD date", value)
+	}
+	return t, nil
+}
+
+// parseCountParam parses a non-negative integer.
+func parseCountParam(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q is not a non-negative integer", value)
+	}
+	return n, nil
 }
 
 // HistoryLogsHandler handles requests for logs of a specific run.
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nomis52/goback/notify"
+	"github.com/nomis52/goback/server/runner"
+)
+
+func TestHistoryHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantQuery  runner.HistoryQuery
+		wantBody   string
+	}{
+		{
+			name:       "no parameters",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "all parameters",
+			query:      "?workflow=backup&status=failure&since=2026-03-01&until=2026-03-08T12:00:00Z&offset=20&limit=10",
+			wantStatus: http.StatusOK,
+			wantQuery: runner.HistoryQuery{
+				Workflow: "backup",
+				Outcome:  notify.OutcomeFailure,
+				Since:    time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+				Until:    time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC),
+				Offset:   20,
+				Limit:    10,
+			},
+		},
+		{
+			name:       "invalid status",
+			query:      "?status=running",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `invalid status \"running\"`,
+		},
+		{
+			name:       "invalid since",
+			query:      "?since=yesterday",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid since",
+		},
+		{
+			name:       "since after until",
+			query:      "?since=2026-03-08&until=2026-03-01",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "since must be before until",
+		},
+		{
+			name:       "negative offset",
+			query:      "?offset=-1",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid offset",
+		},
+		{
+			name:       "invalid limit",
+			query:      "?limit=ten",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid limit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &mockHistoryProvider{page: runner.HistoryPage{
+				Runs:  []runner.RunSummary{{ID: "abc"}},
+				Total: 42,
+			}}
+			handler := NewHistoryHandler(provider)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/history"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.Contains(t, w.Body.String(), tt.wantBody)
+			}
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.wantQuery, provider.query)
+				assert.Equal(t, "42", w.Header().Get(TotalCountHeader))
+				assert.JSONEq(t, `[{"id": "abc", "state": "idle"}]`, w.Body.String())
+			}
+		})
+	}
+}
+
+type mockHistoryProvider struct {
+	page  runner.HistoryPage
+	query runner.HistoryQuery
+}
+
+func (m *mockHistoryProvider) QueryHistory(q runner.HistoryQuery) runner.HistoryPage {
+	m.query = q
+	return m.page
+}
+
+func (m *mockHistoryProvider) GetLogs(id string) ([]runner.ActivityExecution, error) {
+	return nil, runner.ErrRunNotFound
+}
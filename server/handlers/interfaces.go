@@ -6,7 +6,11 @@
 package handlers
 
 import (
+	"time"
+
 	"github.com/nomis52/goback/config"
+	"github.com/nomis52/goback/ledger"
+	"github.com/nomis52/goback/server/events"
 	"github.com/nomis52/goback/server/runner"
 )
 
@@ -22,11 +26,27 @@ type Reloader interface {
 
 // BackupRunner can start backup runs.
 type BackupRunner interface {
-	Run(workflows []string) error
+	RunUntil(workflows []string, deadline time.Time) error
 }
 
 // HistoryProvider provides access to run history.
 type HistoryProvider interface {
-	History() []runner.RunSummary
+	QueryHistory(runner.HistoryQuery) runner.HistoryPage
 	GetLogs(string) ([]runner.ActivityExecution, error)
 }
+
+// ResourceProvider provides access to the backup history of each resource.
+type ResourceProvider interface {
+	Resources() []runner.ResourceStatus
+	ResourceHistory(id string, limit int) []ledger.Entry
+}
+
+// RunCanceller can cancel an in-flight run.
+type RunCanceller interface {
+	Cancel(id string) error
+}
+
+// EventSubscriber provides a stream of live run progress events.
+type EventSubscriber interface {
+	Subscribe() (<-chan events.Event, func())
+}
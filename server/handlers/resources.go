@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+)
+
+// ResourcesHandler handles requests for the backup status of each resource.
+type ResourcesHandler struct {
+	provider ResourceProvider
+}
+
+// NewResourcesHandler creates a new ResourcesHandler.
+func NewResourcesHandler(provider ResourceProvider) *ResourcesHandler {
+	return &ResourcesHandler{
+		provider: provider,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *ResourcesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.provider.Resources())
+}
+
+// ResourceHistoryHandler handles requests for the backups of a single resource.
+//
+// The optional limit query parameter is the maximum number of backups to return.
+type ResourceHistoryHandler struct {
+	provider ResourceProvider
+}
+
+// NewResourceHistoryHandler creates a new ResourceHistoryHandler.
+func NewResourceHistoryHandler(provider ResourceProvider) *ResourceHistoryHandler {
+	return &ResourceHistoryHandler{
+		provider: provider,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+// The resource ID is taken from the {id} path wildcard.
+func (h *ResourceHistoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: "missing resource id",
+		})
+		return
+	}
+
+	limit, err := parseCountParam(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: "invalid limit: " + err.Error(),
+		})
+		return
+	}
+
+	backups := h.provider.ResourceHistory(id, limit)
+	if len(backups) == 0 {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{
+			Error: "no backups recorded for resource " + id,
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, backups)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nomis52/goback/ledger"
+	"github.com/nomis52/goback/server/runner"
+)
+
+func TestResourcesHandler(t *testing.T) {
+	provider := &mockResourceProvider{resources: []runner.ResourceStatus{
+		{ID: "compute-100", Kind: ledger.KindCompute, Name: "web", MaxBackupAge: "24h0m0s", Overdue: true},
+	}}
+	handler := NewResourcesHandler(provider)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/resources", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"compute-100"`)
+	assert.Contains(t, w.Body.String(), `"overdue":true`)
+}
+
+func TestResourceHistoryHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		query      string
+		wantStatus int
+		wantLimit  int
+		wantBody   string
+	}{
+		{
+			name:       "success",
+			id:         "compute-100",
+			wantStatus: http.StatusOK,
+			wantBody:   `"resource_id":"compute-100"`,
+		},
+		{
+			name:       "limit",
+			id:         "compute-100",
+			query:      "?limit=5",
+			wantStatus: http.StatusOK,
+			wantLimit:  5,
+		},
+		{
+			name:       "invalid limit",
+			id:         "compute-100",
+			query:      "?limit=-5",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid limit",
+		},
+		{
+			name:       "missing id",
+			id:         "",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "missing resource id",
+		},
+		{
+			name:       "unknown resource",
+			id:         "compute-999",
+			wantStatus: http.StatusNotFound,
+			wantBody:   "no backups recorded for resource compute-999",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &mockResourceProvider{history: map[string][]ledger.Entry{
+				"compute-100": {{ResourceID: "compute-100", Kind: ledger.KindCompute, Name: "web"}},
+			}}
+			handler := NewResourceHistoryHandler(provider)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/resources/"+tt.id+"/history"+tt.query, nil)
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.Contains(t, w.Body.String(), tt.wantBody)
+			}
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.wantLimit, provider.limit)
+			}
+		})
+	}
+}
+
+type mockResourceProvider struct {
+	resources []runner.ResourceStatus
+	history   map[string][]ledger.Entry
+	limit     int
+}
+
+func (m *mockResourceProvider) Resources() []runner.ResourceStatus {
+	return m.resources
+}
+
+func (m *mockResourceProvider) ResourceHistory(id string, limit int) []ledger.Entry {
+	m.limit = limit
+	return m.history[id]
+}
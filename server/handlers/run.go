@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/nomis52/goback/server/runner"
 )
@@ -12,6 +13,11 @@ import (
 // RunRequest defines the request body for POST /run.
 type RunRequest struct {
 	Workflows []string `json:"workflows"`
+	// Deadline is when the run's backup window closes, e.g. "2025-01-02T07:00:00Z". Optional.
+	Deadline *time.Time `json:"deadline,omitempty"`
+	// Window is the length of the run's backup window, e.g. "4h". Optional, and may not
+	// be combined with Deadline.
+	Window string `json:"window,omitempty"`
 }
 
 // RunHandler handles requests to trigger a backup run.
@@ -55,7 +61,15 @@ func (h *RunHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		seen[wf] = true
 	}
 
-	err := h.runner.Run(req.Workflows)
+	deadline, err := req.deadline(time.Now())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	err = h.runner.RunUntil(req.Workflows, deadline)
 	if err != nil {
 		if errors.Is(err, runner.ErrRunInProgress) {
 			writeJSON(w, http.StatusConflict, ErrorResponse{
@@ -72,3 +86,25 @@ func (h *RunHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusAccepted)
 }
+
+// deadline returns when the requested run's backup window closes, or the zero time if
+// the request has no deadline.
+func (req RunRequest) deadline(now time.Time) (time.Time, error) {
+	switch {
+	case req.Deadline != nil && req.Window != "":
+		return time.Time{}, errors.New("deadline and window cannot both be set")
+	case req.Deadline != nil:
+		return *req.Deadline, nil
+	case req.Window != "":
+		window, err := time.ParseDuration(req.Window)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid window: %w", err)
+		}
+		if window <= 0 {
+			return time.Time{}, fmt.Errorf("window must be positive, got %s", req.Window)
+		}
+		return now.Add(window), nil
+	default:
+		return time.Time{}, nil
+	}
+}
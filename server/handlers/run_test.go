@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nomis52/goback/server/runner"
+)
+
+func TestRunHandler(t *testing.T) {
+	deadline := time.Date(2025, 1, 2, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		body         string
+		err          error
+		wantStatus   int
+		wantBody     string
+		wantDeadline time.Time
+		wantWindow   time.Duration
+	}{
+		{
+			name:       "no deadline",
+			body:       `{"workflows": ["backup"]}`,
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:         "deadline",
+			body:         `{"workflows": ["backup"], "deadline": "2025-01-02T07:00:00Z"}`,
+			wantStatus:   http.StatusAccepted,
+			wantDeadline: deadline,
+		},
+		{
+			name:       "window",
+			body:       `{"workflows": ["backup"], "window": "3h"}`,
+			wantStatus: http.StatusAccepted,
+			wantWindow: 3 * time.Hour,
+		},
+		{
+			name:       "deadline and window",
+			body:       `{"workflows": ["backup"], "deadline": "2025-01-02T07:00:00Z", "window": "3h"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "cannot both be set",
+		},
+		{
+			name:       "invalid window",
+			body:       `{"workflows": ["backup"], "window": "soon"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid window",
+		},
+		{
+			name:       "negative window",
+			body:       `{"workflows": ["backup"], "window": "-1h"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "window must be positive",
+		},
+		{
+			name:       "no workflows",
+			body:       `{"workflows": []}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "cannot be empty",
+		},
+		{
+			name:       "run in progress",
+			body:       `{"workflows": ["backup"]}`,
+			err:        runner.ErrRunInProgress,
+			wantStatus: http.StatusConflict,
+			wantBody:   "already in progress",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backupRunner := &mockBackupRunner{err: tt.err}
+			handler := NewRunHandler(backupRunner)
+
+			req := httptest.NewRequest(http.MethodPost, "/run", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			before := time.Now()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.Contains(t, w.Body.String(), tt.wantBody)
+			}
+			if tt.wantStatus != http.StatusAccepted {
+				return
+			}
+
+			assert.Equal(t, []string{"backup"}, backupRunner.workflows)
+			if tt.wantWindow > 0 {
+				assert.WithinRange(t, backupRunner.deadline, before.Add(tt.wantWindow), time.Now().Add(tt.wantWindow))
+			} else {
+				assert.True(t, tt.wantDeadline.Equal(backupRunner.deadline), "got deadline %v", backupRunner.deadline)
+			}
+		})
+	}
+}
+
+type mockBackupRunner struct {
+	workflows []string
+	deadline  time.Time
+	err       error
+}
+
+func (m *mockBackupRunner) RunUntil(workflows []string, deadline time.Time) error {
+	m.workflows = workflows
+	m.deadline = deadline
+	return m.err
+}
@@ -0,0 +1,473 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/nomis52/goback/ledger"
+)
+
+// openTimeout is how long to wait for another process to release the database file lock.
+const openTimeout = 5 * time.Second
+
+var (
+	// runsBucket maps run ID to the JSON encoded RunSummary.
+	runsBucket = []byte("runs")
+	// logsBucket maps run ID to the JSON encoded activity executions. Logs are kept
+	// separate from summaries so history queries don't decode them.
+	logsBucket = []byte("logs")
+	// byStartBucket indexes runs by start time.
+	byStartBucket = []byte("by_start")
+	// byWorkflowBucket indexes runs by workflow name, then start time.
+	byWorkflowBucket = []byte("by_workflow")
+	// byOutcomeBucket indexes runs by outcome, then start time.
+	byOutcomeBucket = []byte("by_outcome")
+	// byResourceBucket maps resource ID, backup start time and run ID to the JSON encoded
+	// ledger entry of each resource backup.
+	byResourceBucket = []byte("by_resource")
+)
+
+// BoltStore persists run history in an embedded bbolt database.
+//
+// Each run is indexed by start time, workflow and outcome. Index keys are the
+// indexed value, a zero byte and the big-endian start time in nanoseconds followed
+// by the run ID, so each index can be range scanned in start time order.
+type BoltStore struct {
+	db       *bolt.DB
+	logger   *slog.Logger
+	maxCount int
+}
+
+// NewBoltStore opens, or creates, the database at path.
+// Once more than maxCount runs are stored the oldest runs are removed.
+func NewBoltStore(path string, maxCount int, logger *slog.Logger) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{runsBucket, logsBucket, byStartBucket, byWorkflowBucket, byOutcomeBucket, byResourceBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return fmt.Errorf("failed to create bucket %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltStore{
+		db:       db,
+		logger:   logger,
+		maxCount: maxCount,
+	}, nil
+}
+
+// Close closes the database.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// History returns all runs as summaries, most recent first.
+func (s *BoltStore) History() []RunSummary {
+	return s.Query(HistoryQuery{}).Runs
+}
+
+// Query returns the runs matching the query, most recent first.
+//
+// The most selective index for the query is scanned in reverse start time order.
+// Summaries are only decoded for runs on the requested page, or when the query has
+// filters the index doesn't cover.
+func (s *BoltStore) Query(q HistoryQuery) HistoryPage {
+	page := HistoryPage{Runs: []RunSummary{}}
+
+	bucket, prefix := byStartBucket, []byte(nil)
+	residual := q
+	switch {
+	case q.Workflow != "":
+		bucket, prefix = byWorkflowBucket, indexPrefix(q.Workflow)
+		residual.Workflow = ""
+	case q.Outcome != "":
+		bucket, prefix = byOutcomeBucket, indexPrefix(string(q.Outcome))
+		residual.Outcome = ""
+	}
+	filtered := residual.Workflow != "" || residual.Outcome != ""
+
+	lower := append(bytes.Clone(prefix), timeKey(q.Since)...)
+	var upper []byte
+	if !q.Until.IsZero() {
+		upper = append(bytes.Clone(prefix), timeKey(q.Until)...)
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		runs := tx.Bucket(runsBucket)
+		c := tx.Bucket(bucket).Cursor()
+
+		// Position the cursor on the last key before the upper bound
+		seek := upper
+		if seek == nil {
+			seek = prefixEnd(prefix)
+		}
+		var k, v []byte
+		if seek == nil {
+			k, v = c.Last()
+		} else if k, v = c.Seek(seek); k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+
+		for ; k != nil && bytes.HasPrefix(k, prefix) && bytes.Compare(k, lower) >= 0; k, v = c.Prev() {
+			inPage := page.Total >= q.Offset && (q.Limit <= 0 || len(page.Runs) < q.Limit)
+			if !filtered && !inPage {
+				page.Total++
+				continue
+			}
+
+			summary, err := decodeSummary(runs.Get(v))
+			if err != nil {
+				s.logger.Warn("failed to decode run", "id", string(v), "error", err)
+				continue
+			}
+			if filtered && !residual.matches(summary) {
+				continue
+			}
+			if inPage {
+				page.Runs = append(page.Runs, summary)
+			}
+			page.Total++
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("failed to query run history", "error", err)
+	}
+	return page
+}
+
+// Resources returns the latest backups of each resource, ordered by kind and name.
+//
+// Each resource's backups are adjacent in the resource index, so the index is scanned
+// in reverse, reading back from each resource's latest backup to its latest successful
+// backup before seeking to the previous resource.
+func (s *BoltStore) Resources() []ResourceBackups {
+	result := []ResourceBackups{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(byResourceBucket).Cursor()
+		k, v := c.Last()
+		for k != nil {
+			prefix := k[:bytes.IndexByte(k, 0)+1]
+
+			var backups ResourceBackups
+			for first := true; k != nil && bytes.HasPrefix(k, prefix); k, v = c.Prev() {
+				var entry ledger.Entry
+				if err := json.Unmarshal(v, &entry); err != nil {
+					return fmt.Errorf("failed to decode backup %q: %w", k, err)
+				}
+				if first {
+					backups.Latest = entry
+					first = false
+				}
+				if entry.Succeeded() {
+					backups.LastSuccess = &entry
+					break
+				}
+			}
+			result = append(result, backups)
+
+			// Move to the latest backup of the previous resource
+			c.Seek(prefix)
+			k, v = c.Prev()
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("failed to list resources", "error", err)
+	}
+
+	sortResources(result)
+	return result
+}
+
+// ResourceHistory returns up to limit backups of a resource, most recent first.
+func (s *BoltStore) ResourceHistory(id string, limit int) []ledger.Entry {
+	result := []ledger.Entry{}
+	prefix := indexPrefix(id)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(byResourceBucket).Cursor()
+		k, v := c.Seek(prefixEnd(prefix))
+		if k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+
+		for ; k != nil && bytes.HasPrefix(k, prefix); k, v = c.Prev() {
+			var entry ledger.Entry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return fmt.Errorf("failed to decode backup %q: %w", k, err)
+			}
+			result = append(result, entry)
+			if limit > 0 && len(result) == limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("failed to read resource history", "id", id, "error", err)
+	}
+	return result
+}
+
+// Logs returns the activity executions for a specific run.
+func (s *BoltStore) Logs(id string) []ActivityExecution {
+	var logs []ActivityExecution
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(logsBucket).Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+		logs = []ActivityExecution{}
+		return json.Unmarshal(data, &logs)
+	})
+	if err != nil {
+		s.logger.Warn("failed to read run logs", "id", id, "error", err)
+		return nil
+	}
+	return logs
+}
+
+// Save persists a run, replacing any run with the same ID, and removes the oldest
+// runs once the store holds more than maxCount.
+func (s *BoltStore) Save(summary RunSummary, logs []ActivityExecution) error {
+	if summary.StartedAt == nil {
+		return fmt.Errorf("cannot save run without start time")
+	}
+
+	// Ensure ID is populated
+	if summary.ID == "" {
+		summary.ID = summary.CalculateID()
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := s.put(tx, summary, logs); err != nil {
+			return err
+		}
+		return s.prune(tx)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+
+	s.logger.Debug("saved run to database", "id", summary.ID)
+	return nil
+}
+
+// ImportDiskStore copies the runs saved by a DiskStore in dir into the database.
+// It does nothing if the database already holds runs, so it is safe to call on every start.
+// Returns the number of runs imported.
+func (s *BoltStore) ImportDiskStore(dir string) (int, error) {
+	empty := true
+	err := s.db.View(func(tx *bolt.Tx) error {
+		k, _ := tx.Bucket(runsBucket).Cursor().First()
+		empty = k == nil
+		return nil
+	})
+	if err != nil || !empty {
+		return 0, err
+	}
+
+	disk := &DiskStore{dir: dir, logger: s.logger, maxCount: s.maxCount}
+	summaries, logs, err := disk.load()
+	if err != nil {
+		return 0, err
+	}
+
+	imported := 0
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		for _, summary := range summaries {
+			if summary.StartedAt == nil {
+				continue
+			}
+			if err := s.put(tx, summary, logs[summary.ID]); err != nil {
+				return err
+			}
+			imported++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to import runs: %w", err)
+	}
+	return imported, nil
+}
+
+// put writes a run and its index entries.
+func (s *BoltStore) put(tx *bolt.Tx, summary RunSummary, logs []ActivityExecution) error {
+	if err := s.delete(tx, summary.ID); err != nil {
+		return err
+	}
+
+	summaryData, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run: %w", err)
+	}
+	logsData, err := json.Marshal(logs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal logs: %w", err)
+	}
+
+	id := []byte(summary.ID)
+	if err := tx.Bucket(runsBucket).Put(id, summaryData); err != nil {
+		return err
+	}
+	if err := tx.Bucket(logsBucket).Put(id, logsData); err != nil {
+		return err
+	}
+	for bucket, key := range indexKeys(summary) {
+		for _, k := range key {
+			if err := tx.Bucket([]byte(bucket)).Put(k, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	backups := tx.Bucket(byResourceBucket)
+	for _, entry := range runBackups([]RunSummary{summary}, map[string][]ActivityExecution{summary.ID: logs}) {
+		if entry.RunID == "" {
+			entry.RunID = summary.ID
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal backup: %w", err)
+		}
+		if err := backups.Put(backupKey(entry, summary.ID), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// delete removes a run and its index entries, if it exists.
+func (s *BoltStore) delete(tx *bolt.Tx, id string) error {
+	runs := tx.Bucket(runsBucket)
+	data := runs.Get([]byte(id))
+	if data == nil {
+		return nil
+	}
+	summary, err := decodeSummary(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode run %s: %w", id, err)
+	}
+
+	for bucket, key := range indexKeys(summary) {
+		for _, k := range key {
+			if err := tx.Bucket([]byte(bucket)).Delete(k); err != nil {
+				return err
+			}
+		}
+	}
+	logs := tx.Bucket(logsBucket)
+	if data := logs.Get([]byte(id)); data != nil {
+		var executions []ActivityExecution
+		if err := json.Unmarshal(data, &executions); err != nil {
+			return fmt.Errorf("failed to decode logs of run %s: %w", id, err)
+		}
+		for _, exec := range executions {
+			for _, entry := range exec.Backups {
+				if err := tx.Bucket(byResourceBucket).Delete(backupKey(entry, id)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := runs.Delete([]byte(id)); err != nil {
+		return err
+	}
+	return logs.Delete([]byte(id))
+}
+
+// prune removes the oldest runs beyond maxCount.
+func (s *BoltStore) prune(tx *bolt.Tx) error {
+	// Skip over the newest maxCount runs, everything before them is removed
+	c := tx.Bucket(byStartBucket).Cursor()
+	k, v := c.Last()
+	for kept := 0; k != nil && kept < s.maxCount; kept++ {
+		k, v = c.Prev()
+	}
+
+	var oldest []string
+	for ; k != nil; k, v = c.Prev() {
+		oldest = append(oldest, string(v))
+	}
+	for _, id := range oldest {
+		if err := s.delete(tx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexKeys returns the index entries for a run, keyed by bucket name.
+func indexKeys(summary RunSummary) map[string][][]byte {
+	suffix := append(timeKey(*summary.StartedAt), summary.ID...)
+	keys := map[string][][]byte{
+		string(byStartBucket):   {suffix},
+		string(byOutcomeBucket): {append(indexPrefix(string(outcome(summary))), suffix...)},
+	}
+	for _, w := range summary.Workflows {
+		keys[string(byWorkflowBucket)] = append(keys[string(byWorkflowBucket)], append(indexPrefix(w), suffix...))
+	}
+	return keys
+}
+
+// backupKey returns the resource index key of a backup in a run.
+func backupKey(entry ledger.Entry, runID string) []byte {
+	key := append(indexPrefix(entry.ResourceID), timeKey(entry.StartedAt)...)
+	return append(key, runID...)
+}
+
+// indexPrefix returns the key prefix for an indexed value.
+func indexPrefix(value string) []byte {
+	return append([]byte(value), 0)
+}
+
+// prefixEnd returns the first key after every key with the given prefix, or nil
+// if the prefix is empty.
+func prefixEnd(prefix []byte) []byte {
+	if len(prefix) == 0 {
+		return nil
+	}
+	end := bytes.Clone(prefix)
+	end[len(end)-1]++
+	return end
+}
+
+// timeKey encodes t so that keys sort in time order. The zero time sorts first.
+func timeKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	if !t.IsZero() && t.UnixNano() > 0 {
+		binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	}
+	return key
+}
+
+func decodeSummary(data []byte) (RunSummary, error) {
+	var summary RunSummary
+	if data == nil {
+		return summary, fmt.Errorf("run not found")
+	}
+	err := json.Unmarshal(data, &summary)
+	return summary, err
+}
@@ -0,0 +1,124 @@
+package runner
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nomis52/goback/ledger"
+)
+
+func newTestBoltStore(t *testing.T, path string, maxCount int) *BoltStore {
+	t.Helper()
+	store, err := NewBoltStore(path, maxCount, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestBoltStore_SaveAndReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	store := newTestBoltStore(t, path, 10)
+	assert.Empty(t, store.History())
+
+	startTime := time.Now().Truncate(time.Second)
+	endTime := startTime.Add(time.Minute)
+	summary := RunSummary{
+		State:     RunStateIdle,
+		Workflows: []string{"backup"},
+		StartedAt: &startTime,
+		EndedAt:   &endTime,
+	}
+	executions := []ActivityExecution{{Module: "backup", Type: "BackupVMs", State: "completed"}}
+	require.NoError(t, store.Save(summary, executions))
+
+	history := store.History()
+	require.Len(t, history, 1)
+	assertRunSummaryEqual(t, summary, history[0])
+	id := history[0].ID
+	assert.Equal(t, executions, store.Logs(id))
+	assert.Nil(t, store.Logs("missing"))
+
+	// Runs survive a restart
+	require.NoError(t, store.Close())
+	store = newTestBoltStore(t, path, 10)
+	history = store.History()
+	require.Len(t, history, 1)
+	assertRunSummaryEqual(t, summary, history[0])
+	assert.Equal(t, executions, store.Logs(id))
+}
+
+func TestBoltStore_SaveWithoutStartTime(t *testing.T) {
+	store := newTestBoltStore(t, filepath.Join(t.TempDir(), "history.db"), 10)
+
+	err := store.Save(RunSummary{State: RunStateIdle}, nil)
+	assert.EqualError(t, err, "cannot save run without start time")
+}
+
+func TestBoltStore_SaveReplacesRun(t *testing.T) {
+	store := newTestBoltStore(t, filepath.Join(t.TempDir(), "history.db"), 10)
+
+	startTime := time.Now()
+	require.NoError(t, store.Save(RunSummary{ID: "1", Workflows: []string{"backup"}, StartedAt: &startTime, Error: "failed"}, nil))
+	require.NoError(t, store.Save(RunSummary{ID: "1", Workflows: []string{"maintenance"}, StartedAt: &startTime}, nil))
+
+	history := store.History()
+	require.Len(t, history, 1)
+	assert.Empty(t, history[0].Error)
+	assert.Empty(t, store.Query(HistoryQuery{Workflow: "backup"}).Runs, "stale index entries should be removed")
+	assert.Len(t, store.Query(HistoryQuery{Workflow: "maintenance"}).Runs, 1)
+}
+
+func TestBoltStore_MaxCount(t *testing.T) {
+	store := newTestBoltStore(t, filepath.Join(t.TempDir(), "history.db"), 3)
+
+	baseTime := time.Now().Truncate(time.Second)
+	for i := 0; i < 5; i++ {
+		startTime := baseTime.Add(time.Duration(i) * time.Hour)
+		executions := []ActivityExecution{{Backups: []ledger.Entry{{ResourceID: "compute-100", StartedAt: startTime}}}}
+		require.NoError(t, store.Save(RunSummary{Workflows: []string{"backup"}, StartedAt: &startTime}, executions))
+	}
+
+	history := store.History()
+	require.Len(t, history, 3)
+	for i, run := range history {
+		assert.True(t, baseTime.Add(time.Duration(4-i)*time.Hour).Equal(*run.StartedAt))
+	}
+	assert.Equal(t, 3, store.Query(HistoryQuery{Workflow: "backup"}).Total)
+	assert.Len(t, store.ResourceHistory("compute-100", 0), 3, "backups of removed runs should be removed")
+}
+
+func TestBoltStore_ImportDiskStore(t *testing.T) {
+	dir := t.TempDir()
+	startTime := time.Date(2026, 3, 1, 4, 5, 0, 0, time.UTC)
+	run := runRecord{
+		RunSummary:         RunSummary{ID: "1", Workflows: []string{"backup"}, StartedAt: &startTime},
+		ActivityExecutions: []ActivityExecution{{Module: "backup", Type: "BackupVMs", State: "completed"}},
+	}
+	data, err := json.Marshal(run)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2026-03-01T04-05-00.json"), data, 0644))
+
+	store := newTestBoltStore(t, filepath.Join(dir, "history.db"), 10)
+	imported, err := store.ImportDiskStore(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, imported)
+
+	history := store.History()
+	require.Len(t, history, 1)
+	assertRunSummaryEqual(t, run.RunSummary, history[0])
+	assert.Equal(t, run.ActivityExecutions, store.Logs("1"))
+
+	// Once the database holds runs, importing does nothing
+	imported, err = store.ImportDiskStore(dir)
+	require.NoError(t, err)
+	assert.Zero(t, imported)
+	assert.Len(t, store.History(), 1)
+}
@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"sort"
 	"sync"
+
+	"github.com/nomis52/goback/ledger"
 )
 
 // DiskStore persists run history to disk as JSON files.
@@ -59,6 +61,30 @@ func (s *DiskStore) History() []RunSummary {
 	return result
 }
 
+// Query returns the runs matching the query, most recent first.
+func (s *DiskStore) Query(q HistoryQuery) HistoryPage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return queryHistory(s.summaries, q)
+}
+
+// Resources returns the latest backups of each resource, ordered by kind and name.
+func (s *DiskStore) Resources() []ResourceBackups {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return latestBackups(runBackups(s.summaries, s.logs))
+}
+
+// ResourceHistory returns up to limit backups of a resource, most recent first.
+func (s *DiskStore) ResourceHistory(id string, limit int) []ledger.Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return resourceHistory(runBackups(s.summaries, s.logs), id, limit)
+}
+
 // Logs returns the activity executions for a specific run.
 func (s *DiskStore) Logs(id string) []ActivityExecution {
 	s.mu.Lock()
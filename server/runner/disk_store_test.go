@@ -190,6 +190,36 @@ func TestDiskStore_LoadsExistingRuns(t *testing.T) {
 	assertRunSummaryEqual(t, summary, history[0])
 }
 
+func TestDiskStore_SavesOutputs(t *testing.T) {
+	tmpDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	now := time.Now()
+	summary := RunSummary{ID: "run-1", State: RunStateIdle, StartedAt: &now, EndedAt: &now}
+	executions := []ActivityExecution{{
+		Module: "backup",
+		Type:   "BackupVMs",
+		State:  "completed",
+		Outputs: map[string]any{
+			"Backups": []map[string]any{{"task_id": "UPID:pve1:vzdump", "size": 2048}},
+		},
+	}}
+
+	store1, err := NewDiskStore(tmpDir, 10, logger)
+	require.NoError(t, err)
+	require.NoError(t, store1.Save(summary, executions))
+
+	// Outputs are decoded as generic JSON values when the run is loaded again
+	store2, err := NewDiskStore(tmpDir, 10, logger)
+	require.NoError(t, err)
+
+	logs := store2.Logs("run-1")
+	require.Len(t, logs, 1)
+	assert.Equal(t, map[string]any{
+		"Backups": []any{map[string]any{"task_id": "UPID:pve1:vzdump", "size": float64(2048)}},
+	}, logs[0].Outputs)
+}
+
 func TestDiskStore_IgnoresNonJSONFiles(t *testing.T) {
 	tmpDir := t.TempDir()
 	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
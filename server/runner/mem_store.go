@@ -1,6 +1,10 @@
 package runner
 
-import "sync"
+import (
+	"sync"
+
+	"github.com/nomis52/goback/ledger"
+)
 
 // MemoryStore keeps run history in memory only (no persistence).
 type MemoryStore struct {
@@ -27,6 +31,30 @@ func (s *MemoryStore) History() []RunSummary {
 	return result
 }
 
+// Query returns the runs matching the query, most recent first.
+func (s *MemoryStore) Query(q HistoryQuery) HistoryPage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return queryHistory(s.summaries, q)
+}
+
+// Resources returns the latest backups of each resource, ordered by kind and name.
+func (s *MemoryStore) Resources() []ResourceBackups {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return latestBackups(runBackups(s.summaries, s.logs))
+}
+
+// ResourceHistory returns up to limit backups of a resource, most recent first.
+func (s *MemoryStore) ResourceHistory(id string, limit int) []ledger.Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return resourceHistory(runBackups(s.summaries, s.logs), id, limit)
+}
+
 // Logs returns the activity executions for a specific run.
 func (s *MemoryStore) Logs(id string) []ActivityExecution {
 	s.mu.Lock()
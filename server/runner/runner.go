@@ -49,29 +49,46 @@ import (
 
 	"github.com/nomis52/goback/activity"
 	"github.com/nomis52/goback/config"
+	"github.com/nomis52/goback/ledger"
 	"github.com/nomis52/goback/logging"
 	"github.com/nomis52/goback/metrics"
+	"github.com/nomis52/goback/notify"
+	"github.com/nomis52/goback/server/events"
 	"github.com/nomis52/goback/workflow"
 	"github.com/nomis52/goback/workflows"
 )
 
 const defaultMaxHistorySize = 100
 
-// ErrRunInProgress is returned when attempting to start a run while one is already running.
-var ErrRunInProgress = errors.New("backup run already in progress")
+var (
+	// ErrRunInProgress is returned when attempting to start a run while one is already running.
+	ErrRunInProgress = errors.New("backup run already in progress")
+
+	// ErrNoRunInProgress is returned when attempting to cancel a run while none is running.
+	ErrNoRunInProgress = errors.New("no backup run in progress")
+
+	// ErrRunNotFound is returned when a run ID does not match any known run.
+	ErrRunNotFound = errors.New("run not found")
+)
 
 // Runner manages backup run execution.
 type Runner struct {
 	logger         *slog.Logger
 	configProvider ConfigProvider
-	factories      map[string]WorkflowFactory
 	store          StateStore
+	notifier       Notifier
+	publisher      EventPublisher
+	deadlineGrace  time.Duration
 
 	mu               sync.Mutex
+	factories        map[string]WorkflowFactory  // Replaced by SetFactories when the config is reloaded
 	runStatus        RunSummary
 	workflow         workflow.Workflow           // Current or last run's workflow
 	statusCollection *activity.StatusHandler     // Current run's status collection
 	logCollector     *logging.LogCollector       // Captures logs during workflow execution
+	backupCollector  *ledger.Collector           // Captures resource backups during workflow execution
+	cancelRun        context.CancelFunc          // Cancels the current run's context, nil when idle
+	cancelRequested  bool                        // True once Cancel has been called for the current run
 
 	// Metrics
 	registry                 metrics.Registry
@@ -85,6 +102,16 @@ type ConfigProvider interface {
 	Config() *config.Config
 }
 
+// Notifier is sent a summary of each run once it finishes.
+type Notifier interface {
+	Notify(ctx context.Context, event notify.Event) error
+}
+
+// EventPublisher receives live progress events for runs.
+type EventPublisher interface {
+	Publish(event events.Event)
+}
+
 // Option configures a Runner.
 type Option func(*Runner)
 
@@ -102,6 +129,29 @@ func WithMetricsRegistry(registry metrics.Registry) Option {
 	}
 }
 
+// WithNotifier configures the runner to send a notification when each run finishes.
+func WithNotifier(notifier Notifier) Option {
+	return func(r *Runner) {
+		r.notifier = notifier
+	}
+}
+
+// WithEventPublisher configures the runner to publish run, activity, status and log events
+// as they happen.
+func WithEventPublisher(publisher EventPublisher) Option {
+	return func(r *Runner) {
+		r.publisher = publisher
+	}
+}
+
+// WithDeadlineGrace sets how long work in progress at a run's deadline may continue before
+// the run is cancelled. Without it, runs are cancelled as soon as their deadline passes.
+func WithDeadlineGrace(grace time.Duration) Option {
+	return func(r *Runner) {
+		r.deadlineGrace = grace
+	}
+}
+
 // New creates a new Runner.
 func New(logger *slog.Logger, provider ConfigProvider, factories map[string]WorkflowFactory, opts ...Option) *Runner {
 	r := &Runner{
@@ -152,36 +202,87 @@ func New(logger *slog.Logger, provider ConfigProvider, factories map[string]Work
 // Returns ErrRunInProgress if a run is already in progress.
 // Returns an error if workflows is empty or contains unknown workflow names.
 func (r *Runner) Run(workflows []string) error {
-	if len(workflows) == 0 {
+	return r.RunUntil(workflows, time.Time{})
+}
+
+// RunUntil starts a backup run that must finish by deadline. A zero deadline means the
+// run has no deadline.
+//
+// Once the deadline passes, activities stop starting new work, such as backups of further
+// VMs. Work in progress continues for the grace period set with WithDeadlineGrace, after
+// which the run is cancelled. Workflows wrapped with workflow.IgnoreCancel (such as
+// poweroff) and finalizers still run, and the run is recorded with RunStateDeadlineExceeded.
+// Returns an error if the deadline has already passed.
+func (r *Runner) RunUntil(workflowNames []string, deadline time.Time) error {
+	if len(workflowNames) == 0 {
 		return errors.New("no workflows specified")
 	}
 
-	// Validate all workflow names exist
-	for _, name := range workflows {
-		if _, ok := r.factories[name]; !ok {
-			available := make([]string, 0, len(r.factories))
-			for k := range r.factories {
-				available = append(available, k)
-			}
-			sort.Strings(available)
-			return fmt.Errorf("unknown workflow %q (available: %v)", name, available)
-		}
+	// Look up the factories now, so a reload during the run doesn't change its workflows
+	factories, err := r.lookupFactories(workflowNames)
+	if err != nil {
+		return err
+	}
+
+	if !deadline.IsZero() && !deadline.After(time.Now()) {
+		return fmt.Errorf("run deadline %s has already passed", deadline.Format(time.RFC3339))
+	}
+
+	var window workflows.Window
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if deadline.IsZero() {
+		ctx, cancel = context.WithCancel(context.Background())
+	} else {
+		window = workflows.Window{Deadline: deadline, Grace: r.deadlineGrace}
+		ctx, cancel = context.WithDeadline(context.Background(), deadline.Add(r.deadlineGrace))
 	}
 
-	if !r.tryStart(workflows) {
+	if !r.tryStart(workflowNames, deadline, cancel) {
+		cancel()
 		return ErrRunInProgress
 	}
 
-	r.logger.Info("starting backup run", "workflows", workflows)
+	if !deadline.IsZero() {
+		r.logger.Info("starting backup run", "workflows", workflowNames, "deadline", deadline, "grace", r.deadlineGrace)
+	} else {
+		r.logger.Info("starting backup run", "workflows", workflowNames)
+	}
 
 	go func() {
-		err := r.executeRun(context.Background(), workflows)
-		r.finish(err)
+		defer cancel()
+		err := r.executeRun(ctx, workflowNames, factories, window)
+		r.finish(err, err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded))
 	}()
 
 	return nil
 }
 
+// Cancel cancels the in-flight run with the given ID.
+// The run's context is cancelled, so running activities unwind and pending activities
+// are skipped. Workflows wrapped with workflow.IgnoreCancel (such as poweroff) still run.
+// The run is recorded with RunStateCancelled once it finishes.
+// Returns ErrNoRunInProgress if no run is in progress, or ErrRunNotFound if id does not
+// match the current run. Cancelling a run more than once is not an error.
+func (r *Runner) Cancel(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.runStatus.State != RunStateRunning {
+		return ErrNoRunInProgress
+	}
+	if r.runStatus.ID != id {
+		return fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	}
+
+	if !r.cancelRequested {
+		r.logger.Info("cancelling backup run", "id", id, "workflows", r.runStatus.Workflows)
+		r.cancelRequested = true
+		r.cancelRun()
+	}
+	return nil
+}
+
 // Status returns the current run summary and activity executions.
 // If a run is in progress, includes real-time activity executions with captured logs and status messages.
 // If idle, returns the last completed run summary and executions.
@@ -213,6 +314,28 @@ func (r *Runner) History() []RunSummary {
 	return r.store.History()
 }
 
+// Resources returns the backup status of each resource that has been backed up, ordered by kind and name.
+func (r *Runner) Resources() []ResourceStatus {
+	now := time.Now()
+	resources := r.store.Resources()
+	result := make([]ResourceStatus, 0, len(resources))
+	for _, backups := range resources {
+		result = append(result, newResourceStatus(backups, now))
+	}
+	return result
+}
+
+// ResourceHistory returns up to limit backups of a resource, most recent first.
+// A limit of 0 returns all of them.
+func (r *Runner) ResourceHistory(id string, limit int) []ledger.Entry {
+	return r.store.ResourceHistory(id, limit)
+}
+
+// QueryHistory returns the completed runs matching the query, most recent first.
+func (r *Runner) QueryHistory(q HistoryQuery) HistoryPage {
+	return r.store.Query(q)
+}
+
 // GetLogs returns the activity executions for a specific run.
 func (r *Runner) GetLogs(id string) ([]ActivityExecution, error) {
 	// First check if it's the current run
@@ -227,12 +350,45 @@ func (r *Runner) GetLogs(id string) ([]ActivityExecution, error) {
 		return logs, nil
 	}
 
-	return nil, fmt.Errorf("run not found: %s", id)
+	return nil, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+}
+
+// SetFactories replaces the workflow factories, e.g. when the config is reloaded and the
+// set of declared workflows changes. A run in progress keeps the workflows it started with.
+func (r *Runner) SetFactories(factories map[string]WorkflowFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories = factories
+}
+
+// lookupFactories returns the factory for each of the named workflows.
+// Returns an error if any of them is unknown.
+func (r *Runner) lookupFactories(workflowNames []string) ([]WorkflowFactory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	factories := make([]WorkflowFactory, 0, len(workflowNames))
+	for _, name := range workflowNames {
+		factory, ok := r.factories[name]
+		if !ok {
+			available := make([]string, 0, len(r.factories))
+			for k := range r.factories {
+				available = append(available, k)
+			}
+			sort.Strings(available)
+			return nil, fmt.Errorf("unknown workflow %q (available: %v)", name, available)
+		}
+		factories = append(factories, factory)
+	}
+	return factories, nil
 }
 
 // AvailableWorkflows returns a map of all available workflow names.
 // The map keys are workflow names, values are always true.
 func (r *Runner) AvailableWorkflows() map[string]bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	available := make(map[string]bool, len(r.factories))
 	for name := range r.factories {
 		available[name] = true
@@ -265,8 +421,10 @@ func (r *Runner) CurrentStatuses() map[workflow.ActivityID]string {
 }
 
 // tryStart attempts to transition from idle to running.
+// The cancel function is retained so the run can be cancelled via Cancel.
+// deadline is zero if the run has no deadline.
 // Returns true if successful, false if already running.
-func (r *Runner) tryStart(workflows []string) bool {
+func (r *Runner) tryStart(workflows []string, deadline time.Time, cancel context.CancelFunc) bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -280,12 +438,19 @@ func (r *Runner) tryStart(workflows []string) bool {
 		Workflows: workflows,
 		StartedAt: &now,
 	}
+	if !deadline.IsZero() {
+		r.runStatus.Deadline = &deadline
+	}
 	r.runStatus.ID = r.runStatus.CalculateID()
+	r.cancelRun = cancel
+	r.cancelRequested = false
+	r.publish(events.TypeRunStarted, r.runStatus.ID, r.runStatus)
 	return true
 }
 
 // finish transitions from running to idle and records the result.
-func (r *Runner) finish(err error) {
+// deadlineExceeded is true if the run was stopped because its deadline and grace period passed.
+func (r *Runner) finish(err error, deadlineExceeded bool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -294,10 +459,24 @@ func (r *Runner) finish(err error) {
 
 	r.runStatus.State = RunStateIdle
 	r.runStatus.EndedAt = &endTime
+	r.cancelRun = nil
+
+	// A run cancelled by hand is recorded as cancelled, even if its deadline also passed
+	if r.cancelRequested {
+		r.runStatus.State = RunStateCancelled
+	} else if deadlineExceeded {
+		r.runStatus.State = RunStateDeadlineExceeded
+	}
 
 	if err != nil {
 		r.runStatus.Error = err.Error()
-		r.logger.Error("backup run failed", "error", err, "duration", duration)
+		if r.cancelRequested {
+			r.logger.Warn("backup run cancelled", "error", err, "duration", duration)
+		} else if deadlineExceeded {
+			r.logger.Warn("backup run stopped at its deadline", "error", err, "duration", duration)
+		} else {
+			r.logger.Error("backup run failed", "error", err, "duration", duration)
+		}
 	} else {
 		r.runStatus.Error = ""
 		r.logger.Info("backup run completed", "duration", duration)
@@ -311,7 +490,7 @@ func (r *Runner) finish(err error) {
 			r.workflowLastRunTimestamp.With(labels).Set(float64(endTime.Unix()))
 			r.workflowLastRunDuration.With(labels).Set(duration.Seconds())
 
-			if err != nil {
+			if err != nil || r.runStatus.State != RunStateIdle {
 				r.workflowLastRunSuccess.With(labels).Set(0)
 			} else {
 				r.workflowLastRunSuccess.With(labels).Set(1)
@@ -325,12 +504,95 @@ func (r *Runner) finish(err error) {
 		executions = r.buildActivityExecutions()
 	}
 
+	r.publish(events.TypeRunFinished, r.runStatus.ID, r.runStatus)
+
+	// Notify before saving so the previous run is still the most recent in the store
+	if r.notifier != nil {
+		event := r.buildEvent(executions)
+		go func() {
+			if err := r.notifier.Notify(context.Background(), event); err != nil {
+				r.logger.Warn("failed to send run notification", "id", event.RunID, "error", err)
+			}
+		}()
+	}
+
 	// Save to store
 	if err := r.store.Save(r.runStatus, executions); err != nil {
 		r.logger.Error("failed to save run to store", "error", err)
 	}
 }
 
+// buildEvent creates the notification event for the run that just finished.
+// Must be called with r.mu held, before the run is saved to the store.
+func (r *Runner) buildEvent(executions []ActivityExecution) notify.Event {
+	event := notify.Event{
+		RunID:     r.runStatus.ID,
+		Workflows: r.runStatus.Workflows,
+		Outcome:   outcome(r.runStatus),
+		StartedAt: *r.runStatus.StartedAt,
+		EndedAt:   *r.runStatus.EndedAt,
+		Error:     r.runStatus.Error,
+	}
+
+	if previous := r.store.Query(HistoryQuery{Limit: 1}).Runs; event.Outcome == notify.OutcomeSuccess && len(previous) > 0 {
+		event.Recovered = outcome(previous[0]) != notify.OutcomeSuccess
+	}
+
+	for _, exec := range executions {
+		event.Activities = append(event.Activities, notify.Activity{
+			Module:   exec.Module,
+			Type:     exec.Type,
+			State:    exec.State,
+			Error:    exec.Error,
+			Attempts: exec.Attempts,
+		})
+	}
+	return event
+}
+
+// publish sends an event to the configured publisher, if any.
+func (r *Runner) publish(eventType events.Type, runID string, data any) {
+	if r.publisher == nil {
+		return
+	}
+	r.publisher.Publish(events.Event{Type: eventType, RunID: runID, Data: data})
+}
+
+// activityState converts an activity result into an event payload.
+func activityState(id workflow.ActivityID, result workflow.Result) events.ActivityState {
+	state := events.ActivityState{
+		Module:   id.Module,
+		Type:     id.Type,
+		State:    result.State.String(),
+		Attempts: result.Attempts,
+		TimedOut: result.TimedOut(),
+	}
+	if result.Error != nil {
+		state.Error = result.Error.Error()
+	}
+	if !result.StartTime.IsZero() {
+		state.StartTime = &result.StartTime
+	}
+	if !result.EndTime.IsZero() {
+		state.EndTime = &result.EndTime
+	}
+	return state
+}
+
+// outcome returns how a completed run finished.
+func outcome(summary RunSummary) notify.Outcome {
+	switch {
+	case summary.State == RunStateCancelled:
+		return notify.OutcomeCancelled
+	case summary.State == RunStateDeadlineExceeded:
+		return notify.OutcomeDeadline
+	case summary.Error != "":
+		return notify.OutcomeFailure
+	default:
+		return notify.OutcomeSuccess
+	}
+}
+
 // buildActivityExecutions combines workflow results, logs, and status messages into ActivityExecution structs.
 func (r *Runner) buildActivityExecutions() []ActivityExecution {
 	results := r.workflow.GetAllResults()
@@ -351,6 +613,9 @@ func (r *Runner) buildActivityExecutions() []ActivityExecution {
 			State:     result.State.String(),
 			StartTime: &result.StartTime,
 			EndTime:   &result.EndTime,
+			Attempts:  result.Attempts,
+			TimedOut:  result.TimedOut(),
+			Outputs:   result.Outputs,
 		}
 
 		if result.Error != nil {
@@ -369,6 +634,14 @@ func (r *Runner) buildActivityExecutions() []ActivityExecution {
 			exec.Logs = activityLogs
 		}
 
+		// Add resource backups recorded by this activity
+		if r.backupCollector != nil {
+			exec.Backups = r.backupCollector.Get(id.String())
+			for i := range exec.Backups {
+				exec.Backups[i].RunID = r.runStatus.ID
+			}
+		}
+
 		executions = append(executions, exec)
 	}
 
@@ -380,17 +653,34 @@ func (r *Runner) buildActivityExecutions() []ActivityExecution {
 	return executions
 }
 
-func (r *Runner) executeRun(ctx context.Context, workflowNames []string) error {
+func (r *Runner) executeRun(ctx context.Context, workflowNames []string, factories []WorkflowFactory, window workflows.Window) error {
 	cfg := r.configProvider.Config()
 	if cfg == nil {
 		return errors.New("no configuration available")
 	}
 
+	r.mu.Lock()
+	runID := r.runStatus.ID
+	r.mu.Unlock()
+
 	// Create status collection for this run
-	statusCollection := activity.NewStatusHandler()
+	statusCollection := activity.NewStatusHandler(activity.WithStatusListener(func(id workflow.ActivityID, status string) {
+		r.publish(events.TypeActivityStatus, runID, events.ActivityStatus{Module: id.Module, Type: id.Type, Status: status})
+	}))
 
 	// Create log collector for this run
-	logCollector := logging.NewLogCollector()
+	logCollector := logging.NewLogCollector(logging.WithLogListener(func(activityID string, entry logging.LogEntry) {
+		r.publish(events.TypeLog, runID, events.Log{
+			Activity:   activityID,
+			Time:       entry.Time,
+			Level:      entry.Level,
+			Message:    entry.Message,
+			Attributes: entry.Attributes,
+		})
+	}))
+
+	// Create backup collector for this run
+	backupCollector := ledger.NewCollector()
 
 	// Create logger factory that captures logs per activity
 	loggerFactory := func(id workflow.ActivityID) *slog.Logger {
@@ -406,10 +696,17 @@ func (r *Runner) executeRun(ctx context.Context, workflowNames []string) error {
 		StatusCollection: statusCollection,
 		LoggerFactory:    loggerFactory,
 		Registry:         r.registry,
+		ResultObserver: func(id workflow.ActivityID, result workflow.Result) {
+			r.publish(events.TypeActivityState, runID, activityState(id, result))
+		},
+		RecorderFactory: func(id workflow.ActivityID) ledger.Recorder {
+			return backupCollector.Recorder(id.String())
+		},
+		Window: window,
 	}
-	for _, name := range workflowNames {
-		factory := r.factories[name] // Already validated in Run()
-		wf, err := factory(params)
+	for i, name := range workflowNames {
+		params.Name = name
+		wf, err := factories[i](params)
 		if err != nil {
 			return fmt.Errorf("failed to create workflow %q: %w", name, err)
 		}
@@ -424,6 +721,7 @@ func (r *Runner) executeRun(ctx context.Context, workflowNames []string) error {
 	r.workflow = composedWorkflow
 	r.statusCollection = statusCollection
 	r.logCollector = logCollector
+	r.backupCollector = backupCollector
 	r.mu.Unlock()
 
 	// Execute composed workflow
@@ -0,0 +1,368 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nomis52/goback/config"
+	"github.com/nomis52/goback/ledger"
+	"github.com/nomis52/goback/notify"
+	"github.com/nomis52/goback/server/events"
+	"github.com/nomis52/goback/workflow"
+	"github.com/nomis52/goback/workflows"
+)
+
+const (
+	testWaitTimeout  = 5 * time.Second
+	testPollInterval = 10 * time.Millisecond
+)
+
+func TestRunner_Cancel(t *testing.T) {
+	blocking := &blockingWorkflow{started: make(chan struct{})}
+	cleanup := &recordingWorkflow{}
+	factories := map[string]WorkflowFactory{
+		"blocking": func(workflows.Params) (workflow.Workflow, error) { return blocking, nil },
+		"cleanup":  func(workflows.Params) (workflow.Workflow, error) { return workflow.IgnoreCancel(cleanup), nil },
+	}
+	r := New(slog.Default(), &mockConfigProvider{}, factories)
+
+	require.NoError(t, r.Run([]string{"blocking", "cleanup"}))
+	<-blocking.started
+
+	status, _ := r.Status()
+	require.NoError(t, r.Cancel(status.ID))
+	// Cancelling twice is a no-op
+	require.NoError(t, r.Cancel(status.ID))
+
+	require.Eventually(t, func() bool { return !r.IsRunning() }, testWaitTimeout, testPollInterval)
+
+	assert.True(t, cleanup.ran.Load(), "cleanup workflow should run after cancellation")
+	assert.False(t, cleanup.cancelled.Load(), "cleanup workflow should see a live context")
+
+	history := r.History()
+	require.Len(t, history, 1)
+	assert.Equal(t, RunStateCancelled, history[0].State)
+	assert.Contains(t, history[0].Error, context.Canceled.Error())
+
+	// A new run can start after a cancelled one
+	blocking.started = make(chan struct{})
+	require.NoError(t, r.Run([]string{"blocking"}))
+	<-blocking.started
+	status, _ = r.Status()
+	require.NoError(t, r.Cancel(status.ID))
+	require.Eventually(t, func() bool { return !r.IsRunning() }, testWaitTimeout, testPollInterval)
+}
+
+func TestRunner_CancelErrors(t *testing.T) {
+	blocking := &blockingWorkflow{started: make(chan struct{})}
+	factories := map[string]WorkflowFactory{
+		"blocking": func(workflows.Params) (workflow.Workflow, error) { return blocking, nil },
+	}
+	r := New(slog.Default(), &mockConfigProvider{}, factories)
+
+	err := r.Cancel("1700000000")
+	assert.True(t, errors.Is(err, ErrNoRunInProgress))
+
+	require.NoError(t, r.Run([]string{"blocking"}))
+	<-blocking.started
+
+	err = r.Cancel("not-a-run")
+	assert.True(t, errors.Is(err, ErrRunNotFound))
+	assert.True(t, r.IsRunning())
+
+	status, _ := r.Status()
+	require.NoError(t, r.Cancel(status.ID))
+	require.Eventually(t, func() bool { return !r.IsRunning() }, testWaitTimeout, testPollInterval)
+}
+
+func TestRunner_RunUntil(t *testing.T) {
+	blocking := &blockingWorkflow{started: make(chan struct{})}
+	cleanup := &recordingWorkflow{}
+	var window workflows.Window
+	factories := map[string]WorkflowFactory{
+		"blocking": func(params workflows.Params) (workflow.Workflow, error) {
+			window = params.Window
+			return blocking, nil
+		},
+		"cleanup": func(workflows.Params) (workflow.Workflow, error) { return workflow.IgnoreCancel(cleanup), nil },
+	}
+	notifier := &mockNotifier{events: make(chan notify.Event, 1)}
+	r := New(slog.Default(), &mockConfigProvider{}, factories, WithDeadlineGrace(10*time.Millisecond), WithNotifier(notifier))
+
+	err := r.RunUntil([]string{"blocking"}, time.Now().Add(-time.Minute))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has already passed")
+	assert.False(t, r.IsRunning())
+
+	deadline := time.Now().Add(10 * time.Millisecond)
+	require.NoError(t, r.RunUntil([]string{"blocking", "cleanup"}, deadline))
+	<-blocking.started
+
+	// The run is cancelled once the deadline and grace period have passed
+	require.Eventually(t, func() bool { return !r.IsRunning() }, testWaitTimeout, testPollInterval)
+
+	assert.Equal(t, deadline, window.Deadline)
+	assert.Equal(t, 10*time.Millisecond, window.Grace)
+	assert.True(t, cleanup.ran.Load(), "cleanup workflow should run after the deadline")
+
+	history := r.History()
+	require.Len(t, history, 1)
+	assert.Equal(t, RunStateDeadlineExceeded, history[0].State, "a run that overruns its deadline is recorded as such, not as cancelled")
+	require.NotNil(t, history[0].Deadline)
+	assert.Equal(t, deadline, *history[0].Deadline)
+	assert.Contains(t, history[0].Error, context.DeadlineExceeded.Error())
+
+	select {
+	case event := <-notifier.events:
+		assert.Equal(t, notify.OutcomeDeadline, event.Outcome)
+	case <-time.After(testWaitTimeout):
+		t.Fatal("timed out waiting for notification")
+	}
+}
+
+func TestRunner_SetFactories(t *testing.T) {
+	before := &recordingWorkflow{}
+	after := &recordingWorkflow{}
+	r := New(slog.Default(), &mockConfigProvider{}, map[string]WorkflowFactory{
+		"before": func(workflows.Params) (workflow.Workflow, error) { return before, nil },
+	})
+
+	r.SetFactories(map[string]WorkflowFactory{
+		"after": func(workflows.Params) (workflow.Workflow, error) { return after, nil },
+	})
+	assert.Equal(t, map[string]bool{"after": true}, r.AvailableWorkflows())
+
+	err := r.Run([]string{"before"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown workflow "before"`)
+
+	require.NoError(t, r.Run([]string{"after"}))
+	require.Eventually(t, func() bool { return !r.IsRunning() }, testWaitTimeout, testPollInterval)
+	assert.True(t, after.ran.Load())
+	assert.False(t, before.ran.Load())
+}
+
+func TestRunner_Notify(t *testing.T) {
+	wf := &resultWorkflow{}
+	factories := map[string]WorkflowFactory{
+		"backup": func(workflows.Params) (workflow.Workflow, error) { return wf, nil },
+	}
+	notifier := &mockNotifier{events: make(chan notify.Event, 1)}
+	r := New(slog.Default(), &mockConfigProvider{}, factories, WithNotifier(notifier))
+
+	runOnce := func(err error) notify.Event {
+		wf.err = err
+		require.NoError(t, r.Run([]string{"backup"}))
+		select {
+		case event := <-notifier.events:
+			require.Eventually(t, func() bool { return !r.IsRunning() }, testWaitTimeout, testPollInterval)
+			return event
+		case <-time.After(testWaitTimeout):
+			t.Fatal("timed out waiting for notification")
+			return notify.Event{}
+		}
+	}
+
+	event := runOnce(errors.New("boom"))
+	assert.Equal(t, notify.OutcomeFailure, event.Outcome)
+	assert.Equal(t, []string{"backup"}, event.Workflows)
+	assert.Contains(t, event.Error, "boom")
+	assert.False(t, event.Recovered)
+
+	event = runOnce(nil)
+	assert.Equal(t, notify.OutcomeSuccess, event.Outcome)
+	assert.True(t, event.Recovered, "first success after a failure is a recovery")
+
+	event = runOnce(nil)
+	assert.Equal(t, notify.OutcomeSuccess, event.Outcome)
+	assert.False(t, event.Recovered)
+}
+
+func TestRunner_Events(t *testing.T) {
+	id := workflow.ActivityID{Module: "backup", Type: "Step"}
+	factories := map[string]WorkflowFactory{
+		"backup": func(params workflows.Params) (workflow.Workflow, error) {
+			return &observedWorkflow{id: id, observer: params.ResultObserver}, nil
+		},
+	}
+	publisher := &mockPublisher{}
+	r := New(slog.Default(), &mockConfigProvider{}, factories, WithEventPublisher(publisher))
+
+	require.NoError(t, r.Run([]string{"backup"}))
+	require.Eventually(t, func() bool { return !r.IsRunning() }, testWaitTimeout, testPollInterval)
+
+	published := publisher.all()
+	require.Len(t, published, 3)
+	status, _ := r.Status()
+
+	assert.Equal(t, events.TypeRunStarted, published[0].Type)
+	assert.Equal(t, events.TypeActivityState, published[1].Type)
+	assert.Equal(t, events.TypeRunFinished, published[2].Type)
+	for _, e := range published {
+		assert.Equal(t, status.ID, e.RunID)
+	}
+
+	state, ok := published[1].Data.(events.ActivityState)
+	require.True(t, ok)
+	assert.Equal(t, "backup", state.Module)
+	assert.Equal(t, "Step", state.Type)
+	assert.Equal(t, workflow.Completed.String(), state.State)
+	assert.NotNil(t, state.EndTime)
+}
+
+func TestRunner_Resources(t *testing.T) {
+	id := workflow.ActivityID{Module: "backup", Type: "BackupVMs"}
+	factories := map[string]WorkflowFactory{
+		"backup": func(params workflows.Params) (workflow.Workflow, error) {
+			return &ledgerWorkflow{id: id, recorder: params.RecorderFactory(id)}, nil
+		},
+	}
+	r := New(slog.Default(), &mockConfigProvider{}, factories)
+
+	require.NoError(t, r.Run([]string{"backup"}))
+	require.Eventually(t, func() bool { return !r.IsRunning() }, testWaitTimeout, testPollInterval)
+	status, executions := r.Status()
+
+	require.Len(t, executions, 1)
+	require.Len(t, executions[0].Backups, 1)
+	assert.Equal(t, status.ID, executions[0].Backups[0].RunID)
+
+	resources := r.Resources()
+	require.Len(t, resources, 1)
+	assert.Equal(t, "compute-100", resources[0].ID)
+	assert.Equal(t, "web", resources[0].Name)
+	assert.False(t, resources[0].Overdue)
+	require.NotNil(t, resources[0].LastSuccess)
+
+	history := r.ResourceHistory("compute-100", 0)
+	require.Len(t, history, 1)
+	assert.Equal(t, status.ID, history[0].RunID)
+}
+
+type mockConfigProvider struct{}
+
+func (m *mockConfigProvider) Config() *config.Config {
+	return &config.Config{}
+}
+
+// blockingWorkflow blocks until its context is cancelled.
+type blockingWorkflow struct {
+	started chan struct{}
+}
+
+func (b *blockingWorkflow) Execute(ctx context.Context) error {
+	close(b.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (b *blockingWorkflow) GetAllResults() map[workflow.ActivityID]*workflow.Result {
+	return nil
+}
+
+// resultWorkflow returns err from Execute.
+type resultWorkflow struct {
+	err error
+}
+
+func (r *resultWorkflow) Execute(ctx context.Context) error {
+	return r.err
+}
+
+func (r *resultWorkflow) GetAllResults() map[workflow.ActivityID]*workflow.Result {
+	return nil
+}
+
+// mockNotifier forwards each event to a channel.
+type mockNotifier struct {
+	events chan notify.Event
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, event notify.Event) error {
+	m.events <- event
+	return nil
+}
+
+// mockPublisher records every published event.
+type mockPublisher struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (m *mockPublisher) Publish(e events.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, e)
+}
+
+func (m *mockPublisher) all() []events.Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]events.Event(nil), m.events...)
+}
+
+// observedWorkflow reports a single completed activity to its observer.
+type observedWorkflow struct {
+	id       workflow.ActivityID
+	observer workflow.ResultObserver
+}
+
+func (o *observedWorkflow) Execute(ctx context.Context) error {
+	now := time.Now()
+	o.observer(o.id, workflow.Result{State: workflow.Completed, StartTime: now, EndTime: now})
+	return nil
+}
+
+func (o *observedWorkflow) GetAllResults() map[workflow.ActivityID]*workflow.Result {
+	return nil
+}
+
+// recordingWorkflow records whether it ran and whether its context was cancelled.
+type recordingWorkflow struct {
+	ran       atomic.Bool
+	cancelled atomic.Bool
+}
+
+func (r *recordingWorkflow) Execute(ctx context.Context) error {
+	r.ran.Store(true)
+	r.cancelled.Store(ctx.Err() != nil)
+	return nil
+}
+
+func (r *recordingWorkflow) GetAllResults() map[workflow.ActivityID]*workflow.Result {
+	return nil
+}
+
+// ledgerWorkflow records a successful backup to the ledger.
+type ledgerWorkflow struct {
+	id       workflow.ActivityID
+	recorder ledger.Recorder
+	result   workflow.Result
+}
+
+func (l *ledgerWorkflow) Execute(ctx context.Context) error {
+	now := time.Now()
+	l.recorder.Record(ledger.Entry{
+		ResourceID:   ledger.ComputeID(100),
+		Kind:         ledger.KindCompute,
+		Name:         "web",
+		StartedAt:    now,
+		EndedAt:      now,
+		Outcome:      notify.OutcomeSuccess,
+		MaxBackupAge: time.Hour,
+	})
+	l.result = workflow.Result{State: workflow.Completed, StartTime: now, EndTime: now}
+	return nil
+}
+
+func (l *ledgerWorkflow) GetAllResults() map[workflow.ActivityID]*workflow.Result {
+	return map[workflow.ActivityID]*workflow.Result{l.id: &l.result}
+}
@@ -1,11 +1,169 @@
 package runner
 
+import (
+	"sort"
+	"time"
+
+	"github.com/nomis52/goback/ledger"
+	"github.com/nomis52/goback/notify"
+)
+
 // StateStore manages persistence of run history.
 type StateStore interface {
 	// History returns all loaded runs as summaries.
 	History() []RunSummary
+	// Query returns the runs matching the query, most recent first.
+	Query(HistoryQuery) HistoryPage
 	// Logs returns the activity executions for a specific run.
 	Logs(string) []ActivityExecution
 	// Save persists a run.
 	Save(RunSummary, []ActivityExecution) error
+	// Resources returns the latest backups of each resource recorded in the stored runs,
+	// ordered by kind and name.
+	Resources() []ResourceBackups
+	// ResourceHistory returns up to limit backups of a resource, most recent first.
+	// A limit of 0 returns all of them.
+	ResourceHistory(id string, limit int) []ledger.Entry
+}
+
+// HistoryQuery filters and pages the run history. Zero values match everything.
+type HistoryQuery struct {
+	// Workflow matches runs that included the named workflow.
+	Workflow string
+	// Outcome matches runs that finished with the given outcome.
+	Outcome notify.Outcome
+	// Since matches runs that started at or after this time.
+	Since time.Time
+	// Until matches runs that started before this time.
+	Until time.Time
+	// Offset is the number of matching runs to skip.
+	Offset int
+	// Limit is the maximum number of runs to return. Zero means no limit.
+	Limit int
+}
+
+// HistoryPage is one page of query results.
+type HistoryPage struct {
+	// Runs are the matching runs on this page, most recent first.
+	Runs []RunSummary
+	// Total is the number of runs that matched the query, across all pages.
+	Total int
+}
+
+// matches returns true if the run satisfies the query's filters.
+func (q HistoryQuery) matches(summary RunSummary) bool {
+	if q.Workflow != "" && !containsWorkflow(summary.Workflows, q.Workflow) {
+		return false
+	}
+	if q.Outcome != "" && outcome(summary) != q.Outcome {
+		return false
+	}
+	if !q.Since.IsZero() && (summary.StartedAt == nil || summary.StartedAt.Before(q.Since)) {
+		return false
+	}
+	if !q.Until.IsZero() && (summary.StartedAt == nil || !summary.StartedAt.Before(q.Until)) {
+		return false
+	}
+	return true
+}
+
+// page returns the window of runs selected by Offset and Limit.
+func (q HistoryQuery) page(runs []RunSummary) []RunSummary {
+	if q.Offset >= len(runs) {
+		return []RunSummary{}
+	}
+	runs = runs[q.Offset:]
+	if q.Limit > 0 && len(runs) > q.Limit {
+		runs = runs[:q.Limit]
+	}
+	return runs
+}
+
+// queryHistory applies a query to summaries that are already ordered most recent first.
+func queryHistory(summaries []RunSummary, q HistoryQuery) HistoryPage {
+	matched := make([]RunSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		if q.matches(summary) {
+			matched = append(matched, summary)
+		}
+	}
+
+	runs := q.page(matched)
+	result := make([]RunSummary, len(runs))
+	copy(result, runs)
+	return HistoryPage{Runs: result, Total: len(matched)}
+}
+
+func containsWorkflow(workflows []string, name string) bool {
+	for _, w := range workflows {
+		if w == name {
+			return true
+		}
+	}
+	return false
+}
+
+// runBackups returns the backups recorded in runs, most recent first.
+func runBackups(summaries []RunSummary, logs map[string][]ActivityExecution) []ledger.Entry {
+	var entries []ledger.Entry
+	for _, summary := range summaries {
+		for _, exec := range logs[summary.ID] {
+			entries = append(entries, exec.Backups...)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedAt.After(entries[j].StartedAt)
+	})
+	return entries
+}
+
+// latestBackups finds the latest backups of each resource in backups that are ordered
+// most recent first.
+func latestBackups(entries []ledger.Entry) []ResourceBackups {
+	index := make(map[string]int)
+	result := []ResourceBackups{}
+	for _, entry := range entries {
+		i, ok := index[entry.ResourceID]
+		if !ok {
+			i = len(result)
+			index[entry.ResourceID] = i
+			result = append(result, ResourceBackups{Latest: entry})
+		}
+		if result[i].LastSuccess == nil && entry.Succeeded() {
+			success := entry
+			result[i].LastSuccess = &success
+		}
+	}
+	sortResources(result)
+	return result
+}
+
+// sortResources orders resources by kind, then name.
+func sortResources(resources []ResourceBackups) {
+	sort.Slice(resources, func(i, j int) bool {
+		a, b := resources[i].Latest, resources[j].Latest
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ResourceID < b.ResourceID
+	})
+}
+
+// resourceHistory returns up to limit of the backups of a resource, from backups that are
+// ordered most recent first.
+func resourceHistory(entries []ledger.Entry, id string, limit int) []ledger.Entry {
+	result := []ledger.Entry{}
+	for _, entry := range entries {
+		if entry.ResourceID != id {
+			continue
+		}
+		result = append(result, entry)
+		if limit > 0 && len(result) == limit {
+			break
+		}
+	}
+	return result
 }
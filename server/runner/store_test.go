@@ -0,0 +1,242 @@
+package runner
+
+import (
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nomis52/goback/ledger"
+	"github.com/nomis52/goback/notify"
+)
+
+// queryRuns returns a history of runs, oldest first, for the query tests.
+func queryRuns() []RunSummary {
+	day := func(d int) *time.Time {
+		t := time.Date(2026, 3, d, 4, 5, 0, 0, time.UTC)
+		return &t
+	}
+	return []RunSummary{
+		{ID: "1", Workflows: []string{"backup", "poweroff"}, StartedAt: day(1)},
+		{ID: "2", Workflows: []string{"backup"}, StartedAt: day(2), Error: "pbs unreachable"},
+		{ID: "3", Workflows: []string{"maintenance"}, StartedAt: day(3)},
+		{ID: "4", Workflows: []string{"backup", "poweroff"}, StartedAt: day(4), State: RunStateCancelled, Error: "context canceled"},
+		{ID: "5", Workflows: []string{"backup", "poweroff"}, StartedAt: day(5)},
+	}
+}
+
+// testStores returns constructors for each StateStore implementation.
+func testStores() map[string]func(t *testing.T) StateStore {
+	return map[string]func(t *testing.T) StateStore{
+		"memory": func(t *testing.T) StateStore {
+			return NewMemoryStore()
+		},
+		"disk": func(t *testing.T) StateStore {
+			store, err := NewDiskStore(t.TempDir(), 10, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			require.NoError(t, err)
+			return store
+		},
+		"bolt": func(t *testing.T) StateStore {
+			store, err := NewBoltStore(filepath.Join(t.TempDir(), "history.db"), 10, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			require.NoError(t, err)
+			t.Cleanup(func() { store.Close() })
+			return store
+		},
+	}
+}
+
+func TestStateStore_Query(t *testing.T) {
+	march := func(d int) time.Time {
+		return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name      string
+		query     HistoryQuery
+		wantIDs   []string
+		wantTotal int
+	}{
+		{
+			name:      "everything",
+			query:     HistoryQuery{},
+			wantIDs:   []string{"5", "4", "3", "2", "1"},
+			wantTotal: 5,
+		},
+		{
+			name:      "first page",
+			query:     HistoryQuery{Limit: 2},
+			wantIDs:   []string{"5", "4"},
+			wantTotal: 5,
+		},
+		{
+			name:      "last page",
+			query:     HistoryQuery{Offset: 4, Limit: 2},
+			wantIDs:   []string{"1"},
+			wantTotal: 5,
+		},
+		{
+			name:      "past the end",
+			query:     HistoryQuery{Offset: 10},
+			wantIDs:   []string{},
+			wantTotal: 5,
+		},
+		{
+			name:      "workflow",
+			query:     HistoryQuery{Workflow: "poweroff"},
+			wantIDs:   []string{"5", "4", "1"},
+			wantTotal: 3,
+		},
+		{
+			name:      "outcome",
+			query:     HistoryQuery{Outcome: notify.OutcomeSuccess},
+			wantIDs:   []string{"5", "3", "1"},
+			wantTotal: 3,
+		},
+		{
+			name:      "failures exclude cancelled runs",
+			query:     HistoryQuery{Outcome: notify.OutcomeFailure},
+			wantIDs:   []string{"2"},
+			wantTotal: 1,
+		},
+		{
+			name:      "date range",
+			query:     HistoryQuery{Since: march(2), Until: march(4)},
+			wantIDs:   []string{"3", "2"},
+			wantTotal: 2,
+		},
+		{
+			name:      "since",
+			query:     HistoryQuery{Since: march(4)},
+			wantIDs:   []string{"5", "4"},
+			wantTotal: 2,
+		},
+		{
+			name:      "until",
+			query:     HistoryQuery{Until: march(2)},
+			wantIDs:   []string{"1"},
+			wantTotal: 1,
+		},
+		{
+			name:      "workflow, outcome and page",
+			query:     HistoryQuery{Workflow: "backup", Outcome: notify.OutcomeSuccess, Limit: 1},
+			wantIDs:   []string{"5"},
+			wantTotal: 2,
+		},
+		{
+			name:      "workflow and date range",
+			query:     HistoryQuery{Workflow: "backup", Since: march(2), Until: march(5)},
+			wantIDs:   []string{"4", "2"},
+			wantTotal: 2,
+		},
+		{
+			name:      "unknown workflow",
+			query:     HistoryQuery{Workflow: "demo"},
+			wantIDs:   []string{},
+			wantTotal: 0,
+		},
+	}
+
+	for storeName, newStore := range testStores() {
+		t.Run(storeName, func(t *testing.T) {
+			store := newStore(t)
+			for _, run := range queryRuns() {
+				require.NoError(t, store.Save(run, nil))
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					page := store.Query(tt.query)
+
+					ids := []string{}
+					for _, run := range page.Runs {
+						ids = append(ids, run.ID)
+					}
+					assert.Equal(t, tt.wantIDs, ids)
+					assert.Equal(t, tt.wantTotal, page.Total)
+				})
+			}
+		})
+	}
+}
+
+func TestStateStore_Resources(t *testing.T) {
+	at := func(d int) time.Time {
+		return time.Date(2026, 3, d, 4, 5, 0, 0, time.UTC)
+	}
+	backup := func(id, name string, d int, outcome notify.Outcome) ledger.Entry {
+		return ledger.Entry{
+			ResourceID: id,
+			Kind:       ledger.KindCompute,
+			Name:       name,
+			StartedAt:  at(d),
+			EndedAt:    at(d).Add(time.Minute),
+			Outcome:    outcome,
+		}
+	}
+	files := ledger.Entry{
+		ResourceID: "files-home",
+		Kind:       ledger.KindFiles,
+		Name:       "home",
+		StartedAt:  at(2),
+		EndedAt:    at(2).Add(time.Minute),
+		Outcome:    notify.OutcomeSuccess,
+	}
+
+	runs := []struct {
+		day     int
+		backups []ledger.Entry
+	}{
+		{day: 1, backups: []ledger.Entry{
+			backup("compute-100", "web", 1, notify.OutcomeSuccess),
+			backup("compute-101", "db", 1, notify.OutcomeSuccess),
+		}},
+		{day: 2, backups: []ledger.Entry{
+			backup("compute-101", "db", 2, notify.OutcomeFailure),
+		}},
+		{day: 3, backups: []ledger.Entry{
+			backup("compute-101", "db", 3, notify.OutcomeFailure),
+			backup("compute-102", "mail", 3, notify.OutcomeFailure),
+		}},
+	}
+
+	for storeName, newStore := range testStores() {
+		t.Run(storeName, func(t *testing.T) {
+			store := newStore(t)
+			for _, run := range runs {
+				started := at(run.day)
+				executions := []ActivityExecution{{Module: "backup", Type: "BackupVMs", Backups: run.backups}}
+				if run.day == 2 {
+					executions = append(executions, ActivityExecution{Module: "backup", Type: "BackupDirs", Backups: []ledger.Entry{files}})
+				}
+				require.NoError(t, store.Save(RunSummary{Workflows: []string{"backup"}, StartedAt: &started}, executions))
+			}
+
+			resources := store.Resources()
+			var ids []string
+			for _, r := range resources {
+				ids = append(ids, r.Latest.ResourceID)
+			}
+			assert.Equal(t, []string{"compute-101", "compute-102", "compute-100", "files-home"}, ids, "ordered by kind and name")
+
+			db := resources[0]
+			assert.True(t, at(3).Equal(db.Latest.StartedAt))
+			assert.Equal(t, notify.OutcomeFailure, db.Latest.Outcome)
+			require.NotNil(t, db.LastSuccess)
+			assert.True(t, at(1).Equal(db.LastSuccess.StartedAt))
+			assert.Nil(t, resources[1].LastSuccess, "mail has never been backed up successfully")
+
+			history := store.ResourceHistory("compute-101", 0)
+			require.Len(t, history, 3)
+			for i, d := range []int{3, 2, 1} {
+				assert.True(t, at(d).Equal(history[i].StartedAt))
+			}
+			assert.Len(t, store.ResourceHistory("compute-101", 2), 2)
+			assert.Empty(t, store.ResourceHistory("compute-999", 0))
+			assert.Empty(t, store.ResourceHistory("compute-10", 0), "IDs must match exactly")
+		})
+	}
+}
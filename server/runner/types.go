@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nomis52/goback/ledger"
 	"github.com/nomis52/goback/logging"
 	"github.com/nomis52/goback/workflow"
 	"github.com/nomis52/goback/workflows"
@@ -22,6 +23,11 @@ const (
 	RunStateIdle RunState = iota
 	// RunStateRunning indicates a backup is in progress.
 	RunStateRunning
+	// RunStateCancelled indicates the last run was cancelled before it completed.
+	RunStateCancelled
+	// RunStateDeadlineExceeded indicates the last run was still going when its deadline and
+	// grace period passed, so it was stopped.
+	RunStateDeadlineExceeded
 )
 
 // String returns the string representation of the run state.
@@ -31,6 +37,10 @@ func (s RunState) String() string {
 		return "idle"
 	case RunStateRunning:
 		return "running"
+	case RunStateCancelled:
+		return "cancelled"
+	case RunStateDeadlineExceeded:
+		return "deadline_exceeded"
 	default:
 		return "unknown"
 	}
@@ -54,6 +64,10 @@ func (s *RunState) UnmarshalJSON(data []byte) error {
 		*s = RunStateIdle
 	case "running":
 		*s = RunStateRunning
+	case "cancelled":
+		*s = RunStateCancelled
+	case "deadline_exceeded":
+		*s = RunStateDeadlineExceeded
 	default:
 		*s = RunStateIdle
 	}
@@ -72,6 +86,8 @@ type RunSummary struct {
 	StartedAt *time.Time `json:"started_at,omitempty"`
 	// EndedAt is when the run ended. Nil if run is in progress or no run has occurred.
 	EndedAt *time.Time `json:"ended_at,omitempty"`
+	// Deadline is when the run's backup window closed. Nil if the run had no deadline.
+	Deadline *time.Time `json:"deadline,omitempty"`
 	// Error contains the error message if the run failed. Empty on success.
 	Error string `json:"error,omitempty"`
 }
@@ -106,10 +122,60 @@ type ActivityExecution struct {
 	Status string `json:"status,omitempty"`
 	// Error contains the error message if the activity failed. Empty on success.
 	Error string `json:"error,omitempty"`
+	// TimedOut is true if the activity failed because it exceeded its timeout.
+	TimedOut bool `json:"timed_out,omitempty"`
 	// StartTime is when the activity started execution.
 	StartTime *time.Time `json:"start_time,omitempty"`
 	// EndTime is when the activity completed execution.
 	EndTime *time.Time `json:"end_time,omitempty"`
+	// Attempts is the number of times the activity was executed, including retries.
+	Attempts int `json:"attempts,omitempty"`
 	// Logs contains all log entries captured from this activity.
 	Logs []logging.LogEntry `json:"logs,omitempty"`
+	// Backups contains the resource backups recorded by this activity.
+	Backups []ledger.Entry `json:"backups,omitempty"`
+	// Outputs contains the values the activity published for its dependents, keyed by name.
+	Outputs map[string]any `json:"outputs,omitempty"`
+}
+
+// ResourceBackups is the most recent backup of a resource, and its most recent successful backup.
+type ResourceBackups struct {
+	Latest      ledger.Entry
+	LastSuccess *ledger.Entry
+}
+
+// ResourceStatus describes when a resource was last backed up, and whether that was too long ago.
+type ResourceStatus struct {
+	ID   string      `json:"id"`
+	Kind ledger.Kind `json:"kind"`
+	Name string      `json:"name"`
+	Node string      `json:"node,omitempty"`
+	// LastBackup is the most recent backup of the resource, successful or not.
+	LastBackup ledger.Entry `json:"last_backup"`
+	// LastSuccess is the most recent successful backup. Nil if no backup has succeeded.
+	LastSuccess *ledger.Entry `json:"last_success,omitempty"`
+	// MaxBackupAge is the resource's max_backup_age, e.g. "24h0m0s". Empty if backups
+	// of the resource have no maximum age.
+	MaxBackupAge string `json:"max_backup_age,omitempty"`
+	// Overdue is true if the last successful backup is older than MaxBackupAge.
+	Overdue bool `json:"overdue"`
+}
+
+// newResourceStatus builds the status of a resource at the time now.
+// The maximum age is taken from the most recent backup, so it reflects the current config.
+func newResourceStatus(backups ResourceBackups, now time.Time) ResourceStatus {
+	latest := backups.Latest
+	status := ResourceStatus{
+		ID:          latest.ResourceID,
+		Kind:        latest.Kind,
+		Name:        latest.Name,
+		Node:        latest.Node,
+		LastBackup:  latest,
+		LastSuccess: backups.LastSuccess,
+	}
+	if maxAge := latest.MaxBackupAge; maxAge > 0 {
+		status.MaxBackupAge = maxAge.String()
+		status.Overdue = backups.LastSuccess == nil || now.Sub(backups.LastSuccess.EndedAt) > maxAge
+	}
+	return status
 }
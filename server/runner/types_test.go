@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nomis52/goback/ledger"
+	"github.com/nomis52/goback/notify"
+)
+
+func TestNewResourceStatus(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	entry := func(ago time.Duration, outcome notify.Outcome, maxAge time.Duration) ledger.Entry {
+		return ledger.Entry{
+			ResourceID:   "compute-100",
+			Kind:         ledger.KindCompute,
+			Name:         "web",
+			Node:         "pve1",
+			StartedAt:    now.Add(-ago - time.Minute),
+			EndedAt:      now.Add(-ago),
+			Outcome:      outcome,
+			MaxBackupAge: maxAge,
+		}
+	}
+	success := func(ago time.Duration) *ledger.Entry {
+		e := entry(ago, notify.OutcomeSuccess, 24*time.Hour)
+		return &e
+	}
+
+	tests := []struct {
+		name        string
+		backups     ResourceBackups
+		wantMaxAge  string
+		wantOverdue bool
+	}{
+		{
+			name:       "recent success",
+			backups:    ResourceBackups{Latest: *success(time.Hour), LastSuccess: success(time.Hour)},
+			wantMaxAge: "24h0m0s",
+		},
+		{
+			name: "failed since a recent success",
+			backups: ResourceBackups{
+				Latest:      entry(time.Hour, notify.OutcomeFailure, 24*time.Hour),
+				LastSuccess: success(20 * time.Hour),
+			},
+			wantMaxAge: "24h0m0s",
+		},
+		{
+			name: "last success too old",
+			backups: ResourceBackups{
+				Latest:      entry(time.Hour, notify.OutcomeFailure, 24*time.Hour),
+				LastSuccess: success(25 * time.Hour),
+			},
+			wantMaxAge:  "24h0m0s",
+			wantOverdue: true,
+		},
+		{
+			name:        "never succeeded",
+			backups:     ResourceBackups{Latest: entry(time.Hour, notify.OutcomeFailure, 24*time.Hour)},
+			wantMaxAge:  "24h0m0s",
+			wantOverdue: true,
+		},
+		{
+			name:    "no maximum age",
+			backups: ResourceBackups{Latest: entry(time.Hour, notify.OutcomeFailure, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := newResourceStatus(tt.backups, now)
+			assert.Equal(t, "compute-100", status.ID)
+			assert.Equal(t, ledger.KindCompute, status.Kind)
+			assert.Equal(t, "web", status.Name)
+			assert.Equal(t, "pve1", status.Node)
+			assert.Equal(t, tt.backups.Latest, status.LastBackup)
+			assert.Equal(t, tt.backups.LastSuccess, status.LastSuccess)
+			assert.Equal(t, tt.wantMaxAge, status.MaxBackupAge)
+			assert.Equal(t, tt.wantOverdue, status.Overdue)
+		})
+	}
+}
@@ -1,7 +1,7 @@
 // Package server provides an HTTP server for the goback backup automation system.
 //
 // The server exposes a REST API to monitor and control PBS backup operations,
-// including checking power state via IPMI, triggering backup runs, and viewing
+// including checking the PBS power state, triggering backup runs, and viewing
 // run history.
 //
 // # Endpoints
@@ -9,17 +9,21 @@
 //   - GET / - Web UI dashboard
 //   - GET /health - Simple health check, returns "ok"
 //   - GET /api/status - Consolidated status endpoint (PBS state, run status, next run, results)
-//   - GET /api/history - Returns history of completed runs
+//   - GET /api/history - Returns history of completed runs, filtered by workflow, status and date range, with pagination
+//   - GET /api/resources - Returns when each VM, container and file job was last backed up, flagging overdue ones
+//   - GET /api/resources/{id}/history - Returns the backups of a single resource
 //   - GET /config - Returns current configuration as YAML
 //   - POST /reload - Reloads configuration from disk
 //   - POST /run - Triggers a backup run
+//   - POST /api/runs/{id}/cancel - Cancels the in-flight run with the given ID
+//   - GET /api/events - Server-sent event stream of live run progress
 //
 // # Architecture
 //
 // The server maintains two sets of dependencies:
 //
 // Server-level deps are swapped atomically on reload and include the config
-// and IPMI controller used by the /ipmi endpoint.
+// and the PBS power controller used by the /api/status endpoint.
 //
 // Run-level deps are created fresh for each backup run from the current config,
 // ensuring configuration changes take effect on the next run without interrupting
@@ -42,27 +46,34 @@ import (
 	"embed"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
 	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/nomis52/goback/buildinfo"
-	"github.com/nomis52/goback/clients/ipmiclient"
 	"github.com/nomis52/goback/config"
 	"github.com/nomis52/goback/metrics"
+	"github.com/nomis52/goback/notify"
+	"github.com/nomis52/goback/power"
 	serverconfig "github.com/nomis52/goback/server/config"
 	"github.com/nomis52/goback/server/cron"
+	"github.com/nomis52/goback/server/events"
 	"github.com/nomis52/goback/server/handlers"
 	"github.com/nomis52/goback/server/runner"
 	"github.com/nomis52/goback/workflow"
 	"github.com/nomis52/goback/workflows/backup"
+	"github.com/nomis52/goback/workflows/custom"
 	"github.com/nomis52/goback/workflows/demo"
+	"github.com/nomis52/goback/workflows/maintenance"
 	"github.com/nomis52/goback/workflows/poweroff"
+	"github.com/nomis52/goback/workflows/restoretest"
 )
 
 //go:embed static
@@ -73,21 +84,40 @@ const (
 	defaultWriteTimeout    = 10 * time.Second
 	defaultShutdownTimeout = 5 * time.Second
 	defaultListenAddr      = ":8080"
+
+	// historyDBFile is the name of the history database in the state directory.
+	historyDBFile = "history.db"
 )
 
-// defaultWorkflowFactories returns the standard workflow factories for backup, poweroff, and demo workflows.
+// defaultWorkflowFactories returns the standard workflow factories for backup, backup_poweroff, maintenance, restoretest, poweroff, and demo workflows.
 func defaultWorkflowFactories() map[string]runner.WorkflowFactory {
 	return map[string]runner.WorkflowFactory{
-		"backup":   backup.NewWorkflow,
-		"poweroff": poweroff.NewWorkflow,
-		"demo":     demo.NewWorkflow,
+		"backup":          backup.NewWorkflow,
+		"backup_poweroff": backup.NewWorkflowWithPowerOff,
+		"maintenance":     maintenance.NewWorkflow,
+		"restoretest":     restoretest.NewWorkflow,
+		"poweroff":        poweroff.NewWorkflow,
+		"demo":            demo.NewWorkflow,
+	}
+}
+
+// addDeclaredWorkflows registers the workflows declared in the config with factories.
+// A declared workflow cannot replace a built-in one.
+func addDeclaredWorkflows(factories map[string]runner.WorkflowFactory, declared []config.WorkflowConfig) error {
+	for _, w := range declared {
+		if _, exists := factories[w.Name]; exists {
+			return fmt.Errorf("workflow %q is already defined", w.Name)
+		}
+		factories[w.Name] = custom.NewWorkflow
 	}
+	return nil
 }
 
 // serverDeps holds config-derived dependencies that are swapped atomically on reload.
 type serverDeps struct {
-	config         *config.Config
-	ipmiController *ipmiclient.IPMIController
+	config          *config.Config
+	powerController power.PowerController
+	notifier        *notify.Notifier
 }
 
 // Server is the HTTP server for the goback web interface.
@@ -100,9 +130,10 @@ type Server struct {
 	deps            atomic.Pointer[serverDeps]
 	httpServer      *http.Server
 	runner          *runner.Runner
-	store           *runner.DiskStore
+	store           runner.StateStore
 	cronTrigger     *cron.CronTriggerManager
 	cronConfig      []serverconfig.CronTrigger
+	events          *events.Broker
 	tlsCert    string
 	tlsKey     string
 	properties ServerProperties
@@ -197,6 +228,7 @@ func New(cfg *serverconfig.ServerConfig, opts ...Option) (*Server, error) {
 		},
 		metricsRegistry: metricsRegistry,
 		staticFS:        staticFS,
+		events:          events.NewBroker(),
 	}
 
 	// Apply options
@@ -211,16 +243,23 @@ func New(cfg *serverconfig.ServerConfig, opts ...Option) (*Server, error) {
 	// Create runner with optional disk store and metrics
 	runnerOpts := []runner.Option{
 		runner.WithMetricsRegistry(metricsRegistry),
+		runner.WithNotifier(s),
+		runner.WithEventPublisher(s.events),
+		runner.WithDeadlineGrace(cfg.DeadlineGrace),
 	}
 	if s.stateDir != "" {
-		store, err := runner.NewDiskStore(s.stateDir, 100, logger)
+		store, err := newStateStore(s.stateDir, cfg.HistoryStore, cfg.HistorySize, logger)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create disk store: %w", err)
+			return nil, err
 		}
 		s.store = store
 		runnerOpts = append(runnerOpts, runner.WithStateStore(store))
 	}
-	s.runner = runner.New(logger, s, defaultWorkflowFactories(), runnerOpts...)
+	factories := defaultWorkflowFactories()
+	if err := addDeclaredWorkflows(factories, s.Config().Workflows); err != nil {
+		return nil, fmt.Errorf("registering declared workflows: %w", err)
+	}
+	s.runner = runner.New(logger, s, factories, runnerOpts...)
 
 	// Initialize cron triggers if configured
 	if len(s.cronConfig) > 0 {
@@ -255,17 +294,33 @@ func (s *Server) Reload() error {
 		return err
 	}
 
-	ctrl := ipmiclient.NewIPMIController(
-		cfg.PBS.IPMI.Host,
-		ipmiclient.WithUsername(cfg.PBS.IPMI.Username),
-		ipmiclient.WithPassword(cfg.PBS.IPMI.Password),
-		ipmiclient.WithLogger(s.logger),
-	)
+	ctrl, err := power.New(cfg.PBS, s.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create power controller: %w", err)
+	}
+
+	notifier, err := notify.New(cfg.Notify, s.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create notifier: %w", err)
+	}
+
+	factories := defaultWorkflowFactories()
+	if err := addDeclaredWorkflows(factories, cfg.Workflows); err != nil {
+		return err
+	}
+	if err := custom.Validate(&cfg, s.logger); err != nil {
+		return fmt.Errorf("invalid declared workflow: %w", err)
+	}
 
 	s.deps.Store(&serverDeps{
-		config:         &cfg,
-		ipmiController: ctrl,
+		config:          &cfg,
+		powerController: ctrl,
+		notifier:        notifier,
 	})
+	// Workflows added to or removed from the config are available on the next run
+	if s.runner != nil {
+		s.runner.SetFactories(factories)
+	}
 
 	s.logger.Info("configuration loaded", "config_path", s.configPath)
 
@@ -277,9 +332,14 @@ func (s *Server) Config() *config.Config {
 	return s.deps.Load().config
 }
 
-// IPMIController returns the current IPMI controller.
-func (s *Server) IPMIController() *ipmiclient.IPMIController {
-	return s.deps.Load().ipmiController
+// PowerController returns the current PBS power controller.
+func (s *Server) PowerController() power.PowerController {
+	return s.deps.Load().powerController
+}
+
+// Notify sends a run notification using the sinks from the current configuration.
+func (s *Server) Notify(ctx context.Context, event notify.Event) error {
+	return s.deps.Load().notifier.Notify(ctx, event)
 }
 
 // Properties returns the server properties (build info, start time, hostname).
@@ -394,7 +454,47 @@ func (s *Server) Run(ctx context.Context) error {
 		s.logger.Info("shutting down server")
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
 		defer cancel()
-		return s.httpServer.Shutdown(shutdownCtx)
+		err := s.httpServer.Shutdown(shutdownCtx)
+		if closer, ok := s.store.(io.Closer); ok {
+			if closeErr := closer.Close(); closeErr != nil {
+				s.logger.Error("failed to close history store", "error", closeErr)
+			}
+		}
+		return err
+	}
+}
+
+// newStateStore creates the run history store in stateDir, keeping the last size runs.
+// Runs saved as JSON files are imported the first time the database is used.
+func newStateStore(stateDir, kind string, size int, logger *slog.Logger) (runner.StateStore, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid history_size %d, must be positive", size)
+	}
+
+	switch kind {
+	case serverconfig.HistoryStoreJSON, "":
+		store, err := runner.NewDiskStore(stateDir, size, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create disk store: %w", err)
+		}
+		return store, nil
+	case serverconfig.HistoryStoreDB:
+		if err := os.MkdirAll(stateDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create state directory: %w", err)
+		}
+		store, err := runner.NewBoltStore(filepath.Join(stateDir, historyDBFile), size, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create history database: %w", err)
+		}
+		imported, err := store.ImportDiskStore(stateDir)
+		if err != nil {
+			logger.Warn("failed to import run history", "error", err)
+		} else if imported > 0 {
+			logger.Info("imported run history into database", "count", imported)
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("invalid history_store %q, must be %q or %q", kind, serverconfig.HistoryStoreDB, serverconfig.HistoryStoreJSON)
 	}
 }
 
@@ -402,24 +502,32 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	configHandler := handlers.NewConfigHandler(s)
 	reloadHandler := handlers.NewReloadHandler(s.logger, s)
 	runHandler := handlers.NewRunHandler(s.runner)
+	cancelHandler := handlers.NewCancelHandler(s.runner)
 	historyHandler := handlers.NewHistoryHandler(s.runner)
 	historyLogsHandler := handlers.NewHistoryLogsHandler(s.runner)
+	resourcesHandler := handlers.NewResourcesHandler(s.runner)
+	resourceHistoryHandler := handlers.NewResourceHistoryHandler(s.runner)
 	apiStatusHandler := handlers.NewAPIStatusHandler(s.logger, s)
 	availableWorkflowsHandler := handlers.NewAvailableWorkflowsHandler(s.runner)
+	eventsHandler := handlers.NewEventsHandler(s.logger, s.events)
 
 	// API endpoints
 	mux.HandleFunc("GET /health", handlers.HandleHealth)
 	mux.Handle("GET /api/status", apiStatusHandler)
 	mux.Handle("GET /api/history", historyHandler)
 	mux.Handle("GET /api/history/logs", historyLogsHandler)
+	mux.Handle("GET /api/resources", resourcesHandler)
+	mux.Handle("GET /api/resources/{id}/history", resourceHistoryHandler)
 	mux.Handle("GET /api/workflows", availableWorkflowsHandler)
-	if s.store != nil {
-		storeReloadHandler := handlers.NewStoreReloadHandler(s.logger, s.store)
+	mux.Handle("GET /api/events", eventsHandler)
+	if store, ok := s.store.(handlers.ReloadableStore); ok {
+		storeReloadHandler := handlers.NewStoreReloadHandler(s.logger, store)
 		mux.Handle("POST /api/store_reload", storeReloadHandler)
 	}
 	mux.Handle("GET /config", configHandler)
 	mux.Handle("POST /reload", reloadHandler)
 	mux.Handle("POST /run", runHandler)
+	mux.Handle("POST /api/runs/{id}/cancel", cancelHandler)
 
 	// Prometheus metrics endpoint
 	mux.Handle("GET /metrics", s.metricsRegistry.Handler())
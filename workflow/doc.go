@@ -55,6 +55,13 @@
 //	    _         *BackupServiceActivity  // Unnamed - ordering only
 //	}
 //
+// Added Dependencies (Ordering Only, without a field):
+//
+//	err := o.AddDependency(verifyActivity, cleanupActivity) // verify runs after cleanup
+//
+// Validate checks the dependency graph without executing anything, so workflows assembled
+// at runtime, such as from a config file, can be rejected when they are loaded.
+//
 // # Configuration Injection
 //
 // Use struct tags for configuration injection:
@@ -112,6 +119,73 @@
 // The Result.Error field contains ONLY errors returned by the activity's Execute() method.
 // Validation errors, dependency failures, and cancellations are reflected in State only.
 //
+// # Retries
+//
+// Activities may implement the optional Retryable interface to have failed executions
+// retried with exponential backoff:
+//
+//	func (a *PowerOnPBS) RetryPolicy() workflow.RetryPolicy {
+//	    return workflow.RetryPolicy{
+//	        MaxAttempts:    3,
+//	        InitialBackoff: 30 * time.Second,
+//	        MaxBackoff:     2 * time.Minute,
+//	    }
+//	}
+//
+// The activity stays in Running state between attempts and Result.Attempts records how
+// many times Execute() was called. Only the error from the final attempt is stored in
+// Result.Error, and dependents are skipped only once all attempts have failed.
+// Retries stop as soon as the context is cancelled.
+//
+// # Timeouts
+//
+// Activities may implement the optional TimeLimited interface to bound how long Execute()
+// may run. The orchestrator passes Execute() a context with the timeout applied, so the
+// activity only needs to return once ctx is done:
+//
+//	func (a *PowerOnPBS) Timeout() time.Duration {
+//	    return a.BootTimeout + a.ServiceWaitTime
+//	}
+//
+// For Retryable activities the timeout applies to each attempt. When an attempt times out,
+// the error in Result.Error wraps ErrTimeout and Result.TimedOut() reports true, so timeouts
+// can be reported separately from other failures. Cancelling the parent context is not a timeout.
+//
+// # Finalizers
+//
+// Activities may implement the optional Finalizer interface to run once their dependencies
+// have finished, however they finished. Finalize() receives the dependencies' results
+// before Execute() is called:
+//
+//	type PowerOffPBS struct {
+//	    Controller power.PowerController
+//	}
+//
+//	func (a *PowerOffPBS) Finalize(dependencies map[workflow.ActivityID]workflow.Result) {}
+//
+// A finalizer is never Skipped, and its Execute() receives a context that is never cancelled.
+// A finalizer that declares no dependencies runs after every activity that is not a finalizer,
+// which makes it a guaranteed cleanup step for the whole workflow.
+//
+// # Outputs
+//
+// Activities share data with their dependents through exported Output fields:
+//
+//	type BackupVMs struct {
+//	    Backups workflow.Output[[]CompletedBackup] // Set in Execute()
+//	}
+//
+//	type VerifyBackups struct {
+//	    BackupVMs *BackupVMs // backups, ok := a.BackupVMs.Backups.Get()
+//	}
+//
+// A dependent only runs after the activity finished, so it always sees the final value.
+// Dependents are skipped if the activity fails, unless the dependency field is tagged
+// `workflow:"tolerate_failure"`, in which case they run with whatever outputs were set
+// before the failure.
+// When an activity transitions to Completed, the outputs it set are copied into
+// Result.Outputs, keyed by field name, so callers can record them after the run.
+//
 // # Usage Example
 //
 //	// Create activities
@@ -0,0 +1,59 @@
+package workflow
+
+// Finalizer is an optional interface for activities that must run once their dependencies
+// have finished, whether they completed, failed or were skipped. Finalizers are intended for
+// cleanup, such as powering off hardware that an earlier activity powered on.
+//
+// A finalizer differs from other activities in that:
+//   - it is never skipped because a dependency failed or the context was cancelled
+//   - Execute() is called with a context that is never cancelled
+//   - if it declares no dependencies, it runs after every activity that is not a finalizer
+//
+// Example:
+//
+//	func (a *PowerOffPBS) Finalize(dependencies map[workflow.ActivityID]workflow.Result) {
+//	    for id, result := range dependencies {
+//	        if !result.IsSuccess() {
+//	            a.Logger.Warn("powering off after failure", "activity", id.ShortString())
+//	        }
+//	    }
+//	}
+type Finalizer interface {
+	// Finalize is called before Execute() with the final results of the activity's dependencies.
+	Finalize(dependencies map[ActivityID]Result)
+}
+
+// isFinalizer reports whether activity implements Finalizer.
+func isFinalizer(activity Activity) bool {
+	_, ok := activity.(Finalizer)
+	return ok
+}
+
+// addFinalizerDependencies makes each finalizer without declared dependencies depend on
+// every activity that is not a finalizer.
+func (o *Orchestrator) addFinalizerDependencies() {
+	for id, activity := range o.activityMap {
+		if !isFinalizer(activity) || len(o.dependencyMap[id]) > 0 {
+			continue
+		}
+		for otherID, other := range o.activityMap {
+			if !isFinalizer(other) {
+				o.dependencyMap[id] = append(o.dependencyMap[id], otherID)
+			}
+		}
+	}
+}
+
+// dependencyResults returns a copy of the current results of the given activities.
+func (o *Orchestrator) dependencyResults(dependencies []ActivityID) map[ActivityID]Result {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	results := make(map[ActivityID]Result, len(dependencies))
+	for _, depID := range dependencies {
+		if result, ok := o.resultMap[depID]; ok {
+			results[depID] = *result
+		}
+	}
+	return results
+}
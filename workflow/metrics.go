@@ -0,0 +1,94 @@
+package workflow
+
+import (
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/nomis52/goback/metrics"
+)
+
+// Outcome label values for the activity_outcomes_total counter
+const (
+	outcomeCompleted = "completed"
+	outcomeFailed    = "failed"
+	outcomeTimedOut  = "timed_out"
+	outcomeSkipped   = "skipped"
+)
+
+// activityDurationBuckets spans activities that take a second through to those that run for hours.
+var activityDurationBuckets = prometheus.ExponentialBuckets(1, 4, 8)
+
+// activityMetrics records per-activity durations, states and outcomes.
+// Every metric is labelled by workflow name and the activity's ShortString().
+type activityMetrics struct {
+	workflow string
+	duration metrics.HistogramVec
+	state    metrics.GaugeVec
+	outcomes metrics.CounterVec
+}
+
+// newActivityMetrics creates the activity metrics in registry.
+// Metrics that fail to register are logged and left nil so the rest are still recorded.
+func newActivityMetrics(registry metrics.Registry, workflow string, logger *slog.Logger) *activityMetrics {
+	m := &activityMetrics{workflow: workflow}
+	labels := []string{"workflow", "activity"}
+
+	var err error
+	m.duration, err = registry.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "workflow_activity_duration_seconds",
+		Help:    "Duration of activity executions in seconds, including retries",
+		Buckets: activityDurationBuckets,
+	}, labels)
+	if err != nil {
+		logger.Error("failed to create workflow_activity_duration_seconds metric", "error", err)
+	}
+
+	m.state, err = registry.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "workflow_activity_state",
+		Help: "Current activity state: 0=not_started, 1=pending, 2=running, 3=skipped, 4=completed",
+	}, labels)
+	if err != nil {
+		logger.Error("failed to create workflow_activity_state metric", "error", err)
+	}
+
+	m.outcomes, err = registry.NewCounterVec(prometheus.CounterOpts{
+		Name: "workflow_activity_outcomes_total",
+		Help: "Total number of activities that completed, failed, timed out or were skipped",
+	}, append(labels, "outcome"))
+	if err != nil {
+		logger.Error("failed to create workflow_activity_outcomes_total metric", "error", err)
+	}
+
+	return m
+}
+
+// observe records a change to an activity's result.
+func (m *activityMetrics) observe(id ActivityID, result Result) {
+	labels := prometheus.Labels{"workflow": m.workflow, "activity": id.ShortString()}
+
+	if m.state != nil {
+		m.state.With(labels).Set(float64(result.State))
+	}
+
+	var outcome string
+	switch {
+	case result.State == Skipped:
+		outcome = outcomeSkipped
+	case result.TimedOut():
+		outcome = outcomeTimedOut
+	case result.State == Completed && result.Error != nil:
+		outcome = outcomeFailed
+	case result.State == Completed:
+		outcome = outcomeCompleted
+	default:
+		return
+	}
+
+	if m.outcomes != nil {
+		m.outcomes.With(prometheus.Labels{"workflow": m.workflow, "activity": id.ShortString(), "outcome": outcome}).Inc()
+	}
+	if m.duration != nil && result.State == Completed {
+		m.duration.With(labels).Observe(result.Duration().Seconds())
+	}
+}
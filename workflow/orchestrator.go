@@ -2,12 +2,16 @@ package workflow
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/nomis52/goback/metrics"
 )
 
 // Orchestrator manages the execution of activities with dependency resolution.
@@ -31,12 +35,31 @@ type Orchestrator struct {
 	// Core data structures using ActivityID as primary key
 	activityMap     map[ActivityID]Activity      // Primary storage for activities
 	dependencyMap   map[ActivityID][]ActivityID  // activity ID -> list of dependency IDs
+	orderingMap     map[ActivityID][]ActivityID  // activity ID -> dependencies added with AddDependency
+	toleratedMap    map[ActivityID][]ActivityID  // activity ID -> dependencies whose failure it tolerates
 	completionChans map[ActivityID]chan struct{} // activity ID -> completion signal (closed when done)
 	resultMap       map[ActivityID]*Result       // activity ID -> result (protected by mutex)
 
+	// The dependency graph is built once, by Validate or Execute, so factories are only
+	// called once per activity
+	graphBuilt bool
+	graphErr   error
+
+	// observer is notified of every result state change, may be nil
+	observer ResultObserver
+
+	// Per-activity metrics, only recorded when a registry is set with WithMetrics
+	registry     metrics.Registry
+	workflowName string
+	metrics      *activityMetrics
+
 	mu sync.RWMutex
 }
 
+// ResultObserver is called with a copy of an activity's result each time it changes,
+// e.g. to stream progress to a UI. It is called from the activity's goroutine and must not block.
+type ResultObserver func(id ActivityID, result Result)
+
 // Factory creates a dependency instance for a specific activity.
 // The activityID parameter can be used to create activity-specific instances,
 // or ignored to create shared instances.
@@ -88,6 +111,22 @@ func WithConfig(config interface{}) OrchestratorOption {
 	}
 }
 
+// WithResultObserver sets a function that is called whenever an activity's result changes
+func WithResultObserver(observer ResultObserver) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.observer = observer
+	}
+}
+
+// WithMetrics records per-activity durations, states and outcomes in registry,
+// labelled with the given workflow name. A nil registry disables metrics.
+func WithMetrics(registry metrics.Registry, workflowName string) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.registry = registry
+		o.workflowName = workflowName
+	}
+}
+
 // NewOrchestrator creates a new orchestrator instance with optional configuration
 func NewOrchestrator(opts ...OrchestratorOption) *Orchestrator {
 	o := &Orchestrator{
@@ -95,6 +134,8 @@ func NewOrchestrator(opts ...OrchestratorOption) *Orchestrator {
 		factories:       make(map[reflect.Type]factoryEntry),
 		activityMap:     make(map[ActivityID]Activity),
 		dependencyMap:   make(map[ActivityID][]ActivityID),
+		orderingMap:     make(map[ActivityID][]ActivityID),
+		toleratedMap:    make(map[ActivityID][]ActivityID),
 		completionChans: make(map[ActivityID]chan struct{}),
 		resultMap:       make(map[ActivityID]*Result),
 	}
@@ -104,6 +145,10 @@ func NewOrchestrator(opts ...OrchestratorOption) *Orchestrator {
 		opt(o)
 	}
 
+	if o.registry != nil {
+		o.metrics = newActivityMetrics(o.registry, o.workflowName, o.logger)
+	}
+
 	return o
 }
 
@@ -147,6 +192,9 @@ func Provide[T any](o *Orchestrator, factory Factory[T]) {
 // Returns an error if an activity of the same type already exists.
 // Activity types are identified by their full module path + struct name to prevent collisions.
 func (o *Orchestrator) AddActivity(activities ...Activity) error {
+	if o.graphBuilt {
+		return errors.New("activities cannot be added once the workflow has been validated or executed")
+	}
 	for _, activity := range activities {
 		id := GetActivityID(activity)
 
@@ -167,6 +215,49 @@ func (o *Orchestrator) AddActivity(activities ...Activity) error {
 	return nil
 }
 
+// AddDependency makes activity wait for dependsOn to complete, in addition to the
+// dependencies declared by its fields. The dependency is for ordering only: nothing is
+// injected, as with an unnamed field (_ *DependsOn).
+//
+// Returns an error if either activity has not been added, or if they are the same activity.
+func (o *Orchestrator) AddDependency(activity, dependsOn Activity) error {
+	if o.graphBuilt {
+		return errors.New("dependencies cannot be added once the workflow has been validated or executed")
+	}
+
+	id := GetActivityID(activity)
+	depID := GetActivityID(dependsOn)
+
+	if _, exists := o.activityMap[id]; !exists {
+		return fmt.Errorf("activity %s has not been added", id.String())
+	}
+	if _, exists := o.activityMap[depID]; !exists {
+		return fmt.Errorf("activity %s has not been added", depID.String())
+	}
+	if id == depID {
+		return fmt.Errorf("activity %s cannot depend on itself", id.String())
+	}
+
+	o.orderingMap[id] = append(o.orderingMap[id], depID)
+	o.logger.Debug("ordering dependency added", "activity_id", id.String(), "dependency", depID.String())
+	return nil
+}
+
+// Validate injects dependencies and config, then checks the dependency graph without
+// executing any activities. It returns the error Execute would return for a missing
+// dependency, a circular dependency or a config field that can't be injected.
+//
+// Execute performs the same checks, so Validate is only needed to find errors early,
+// such as when a workflow is loaded from a config file. The graph is only built once:
+// calling Execute after Validate reuses it, so dependency factories are not called again.
+// Activities and dependencies cannot be added once the graph has been built.
+func (o *Orchestrator) Validate() error {
+	if err := o.buildDependencyGraphOnce(); err != nil {
+		return fmt.Errorf("dependency analysis failed: %w", err)
+	}
+	return nil
+}
+
 // Execute runs all activities with dependency resolution and proper error handling.
 //
 // BEHAVIOR CONTRACT:
@@ -182,6 +273,7 @@ func (o *Orchestrator) AddActivity(activities ...Activity) error {
 // DEPENDENCY BEHAVIOR:
 // - Activities wait for ALL dependencies to complete successfully
 // - If any dependency fails, dependent activities are Skipped
+// - Finalizers wait for ALL dependencies to finish and are never Skipped
 // - Execution continues for remaining activities (fail-fast disabled)
 // - Both named and unnamed dependencies establish execution ordering
 //
@@ -194,8 +286,8 @@ func (o *Orchestrator) Execute(ctx context.Context) error {
 
 	o.logger.Info("starting execution", "activity_count", len(o.activityMap))
 
-	// 1. Build dependency graph and inject dependencies/config
-	if err := o.buildDependencyGraph(); err != nil {
+	// 1. Build dependency graph and inject dependencies/config, unless Validate already has
+	if err := o.buildDependencyGraphOnce(); err != nil {
 		o.logger.Error("dependency analysis failed", "error", err)
 		// For circular dependencies, leave results in NotStarted state with no individual errors
 		// For other validation failures, leave results in NotStarted state with no individual errors
@@ -272,28 +364,31 @@ func (o *Orchestrator) runActivity(ctx context.Context, id ActivityID, activity
 	activityLogger := o.logger.With("activity_module", id.Module, "activity_type", id.Type, "activity_id", id.String())
 	activityLogger.Debug("activity goroutine started")
 
-	// Get the pre-initialized result and update it to Pending
-	o.mu.Lock()
-	result := o.resultMap[id]
-	result.State = Pending // Activity is now waiting for dependencies
-	o.mu.Unlock()
+	// Activity is now waiting for dependencies
+	result := &Result{State: Pending}
+	o.setResult(id, result)
 
 	// Wait for all dependencies to complete successfully
 	dependencies := o.dependencyMap[id]
-	activityLogger.Debug("checking dependencies", "dependency_count", len(dependencies))
+	finalizer, isFinalizer := activity.(Finalizer)
+	activityLogger.Debug("checking dependencies", "dependency_count", len(dependencies), "finalizer", isFinalizer)
 
 	for _, depID := range dependencies {
 		activityLogger.Debug("waiting for dependency", "dependency", depID.String())
 
+		if isFinalizer {
+			// Finalizers wait for dependencies to finish, however they finished
+			<-o.completionChans[depID]
+			continue
+		}
+
 		// Wait for this specific dependency to complete
 		select {
 		case <-ctx.Done():
 			activityLogger.Warn("activity cancelled due to context", "error", ctx.Err())
 			// Update result to show cancellation (Error remains nil as per documentation)
 			result = &Result{State: Skipped, Error: nil}
-			o.mu.Lock()
-			o.resultMap[id] = result
-			o.mu.Unlock()
+			o.setResult(id, result)
 			// Signal completion for this activity since it's now skipped
 			close(o.completionChans[id])
 			errorChan <- fmt.Errorf("activity %s cancelled: %w", id.String(), ctx.Err())
@@ -312,22 +407,23 @@ func (o *Orchestrator) runActivity(ctx context.Context, id ActivityID, activity
 			activityLogger.Error("dependency completed but no result found", "dependency", depID.String())
 			// Skipped activities have Error = nil as per documentation
 			result = &Result{State: Skipped, Error: nil}
-			o.mu.Lock()
-			o.resultMap[id] = result
-			o.mu.Unlock()
+			o.setResult(id, result)
 			// Signal completion for this activity since it's now skipped
 			close(o.completionChans[id])
 			errorChan <- fmt.Errorf("activity %s skipped: dependency %s completed but no result found", id.String(), depID.String())
 			return
 		}
 
+		if depResult.State == Completed && depResult.Error != nil && slices.Contains(o.toleratedMap[id], depID) {
+			activityLogger.Warn("dependency failed, running with the outputs it published", "dependency", depID.String(), "error", depResult.Error)
+			continue
+		}
+
 		if !depResult.IsSuccess() {
 			activityLogger.Error("dependency failed", "dependency", depID.String(), "error", depResult.Error)
 			// Skipped activities have Error = nil as per documentation
 			result = &Result{State: Skipped, Error: nil}
-			o.mu.Lock()
-			o.resultMap[id] = result
-			o.mu.Unlock()
+			o.setResult(id, result)
 			// Signal completion for this activity since it's now skipped
 			close(o.completionChans[id])
 			errorChan <- fmt.Errorf("activity %s skipped due to dependency failure: %s", id.String(), depID.String())
@@ -338,30 +434,37 @@ func (o *Orchestrator) runActivity(ctx context.Context, id ActivityID, activity
 		activityLogger.Debug("dependency succeeded", "dependency", depID.String())
 	}
 
-	activityLogger.Info("all dependencies satisfied, executing activity")
+	if isFinalizer {
+		finalizer.Finalize(o.dependencyResults(dependencies))
+		// Finalizers run to completion even if the workflow was cancelled
+		ctx = context.WithoutCancel(ctx)
+		activityLogger.Info("all dependencies finished, executing finalizer")
+	} else {
+		activityLogger.Info("all dependencies satisfied, executing activity")
+	}
 
 	// Mark as running
-	result = &Result{State: Running, Error: nil, StartTime: time.Now()}
-	o.mu.Lock()
-	o.resultMap[id] = result
-	o.mu.Unlock()
+	result = &Result{State: Running, Error: nil, StartTime: time.Now(), Attempts: 1}
+	o.setResult(id, result)
 
-	// Execute the activity
-	err := activity.Execute(ctx)
+	// Execute the activity, retrying according to its policy
+	err := o.executeWithRetry(ctx, id, activity, result.StartTime, activityLogger)
 	endTime := time.Now()
 
-	// Create final result (preserve StartTime from when we marked as Running)
-	result = &Result{State: Completed, Error: err, StartTime: result.StartTime, EndTime: endTime}
+	// Create final result (preserve StartTime and Attempts from the Running result) with the
+	// outputs the activity published
+	o.mu.RLock()
+	attempts := o.resultMap[id].Attempts
+	o.mu.RUnlock()
+	result = &Result{State: Completed, Error: err, StartTime: result.StartTime, EndTime: endTime, Attempts: attempts, Outputs: collectOutputs(activity)}
 	if err != nil {
-		activityLogger.Error("activity execution failed", "error", err)
+		activityLogger.Error("activity execution failed", "error", err, "attempts", attempts)
 	} else {
 		activityLogger.Info("activity execution completed successfully")
 	}
 
 	// Store final result
-	o.mu.Lock()
-	o.resultMap[id] = result
-	o.mu.Unlock()
+	o.setResult(id, result)
 
 	// Signal completion
 	close(o.completionChans[id])
@@ -373,6 +476,63 @@ func (o *Orchestrator) runActivity(ctx context.Context, id ActivityID, activity
 	}
 }
 
+// executeWithRetry calls activity.Execute, retrying failures according to the activity's
+// RetryPolicy. Each attempt is limited by the activity's timeout, if it has one.
+// The Running result's Attempts counter is updated before each retry.
+// Returns the error from the final attempt.
+func (o *Orchestrator) executeWithRetry(ctx context.Context, id ActivityID, activity Activity, startTime time.Time, activityLogger *slog.Logger) error {
+	policy := retryPolicyFor(activity)
+	maxAttempts := policy.attempts()
+	backoff := policy.InitialBackoff
+	timeout := timeoutFor(activity)
+
+	for attempt := 1; ; attempt++ {
+		err := executeWithTimeout(ctx, activity, timeout)
+		if err == nil || attempt >= maxAttempts || !policy.shouldRetry(ctx, err) {
+			return err
+		}
+
+		activityLogger.Warn("activity attempt failed, retrying",
+			"attempt", attempt,
+			"max_attempts", maxAttempts,
+			"backoff", backoff,
+			"error", err)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff = policy.nextBackoff(backoff)
+
+		o.setResult(id, &Result{State: Running, StartTime: startTime, Attempts: attempt + 1})
+	}
+}
+
+// setResult stores an activity's result, records its metrics and notifies the observer, if any.
+func (o *Orchestrator) setResult(id ActivityID, result *Result) {
+	o.mu.Lock()
+	o.resultMap[id] = result
+	o.mu.Unlock()
+
+	if o.metrics != nil {
+		o.metrics.observe(id, *result)
+	}
+	if o.observer != nil {
+		o.observer(id, *result)
+	}
+}
+
+// buildDependencyGraphOnce builds the dependency graph the first time it is called, and
+// returns the result of that build on later calls.
+func (o *Orchestrator) buildDependencyGraphOnce() error {
+	if !o.graphBuilt {
+		o.graphErr = o.buildDependencyGraph()
+		o.graphBuilt = true
+	}
+	return o.graphErr
+}
+
 // buildDependencyGraph analyzes activity dependencies and injects config/dependencies
 // Optimized to eliminate redundant activity ID calculations and use map operations
 func (o *Orchestrator) buildDependencyGraph() error {
@@ -399,6 +559,7 @@ func (o *Orchestrator) buildDependencyGraph() error {
 	// Second pass: build dependency graph and inject activity dependencies
 	for id, activity := range o.activityMap {
 		dependencies := []ActivityID{}
+		var tolerated []ActivityID
 
 		activityValue := reflect.ValueOf(activity).Elem()
 		activityType := activityValue.Type()
@@ -435,6 +596,9 @@ func (o *Orchestrator) buildDependencyGraph() error {
 					// This is a dependency - record the dependency
 					dependencies = append(dependencies, depID)
 					o.logger.Debug("activity dependency detected", "activity_id", id.String(), "dependency", depID.String(), "field_name", field.Name)
+					if field.Tag.Get("workflow") == TagTolerateFailure {
+						tolerated = append(tolerated, depID)
+					}
 
 					// Only inject the value if it's not an unnamed field (unnamed fields are for ordering only)
 					if field.Name != "_" {
@@ -460,8 +624,17 @@ func (o *Orchestrator) buildDependencyGraph() error {
 			}
 		}
 
+		// Add ordering dependencies that are not already declared by a field
+		for _, depID := range o.orderingMap[id] {
+			if !slices.Contains(dependencies, depID) {
+				dependencies = append(dependencies, depID)
+			}
+		}
+
 		o.dependencyMap[id] = dependencies
+		o.toleratedMap[id] = tolerated
 	}
+	o.addFinalizerDependencies()
 
 	// Log dependency graph
 	o.logger.Debug("dependency graph built")
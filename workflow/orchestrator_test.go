@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http/httptest"
 	"os"
 	"sync"
 	"testing"
 
 	"github.com/nomis52/goback/logging"
+	"github.com/nomis52/goback/metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -196,6 +198,115 @@ func TestOrchestrator_CircularDependencyDetection(t *testing.T) {
 	assert.Contains(t, err.Error(), "circular dependency")
 }
 
+// TestOrchestrator_AddDependency tests ordering dependencies added without a field
+func TestOrchestrator_AddDependency(t *testing.T) {
+	t.Run("Ordering", func(t *testing.T) {
+		orchestrator := NewOrchestrator()
+		fail := &FailActivity{}
+		pass := &PassActivity{}
+		require.NoError(t, orchestrator.AddActivity(fail, pass))
+		require.NoError(t, orchestrator.AddDependency(pass, fail))
+
+		err := orchestrator.Execute(context.Background())
+		require.Error(t, err)
+
+		assert.True(t, fail.Executed)
+		assert.False(t, pass.Executed, "pass should be skipped after its added dependency failed")
+		assert.Equal(t, Skipped, getResult(orchestrator, pass).State)
+	})
+
+	t.Run("CircularDependency", func(t *testing.T) {
+		orchestrator := NewOrchestrator()
+		Provide(orchestrator, Shared(&MockLogger{}))
+		setup := &DatabaseSetupActivity{}
+		migration := &DataMigrationActivity{}
+		require.NoError(t, orchestrator.AddActivity(setup, migration))
+		require.NoError(t, orchestrator.AddDependency(setup, migration))
+
+		err := orchestrator.Execute(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "circular dependency")
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		orchestrator := NewOrchestrator()
+		pass := &PassActivity{}
+		require.NoError(t, orchestrator.AddActivity(pass))
+
+		err := orchestrator.AddDependency(pass, &FailActivity{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has not been added")
+
+		err = orchestrator.AddDependency(pass, pass)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot depend on itself")
+	})
+}
+
+// TestOrchestrator_Validate tests that the dependency graph is checked without executing activities
+func TestOrchestrator_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		activities func() []Activity
+		wantErr    string
+	}{
+		{
+			name:       "valid",
+			activities: func() []Activity { return []Activity{&FailActivity{}, &DependentOnFailingActivity{}} },
+		},
+		{
+			name:       "missing dependency",
+			activities: func() []Activity { return []Activity{&DataMigrationActivity{}} },
+			wantErr:    "has nil dependency: Setup",
+		},
+		{
+			name:       "circular dependency",
+			activities: func() []Activity { return []Activity{&FirstCircularActivity{}, &SecondCircularActivity{}} },
+			wantErr:    "circular dependency",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := NewOrchestrator()
+			Provide(orchestrator, Shared(&MockLogger{}))
+			activities := tt.activities()
+			require.NoError(t, orchestrator.AddActivity(activities...))
+
+			err := orchestrator.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			for _, activity := range activities {
+				assert.Equal(t, NotStarted, getResult(orchestrator, activity).State)
+			}
+		})
+	}
+}
+
+// TestOrchestrator_ValidateThenExecute tests that Execute reuses the graph built by Validate
+func TestOrchestrator_ValidateThenExecute(t *testing.T) {
+	orchestrator := NewOrchestrator(WithConfig(TestConfig{Database: DatabaseConfig{Host: "localhost", Port: 5432}}))
+	calls := 0
+	Provide(orchestrator, func(ActivityID) *MockLogger {
+		calls++
+		return &MockLogger{}
+	})
+	setup := &DatabaseSetupActivity{}
+	require.NoError(t, orchestrator.AddActivity(setup))
+
+	require.NoError(t, orchestrator.Validate())
+	require.NoError(t, orchestrator.Execute(context.Background()))
+
+	assert.True(t, setup.Executed)
+	assert.Equal(t, 1, calls, "factories should only be called once")
+	assert.Error(t, orchestrator.AddActivity(&PassActivity{}), "activities cannot be added after validation")
+}
+
 // TestOrchestrator_DependencyInjection tests comprehensive dependency injection features
 func TestOrchestrator_DependencyInjection(t *testing.T) {
 	// Configuration for activities
@@ -313,6 +424,119 @@ func TestOrchestrator_LogCapture(t *testing.T) {
 	}
 }
 
+// TestOrchestrator_ResultObserver tests that every result transition is reported to the observer
+func TestOrchestrator_ResultObserver(t *testing.T) {
+	var mu sync.Mutex
+	states := make(map[ActivityID][]ActivityState)
+	observer := func(id ActivityID, result Result) {
+		mu.Lock()
+		defer mu.Unlock()
+		states[id] = append(states[id], result.State)
+	}
+
+	orchestrator := NewOrchestrator(WithResultObserver(observer))
+	fail := &FailActivity{}
+	dependent := &DependentOnFailingActivity{}
+	require.NoError(t, orchestrator.AddActivity(fail, dependent))
+
+	err := orchestrator.Execute(context.Background())
+	require.Error(t, err)
+
+	assert.Equal(t, []ActivityState{Pending, Running, Completed}, states[GetActivityID(fail)])
+	assert.Equal(t, []ActivityState{Pending, Skipped}, states[GetActivityID(dependent)])
+}
+
+func TestOrchestrator_Metrics(t *testing.T) {
+	registry, err := metrics.NewScrapeRegistry()
+	require.NoError(t, err)
+
+	orchestrator := NewOrchestrator(WithMetrics(registry, "test"))
+	pass := &PassActivity{}
+	fail := &FailActivity{}
+	dependent := &DependentOnFailingActivity{}
+	require.NoError(t, orchestrator.AddActivity(pass, fail, dependent))
+
+	err = orchestrator.Execute(context.Background())
+	require.Error(t, err)
+
+	w := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+
+	// Labels are exposed in alphabetical order
+	labels := func(a Activity, outcome string) string {
+		if outcome != "" {
+			return fmt.Sprintf(`{activity=%q,outcome=%q,workflow="test"}`, GetActivityID(a).ShortString(), outcome)
+		}
+		return fmt.Sprintf(`{activity=%q,workflow="test"}`, GetActivityID(a).ShortString())
+	}
+
+	assert.Contains(t, body, fmt.Sprintf("workflow_activity_state%s %d", labels(pass, ""), Completed))
+	assert.Contains(t, body, fmt.Sprintf("workflow_activity_state%s %d", labels(dependent, ""), Skipped))
+	assert.Contains(t, body, "workflow_activity_outcomes_total"+labels(pass, "completed")+" 1")
+	assert.Contains(t, body, "workflow_activity_outcomes_total"+labels(fail, "failed")+" 1")
+	assert.Contains(t, body, "workflow_activity_outcomes_total"+labels(dependent, "skipped")+" 1")
+	assert.Contains(t, body, "workflow_activity_duration_seconds_count"+labels(pass, "")+" 1")
+	assert.Contains(t, body, "workflow_activity_duration_seconds_count"+labels(fail, "")+" 1")
+	assert.NotContains(t, body, "workflow_activity_duration_seconds_count"+labels(dependent, ""), "skipped activities have no duration")
+}
+
+func TestOrchestrator_Finalizer(t *testing.T) {
+	t.Run("RunsAfterDependencyFailure", func(t *testing.T) {
+		orchestrator := NewOrchestrator()
+		fail := &FailActivity{}
+		finalizer := &FinalizerActivity{}
+		require.NoError(t, orchestrator.AddActivity(fail, finalizer))
+
+		err := orchestrator.Execute(context.Background())
+		require.Error(t, err)
+
+		result := getResult(orchestrator, finalizer)
+		assert.Equal(t, Completed, result.State)
+		assert.NoError(t, result.Error)
+		assert.True(t, finalizer.Executed)
+
+		require.Contains(t, finalizer.Dependencies, GetActivityID(fail))
+		assert.Equal(t, Completed, finalizer.Dependencies[GetActivityID(fail)].State)
+		assert.EqualError(t, finalizer.Dependencies[GetActivityID(fail)].Error, "intentional failure")
+	})
+
+	t.Run("RunsAfterEveryActivityWithoutDependencies", func(t *testing.T) {
+		orchestrator := NewOrchestrator()
+		pass := &PassActivity{}
+		fail := &FailActivity{}
+		dependent := &DependentOnFailingActivity{}
+		cleanup := &CleanupFinalizer{}
+		require.NoError(t, orchestrator.AddActivity(pass, fail, dependent, cleanup))
+
+		err := orchestrator.Execute(context.Background())
+		require.Error(t, err)
+
+		assert.Equal(t, Completed, getResult(orchestrator, cleanup).State)
+		assert.Len(t, cleanup.Dependencies, 3)
+		assert.Equal(t, Completed, cleanup.Dependencies[GetActivityID(pass)].State)
+		assert.Equal(t, Skipped, cleanup.Dependencies[GetActivityID(dependent)].State)
+	})
+
+	t.Run("RunsAfterCancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		orchestrator := NewOrchestrator()
+		fail := &FailActivity{}
+		dependent := &DependentOnFailingActivity{}
+		cleanup := &CleanupFinalizer{}
+		require.NoError(t, orchestrator.AddActivity(fail, dependent, cleanup))
+
+		err := orchestrator.Execute(ctx)
+		require.Error(t, err)
+
+		assert.Equal(t, Skipped, getResult(orchestrator, dependent).State)
+		assert.Equal(t, Completed, getResult(orchestrator, cleanup).State)
+		assert.False(t, cleanup.Cancelled, "finalizers should run with a context that is not cancelled")
+	})
+}
+
 // ---------------------------------------------------------------------
 // Test Activity Definitions
 // ---------------------------------------------------------------------
@@ -352,6 +576,37 @@ func (d *DependentOnFailingActivity) Execute(ctx context.Context) error {
 	return nil
 }
 
+// FinalizerActivity - runs after FailActivity, however it finished
+type FinalizerActivity struct {
+	_            *FailActivity
+	Dependencies map[ActivityID]Result
+	Executed     bool
+}
+
+func (f *FinalizerActivity) Init() error { return nil }
+func (f *FinalizerActivity) Finalize(dependencies map[ActivityID]Result) {
+	f.Dependencies = dependencies
+}
+func (f *FinalizerActivity) Execute(ctx context.Context) error {
+	f.Executed = true
+	return nil
+}
+
+// CleanupFinalizer - declares no dependencies, so runs after every other activity
+type CleanupFinalizer struct {
+	Dependencies map[ActivityID]Result
+	Cancelled    bool
+}
+
+func (c *CleanupFinalizer) Init() error { return nil }
+func (c *CleanupFinalizer) Finalize(dependencies map[ActivityID]Result) {
+	c.Dependencies = dependencies
+}
+func (c *CleanupFinalizer) Execute(ctx context.Context) error {
+	c.Cancelled = ctx.Err() != nil
+	return nil
+}
+
 // Two activities that depends on each other
 type FirstCircularActivity struct {
 	Second *SecondCircularActivity
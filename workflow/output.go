@@ -0,0 +1,99 @@
+package workflow
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Output is a typed value an activity publishes for the activities that depend on it.
+// Declare it as an exported field, set it in Execute() and read it from a dependent
+// activity, which only runs once the activity has finished:
+//
+//	type BackupVMs struct {
+//	    Backups workflow.Output[[]CompletedBackup]
+//	}
+//
+//	type VerifyBackups struct {
+//	    BackupVMs *BackupVMs
+//	}
+//
+//	func (a *VerifyBackups) Execute(ctx context.Context) error {
+//	    backups, ok := a.BackupVMs.Backups.Get()
+//	    ...
+//	}
+//
+// Once an activity finishes, the values of its outputs are copied into Result.Outputs,
+// keyed by field name. Output values should be JSON serialisable so they can be stored
+// in the run history.
+type Output[T any] struct {
+	mu    sync.RWMutex
+	value T
+	set   bool
+}
+
+// Set publishes the output's value, replacing any previous value.
+func (o *Output[T]) Set(value T) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.value = value
+	o.set = true
+}
+
+// Get returns the output's value and whether it was set.
+// The zero value of T is returned if the output was never set.
+func (o *Output[T]) Get() (T, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.value, o.set
+}
+
+// anyOutput returns the output's value with its type erased, so the orchestrator can collect
+// outputs without knowing their types.
+func (o *Output[T]) anyOutput() (any, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.value, o.set
+}
+
+// TagTolerateFailure is the workflow tag value that lets an activity run after the tagged
+// dependency failed, so it can use the outputs the dependency set before failing:
+//
+//	type VerifyBackups struct {
+//	    BackupVMs *BackupVMs `workflow:"tolerate_failure"`
+//	}
+//
+// The activity is still skipped if the dependency was skipped, or if the context is
+// cancelled while it waits.
+const TagTolerateFailure = "tolerate_failure"
+
+// outputField is implemented by every Output[T].
+type outputField interface {
+	anyOutput() (any, bool)
+}
+
+// collectOutputs returns the values of the outputs an activity has set, keyed by field name.
+// Returns nil if the activity has no outputs that were set.
+func collectOutputs(activity Activity) map[string]any {
+	activityValue := reflect.ValueOf(activity).Elem()
+	activityType := activityValue.Type()
+
+	var outputs map[string]any
+	for i := 0; i < activityType.NumField(); i++ {
+		field := activityType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		output, ok := activityValue.Field(i).Addr().Interface().(outputField)
+		if !ok {
+			continue
+		}
+		if value, set := output.anyOutput(); set {
+			if outputs == nil {
+				outputs = make(map[string]any)
+			}
+			outputs[field.Name] = value
+		}
+	}
+	return outputs
+}
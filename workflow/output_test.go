@@ -0,0 +1,133 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutput(t *testing.T) {
+	var output Output[[]int]
+
+	value, ok := output.Get()
+	assert.False(t, ok)
+	assert.Nil(t, value)
+
+	output.Set([]int{100, 101})
+	value, ok = output.Get()
+	assert.True(t, ok)
+	assert.Equal(t, []int{100, 101}, value)
+}
+
+func TestOrchestrator_Outputs(t *testing.T) {
+	orchestrator := NewOrchestrator()
+	producer := &ProducerActivity{}
+	consumer := &ConsumerActivity{}
+	require.NoError(t, orchestrator.AddActivity(producer, consumer))
+
+	require.NoError(t, orchestrator.Execute(context.Background()))
+
+	assert.Equal(t, []int{100, 101}, consumer.Received, "dependents should read the output")
+
+	result := getResult(orchestrator, producer)
+	assert.Equal(t, map[string]any{"VMIDs": []int{100, 101}}, result.Outputs, "unset outputs should be omitted")
+
+	assert.Nil(t, getResult(orchestrator, consumer).Outputs)
+}
+
+// ProducerActivity publishes an output for ConsumerActivity
+type ProducerActivity struct {
+	VMIDs    Output[[]int]
+	Unset    Output[string]
+	internal Output[bool]
+}
+
+func (p *ProducerActivity) Init() error { return nil }
+func (p *ProducerActivity) Execute(ctx context.Context) error {
+	p.VMIDs.Set([]int{100, 101})
+	p.internal.Set(true)
+	return nil
+}
+
+// ConsumerActivity reads ProducerActivity's output
+type ConsumerActivity struct {
+	Producer *ProducerActivity
+	Received []int
+}
+
+func (c *ConsumerActivity) Init() error { return nil }
+func (c *ConsumerActivity) Execute(ctx context.Context) error {
+	c.Received, _ = c.Producer.VMIDs.Get()
+	return nil
+}
+
+func TestOrchestrator_TolerateFailure(t *testing.T) {
+	t.Run("RunsAfterFailure", func(t *testing.T) {
+		orchestrator := NewOrchestrator()
+		producer := &PartialProducerActivity{}
+		tolerant := &TolerantConsumerActivity{}
+		strict := &StrictConsumerActivity{}
+		require.NoError(t, orchestrator.AddActivity(producer, tolerant, strict))
+
+		require.Error(t, orchestrator.Execute(context.Background()))
+
+		assert.Equal(t, Completed, getResult(orchestrator, tolerant).State)
+		assert.Equal(t, []int{100}, tolerant.Received, "outputs set before the failure should be readable")
+		assert.Equal(t, Skipped, getResult(orchestrator, strict).State, "untagged dependents should be skipped")
+	})
+
+	t.Run("SkippedAfterSkippedDependency", func(t *testing.T) {
+		orchestrator := NewOrchestrator()
+		fail := &FailActivity{}
+		dependent := &DependentOnFailingActivity{}
+		tolerant := &TolerantOfSkippedActivity{}
+		require.NoError(t, orchestrator.AddActivity(fail, dependent, tolerant))
+
+		require.Error(t, orchestrator.Execute(context.Background()))
+
+		assert.Equal(t, Skipped, getResult(orchestrator, dependent).State)
+		assert.Equal(t, Skipped, getResult(orchestrator, tolerant).State)
+	})
+}
+
+// PartialProducerActivity publishes an output and then fails
+type PartialProducerActivity struct {
+	VMIDs Output[[]int]
+}
+
+func (p *PartialProducerActivity) Init() error { return nil }
+func (p *PartialProducerActivity) Execute(ctx context.Context) error {
+	p.VMIDs.Set([]int{100})
+	return errors.New("backup of 101 failed")
+}
+
+// TolerantConsumerActivity reads PartialProducerActivity's output even if it failed
+type TolerantConsumerActivity struct {
+	Producer *PartialProducerActivity `workflow:"tolerate_failure"`
+	Received []int
+}
+
+func (c *TolerantConsumerActivity) Init() error { return nil }
+func (c *TolerantConsumerActivity) Execute(ctx context.Context) error {
+	c.Received, _ = c.Producer.VMIDs.Get()
+	return nil
+}
+
+// StrictConsumerActivity only runs if PartialProducerActivity succeeds
+type StrictConsumerActivity struct {
+	Producer *PartialProducerActivity
+}
+
+func (c *StrictConsumerActivity) Init() error                       { return nil }
+func (c *StrictConsumerActivity) Execute(ctx context.Context) error { return nil }
+
+// TolerantOfSkippedActivity tolerates the failure of an activity that is skipped
+type TolerantOfSkippedActivity struct {
+	Dependent *DependentOnFailingActivity `workflow:"tolerate_failure"`
+}
+
+func (c *TolerantOfSkippedActivity) Init() error                       { return nil }
+func (c *TolerantOfSkippedActivity) Execute(ctx context.Context) error { return nil }
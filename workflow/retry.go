@@ -0,0 +1,92 @@
+package workflow
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultBackoffMultiplier = 2.0
+)
+
+// RetryPolicy describes how the orchestrator retries a failed activity.
+//
+// The zero value disables retries: the activity is executed exactly once.
+// Between attempts the orchestrator waits for the current backoff, starting at
+// InitialBackoff and multiplying by Multiplier after each attempt, capped at MaxBackoff.
+// Retries stop early if the context is cancelled.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of times Execute() may be called, including the first.
+	// Values less than 1 are treated as 1.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the second attempt.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between attempts. Zero means no cap.
+	MaxBackoff time.Duration
+
+	// Multiplier is applied to the backoff after each attempt. Values less than 1 default to 2.
+	Multiplier float64
+
+	// IsRetryable reports whether an error returned by Execute() should be retried.
+	// nil means all errors are retryable.
+	IsRetryable func(err error) bool
+}
+
+// Retryable is an optional interface for activities that should be retried on failure.
+//
+// Example:
+//
+//	func (a *PowerOnPBS) RetryPolicy() workflow.RetryPolicy {
+//	    return workflow.RetryPolicy{
+//	        MaxAttempts:    3,
+//	        InitialBackoff: 30 * time.Second,
+//	    }
+//	}
+type Retryable interface {
+	// RetryPolicy returns the policy used when Execute() returns an error.
+	RetryPolicy() RetryPolicy
+}
+
+// attempts returns the effective maximum number of attempts.
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// shouldRetry reports whether err should be retried.
+// Context errors are never retried since the orchestrator is shutting down.
+func (p RetryPolicy) shouldRetry(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if p.IsRetryable == nil {
+		return true
+	}
+	return p.IsRetryable(err)
+}
+
+// nextBackoff returns the delay to use after the given backoff.
+func (p RetryPolicy) nextBackoff(backoff time.Duration) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = defaultBackoffMultiplier
+	}
+	next := time.Duration(float64(backoff) * multiplier)
+	if p.MaxBackoff > 0 && next > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return next
+}
+
+// retryPolicyFor returns the retry policy for an activity, or the zero policy if the
+// activity does not implement Retryable.
+func retryPolicyFor(activity Activity) RetryPolicy {
+	if r, ok := activity.(Retryable); ok {
+		return r.RetryPolicy()
+	}
+	return RetryPolicy{}
+}
@@ -0,0 +1,159 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errPermanent = errors.New("permanent failure")
+
+func TestOrchestrator_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		failErr      error
+		policy       RetryPolicy
+		wantErr      string
+		wantAttempts int
+	}{
+		{
+			name:         "succeeds after retries",
+			failures:     2,
+			policy:       RetryPolicy{MaxAttempts: 3},
+			wantAttempts: 3,
+		},
+		{
+			name:         "attempts exhausted",
+			failures:     5,
+			policy:       RetryPolicy{MaxAttempts: 3},
+			wantErr:      "transient failure 3",
+			wantAttempts: 3,
+		},
+		{
+			name:         "zero policy runs once",
+			failures:     1,
+			wantErr:      "transient failure 1",
+			wantAttempts: 1,
+		},
+		{
+			name:     "non-retryable error",
+			failures: 5,
+			failErr:  errPermanent,
+			policy: RetryPolicy{
+				MaxAttempts: 3,
+				IsRetryable: func(err error) bool { return !errors.Is(err, errPermanent) },
+			},
+			wantErr:      "permanent failure",
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := NewOrchestrator()
+			activity := &FlakyActivity{failures: tt.failures, failErr: tt.failErr, policy: tt.policy}
+			require.NoError(t, orchestrator.AddActivity(activity))
+
+			err := orchestrator.Execute(context.Background())
+
+			result := getResult(orchestrator, activity)
+			assert.Equal(t, Completed, result.State)
+			assert.Equal(t, tt.wantAttempts, result.Attempts)
+			assert.Equal(t, tt.wantAttempts, activity.calls)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.False(t, result.IsSuccess())
+			} else {
+				require.NoError(t, err)
+				assert.True(t, result.IsSuccess())
+			}
+		})
+	}
+}
+
+func TestOrchestrator_RetryStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	orchestrator := NewOrchestrator()
+	activity := &FlakyActivity{
+		failures: 5,
+		policy:   RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour},
+		onFail:   cancel,
+	}
+	require.NoError(t, orchestrator.AddActivity(activity))
+
+	err := orchestrator.Execute(ctx)
+	require.Error(t, err)
+
+	result := getResult(orchestrator, activity)
+	assert.Equal(t, 1, result.Attempts)
+	assert.Equal(t, 1, activity.calls)
+}
+
+func TestRetryPolicy_NextBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		backoff time.Duration
+		want    time.Duration
+	}{
+		{
+			name:    "default multiplier",
+			backoff: time.Second,
+			want:    2 * time.Second,
+		},
+		{
+			name:    "custom multiplier",
+			policy:  RetryPolicy{Multiplier: 3},
+			backoff: time.Second,
+			want:    3 * time.Second,
+		},
+		{
+			name:    "capped",
+			policy:  RetryPolicy{MaxBackoff: 5 * time.Second},
+			backoff: 4 * time.Second,
+			want:    5 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.nextBackoff(tt.backoff))
+		})
+	}
+}
+
+// FlakyActivity fails a fixed number of times before succeeding.
+type FlakyActivity struct {
+	failures int
+	failErr  error
+	policy   RetryPolicy
+	onFail   func()
+	calls    int
+}
+
+func (a *FlakyActivity) Init() error { return nil }
+
+func (a *FlakyActivity) Execute(ctx context.Context) error {
+	a.calls++
+	if a.calls > a.failures {
+		return nil
+	}
+	if a.onFail != nil {
+		a.onFail()
+	}
+	if a.failErr != nil {
+		return a.failErr
+	}
+	return fmt.Errorf("transient failure %d", a.calls)
+}
+
+func (a *FlakyActivity) RetryPolicy() RetryPolicy {
+	return a.policy
+}
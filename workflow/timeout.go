@@ -0,0 +1,53 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTimeout is wrapped by the error of an activity whose Execute() did not finish within
+// the activity's timeout. Activities that enforce their own time limits, such as a limit on
+// each backup, may wrap it too. Use Result.TimedOut() to tell timeouts apart from other failures.
+var ErrTimeout = errors.New("activity timed out")
+
+// TimeLimited is an optional interface for activities that must finish within a time limit.
+// The orchestrator derives the context passed to Execute() from the timeout, so activities
+// only need to honour context cancellation rather than tracking the time themselves.
+//
+// Example:
+//
+//	func (a *PowerOnPBS) Timeout() time.Duration {
+//	    return a.BootTimeout
+//	}
+type TimeLimited interface {
+	// Timeout returns the maximum duration of each call to Execute(). Zero means no limit.
+	// For activities that are also Retryable, each attempt gets the full timeout.
+	Timeout() time.Duration
+}
+
+// timeoutFor returns the activity's timeout, or zero if it has none.
+func timeoutFor(activity Activity) time.Duration {
+	if t, ok := activity.(TimeLimited); ok {
+		return t.Timeout()
+	}
+	return 0
+}
+
+// executeWithTimeout calls activity.Execute with a context that expires after timeout.
+// If the timeout expires before the parent context is done, the returned error wraps ErrTimeout.
+func executeWithTimeout(ctx context.Context, activity Activity, timeout time.Duration) error {
+	if timeout <= 0 {
+		return activity.Execute(ctx)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := activity.Execute(attemptCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %v: %w", ErrTimeout, timeout, err)
+	}
+	return err
+}
@@ -0,0 +1,127 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrchestrator_Timeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		blocking     int
+		policy       RetryPolicy
+		wantErr      bool
+		wantTimedOut bool
+		wantAttempts int
+	}{
+		{
+			name:         "finishes within the timeout",
+			timeout:      time.Hour,
+			wantAttempts: 1,
+		},
+		{
+			name:         "no timeout",
+			wantAttempts: 1,
+		},
+		{
+			name:         "times out",
+			timeout:      time.Millisecond,
+			blocking:     1,
+			wantErr:      true,
+			wantTimedOut: true,
+			wantAttempts: 1,
+		},
+		{
+			name:         "each attempt gets the full timeout",
+			timeout:      time.Millisecond,
+			blocking:     1,
+			policy:       RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			wantAttempts: 2,
+		},
+		{
+			name:         "every attempt times out",
+			timeout:      time.Millisecond,
+			blocking:     2,
+			policy:       RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+			wantErr:      true,
+			wantTimedOut: true,
+			wantAttempts: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := NewOrchestrator()
+			activity := &SlowActivity{timeout: tt.timeout, blocking: tt.blocking, policy: tt.policy}
+			require.NoError(t, orchestrator.AddActivity(activity))
+
+			err := orchestrator.Execute(context.Background())
+
+			result := getResult(orchestrator, activity)
+			assert.Equal(t, Completed, result.State)
+			assert.Equal(t, tt.wantAttempts, result.Attempts)
+			assert.Equal(t, tt.wantTimedOut, result.TimedOut())
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.ErrorIs(t, result.Error, ErrTimeout)
+				assert.ErrorIs(t, result.Error, context.DeadlineExceeded)
+			} else {
+				require.NoError(t, err)
+				assert.True(t, result.IsSuccess())
+			}
+		})
+	}
+}
+
+func TestOrchestrator_TimeoutIgnoresCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	orchestrator := NewOrchestrator()
+	activity := &SlowActivity{timeout: time.Hour, blocking: 1, onBlock: cancel}
+	require.NoError(t, orchestrator.AddActivity(activity))
+
+	err := orchestrator.Execute(ctx)
+	require.Error(t, err)
+
+	result := getResult(orchestrator, activity)
+	require.Error(t, result.Error)
+	assert.False(t, result.TimedOut(), "cancelling the run is not a timeout")
+	assert.False(t, errors.Is(result.Error, ErrTimeout))
+	assert.ErrorIs(t, result.Error, context.Canceled)
+}
+
+// SlowActivity blocks until its context is done for a fixed number of calls, then succeeds.
+type SlowActivity struct {
+	timeout  time.Duration
+	blocking int
+	policy   RetryPolicy
+	onBlock  func()
+	calls    int
+}
+
+func (a *SlowActivity) Init() error { return nil }
+
+func (a *SlowActivity) Execute(ctx context.Context) error {
+	a.calls++
+	if a.calls > a.blocking {
+		return nil
+	}
+	if a.onBlock != nil {
+		a.onBlock()
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (a *SlowActivity) Timeout() time.Duration {
+	return a.timeout
+}
+
+func (a *SlowActivity) RetryPolicy() RetryPolicy {
+	return a.policy
+}
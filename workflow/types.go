@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -72,6 +73,15 @@ type Result struct {
 	// EndTime is when the activity transitioned to Completed
 	// Zero value if the activity hasn't finished yet
 	EndTime time.Time
+
+	// Attempts is the number of times Execute() has been called
+	// Greater than 1 only for activities implementing Retryable
+	// Zero if the activity never reached Running state
+	Attempts int
+
+	// Outputs holds the values of the activity's Output fields, keyed by field name
+	// Set when the activity transitions to Completed; nil if it set no outputs
+	Outputs map[string]any
 }
 
 // IsSuccess returns true if the activity completed successfully.
@@ -85,6 +95,12 @@ func (r *Result) IsSuccess() bool {
 	return r.State == Completed && r.Error == nil
 }
 
+// TimedOut returns true if the activity failed because it exceeded its timeout.
+// Timed out activities are in Completed state, with an Error that wraps ErrTimeout.
+func (r *Result) TimedOut() bool {
+	return r.State == Completed && errors.Is(r.Error, ErrTimeout)
+}
+
 // Duration returns the execution duration of the activity.
 //
 // Returns the time between StartTime and EndTime if both are set.
@@ -70,3 +70,28 @@ func (c *compositeWorkflow) GetAllResults() map[ActivityID]*Result {
 	}
 	return results
 }
+
+// IgnoreCancel wraps a workflow so that it runs to completion even if the caller's
+// context has been cancelled. Values carried by the context are preserved.
+// This is intended for cleanup workflows, such as powering off hardware, that must
+// still run when an earlier workflow in the same run was cancelled.
+func IgnoreCancel(w Workflow) Workflow {
+	return &ignoreCancelWorkflow{
+		workflow: w,
+	}
+}
+
+// ignoreCancelWorkflow executes the wrapped workflow with a non-cancellable context.
+type ignoreCancelWorkflow struct {
+	workflow Workflow
+}
+
+// Execute runs the wrapped workflow with a context that is never cancelled.
+func (i *ignoreCancelWorkflow) Execute(ctx context.Context) error {
+	return i.workflow.Execute(context.WithoutCancel(ctx))
+}
+
+// GetAllResults returns the results of the wrapped workflow.
+func (i *ignoreCancelWorkflow) GetAllResults() map[ActivityID]*Result {
+	return i.workflow.GetAllResults()
+}
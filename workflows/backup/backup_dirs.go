@@ -15,8 +15,10 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/nomis52/goback/activity"
+	"github.com/nomis52/goback/clients/pbsclient"
 	"github.com/nomis52/goback/clients/sshclient"
 	"github.com/nomis52/goback/config"
+	"github.com/nomis52/goback/ledger"
 	"github.com/nomis52/goback/metrics"
 )
 
@@ -32,6 +34,7 @@ const (
 	pbsConnectivityMaxRetries    = 6 // 30 seconds total
 	metricDirectoryLastBackup    = "directory_last_backup"
 	metricDirectoryBackupFailure = "directory_backup_failure"
+	metricDirectoryDuration      = "directory_backup_duration_seconds"
 )
 
 // lineLogger is an io.Writer that logs each complete line to a logger.
@@ -79,24 +82,28 @@ func (ll *lineLogger) Close() error {
 }
 
 // BackupDirs manages the execution of directory backups on proxmox servers.
-// Runs after the PBS server is powered on
+// Runs after the PBS server is powered on.
+//
+// Each configured file job is run in turn as its own sub-task: it connects to its
+// host over SSH, waits for PBS to be reachable and runs a single proxmox-backup-client
+// invocation. A failing job does not prevent later jobs from running. A job with an
+// interval is skipped if PBS already has a snapshot of it taken within the interval.
 type BackupDirs struct {
 	// Dependencies
 	Logger     *slog.Logger
+	PBSClient  *pbsclient.Client
 	PowerOnPBS *PowerOnPBS
 	StatusLine *activity.StatusLine
 	Registry   metrics.Registry
+	Ledger     ledger.Recorder // optional
 
 	// Configuration
-	Files          config.FilesConfig `config:"files"`
-	PrivateKeyPath string             `config:"files.private_key_path"`
-
-	// SSH client for remote operations
-	sshClient *sshclient.SSHClient
+	Jobs []config.FileJobConfig `config:"files"`
 
 	// Metrics (initialized in Init)
-	lastBackupGauge metrics.GaugeVec
-	failureCounter  metrics.CounterVec
+	lastBackupGauge   metrics.GaugeVec
+	failureCounter    metrics.CounterVec
+	durationHistogram metrics.HistogramVec
 }
 
 func (a *BackupDirs) Init() error {
@@ -104,7 +111,7 @@ func (a *BackupDirs) Init() error {
 	a.lastBackupGauge, err = a.Registry.NewGaugeVec(prometheus.GaugeOpts{
 		Name: metricDirectoryLastBackup,
 		Help: "Unix timestamp of last successful directory backup",
-	}, []string{"target"})
+	}, []string{"job", "target"})
 	if err != nil {
 		return fmt.Errorf("creating %s metric: %w", metricDirectoryLastBackup, err)
 	}
@@ -112,140 +119,245 @@ func (a *BackupDirs) Init() error {
 	a.failureCounter, err = a.Registry.NewCounterVec(prometheus.CounterOpts{
 		Name: metricDirectoryBackupFailure,
 		Help: "Count of directory backup failures",
-	}, []string{"target"})
+	}, []string{"job", "target"})
 	if err != nil {
 		return fmt.Errorf("creating %s metric: %w", metricDirectoryBackupFailure, err)
 	}
 
-	if a.Files.Target == "" {
-		return nil // nothing configured
-	}
-
-	if a.Files.Token == "" || a.Files.Target == "" {
-		return ErrMissingBackupConfig
-	}
-
-	host := a.Files.Host
-
-	if host == "" || a.Files.User == "" || a.PrivateKeyPath == "" {
-		return ErrMissingSSHConfig
-	}
-
-	// Default to port 22 if not specified
-	if _, _, err := net.SplitHostPort(host); err != nil {
-		host = host + defaultSSHPort
-	}
-
-	privateKeyPEM, err := os.ReadFile(a.PrivateKeyPath)
+	a.durationHistogram, err = a.Registry.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    metricDirectoryDuration,
+		Help:    "Duration of successful directory backups in seconds",
+		Buckets: backupDurationBuckets,
+	}, []string{"job", "target"})
 	if err != nil {
-		return fmt.Errorf("failed to read private key file %s: %w", a.PrivateKeyPath, err)
+		return fmt.Errorf("creating %s metric: %w", metricDirectoryDuration, err)
 	}
 
-	client, err := sshclient.New(host, a.Files.User, string(privateKeyPEM))
-	if err != nil {
-		return fmt.Errorf("failed to create SSH client: %w", err)
+	for _, job := range a.Jobs {
+		if job.Token == "" || job.Target == "" {
+			return fmt.Errorf("job %q: %w", job.Name, ErrMissingBackupConfig)
+		}
+		if job.Host == "" || job.User == "" || job.PrivateKeyPath == "" {
+			return fmt.Errorf("job %q: %w", job.Name, ErrMissingSSHConfig)
+		}
 	}
-	a.sshClient = client
 	return nil
 }
 
 func (a *BackupDirs) Execute(ctx context.Context) error {
-	if a.Files.Target == "" {
+	if len(a.Jobs) == 0 {
 		return nil // nothing configured
 	}
 
 	return activity.CaptureError(a.StatusLine, func() error {
-		if a.sshClient == nil {
-			return ErrSSHClientNotInit
-		}
+		var jobErrors []error
+		notDue := 0
+		for i, job := range a.Jobs {
+			if ctx.Err() != nil {
+				jobErrors = append(jobErrors, fmt.Errorf("job %q not started: %w", job.Name, ctx.Err()))
+				continue
+			}
 
-		if len(a.Files.Sources) == 0 {
-			a.StatusLine.Set("no directories to backup")
-			return nil
+			progress := fmt.Sprintf("job %s (%d/%d)", job.Name, i+1, len(a.Jobs))
+			if !a.isDue(ctx, job) {
+				a.StatusLine.Set(fmt.Sprintf("%s: backed up within the last %s, skipping", progress, job.Interval))
+				notDue++
+				continue
+			}
+			if err := a.runJob(ctx, job, progress); err != nil {
+				jobErrors = append(jobErrors, fmt.Errorf("job %q failed: %w", job.Name, err))
+			}
 		}
 
-		a.StatusLine.Set(fmt.Sprintf("waiting for the PBS host to become available from %s", a.Files.Host))
+		// If any errors occurred, return a combined error
+		if len(jobErrors) > 0 {
+			errMsg := fmt.Sprintf("%d of %d file backup job(s) failed:", len(jobErrors), len(a.Jobs))
+			for _, err := range jobErrors {
+				errMsg += "\n  - " + err.Error()
+			}
+			return errors.New(errMsg)
+		}
 
-		// Test SSH connectivity before attempting backup
-		if err := a.waitForPBSHost(ctx); err != nil {
-			a.Logger.Error("host not accessible via SSH", "error", err)
-			return err
+		status := fmt.Sprintf("directory backup complete, %d job(s) succeeded", len(a.Jobs)-notDue)
+		if notDue > 0 {
+			status += fmt.Sprintf(", %d not due", notDue)
 		}
+		a.StatusLine.Set(status)
+		return nil
+	})
+}
 
-		a.StatusLine.Set(fmt.Sprintf("backing up %d directories", len(a.Files.Sources)))
+// isDue reports whether a job should be backed up. A job without an interval is always due.
+// If the job's snapshots can't be listed, the job is backed up anyway.
+func (a *BackupDirs) isDue(ctx context.Context, job config.FileJobConfig) bool {
+	if job.Interval <= 0 {
+		return true
+	}
+
+	logger := a.Logger.With("job", job.Name)
+	snapshots, err := a.PBSClient.ListSnapshots(ctx, datastoreFromTarget(job.Target), pbsclient.SnapshotFilter{
+		Namespace:  job.Namespace,
+		BackupType: "host",
+		BackupID:   job.BackupID,
+	})
+	if err != nil {
+		logger.Warn("Failed to list snapshots, backing up anyway", "error", err)
+		return true
+	}
 
-		err := a.backupAllDirs(a.Files.Sources)
-		if err != nil {
-			a.Logger.Error("Backup failed", "sources", a.Files.Sources, "error", err)
-			return err
+	var latest time.Time
+	for _, snapshot := range snapshots {
+		if snapshot.BackupTime.After(latest) {
+			latest = snapshot.BackupTime
 		}
-		a.Logger.Debug("Backup succeeded", "sources", a.Files.Sources)
+	}
+	if time.Since(latest) >= job.Interval {
+		return true
+	}
+	logger.Info("Skipping job, backed up within its interval", "last_backup", latest, "interval", job.Interval)
+	return false
+}
 
-		a.StatusLine.Set("directory backup complete")
-		return nil
-	})
+// runJob runs a single file backup job and records its metrics.
+func (a *BackupDirs) runJob(ctx context.Context, job config.FileJobConfig, progress string) error {
+	logger := a.Logger.With("job", job.Name, "host", job.Host)
+
+	started := time.Now()
+	err := a.backupJob(ctx, job, progress, logger)
+	a.recordJob(job, started, err)
+
+	labels := prometheus.Labels{"job": job.Name, "target": job.Target}
+	if err != nil {
+		logger.Error("Backup failed", "sources", job.Sources, "error", err)
+		a.failureCounter.With(labels).Inc()
+	} else {
+		logger.Debug("Backup succeeded", "sources", job.Sources)
+		a.lastBackupGauge.With(labels).Set(float64(time.Now().Unix()))
+		a.durationHistogram.With(labels).Observe(time.Since(started).Seconds())
+	}
+	return err
 }
 
-// backupAllDirs executes a single backup command with all sources combined
-// This enables PBS deduplication across all directories
-func (a *BackupDirs) backupAllDirs(sources []string) error {
-	token := a.Files.Token
-	target := a.Files.Target
+// recordJob adds a file backup job to the ledger, if one is configured.
+func (a *BackupDirs) recordJob(job config.FileJobConfig, started time.Time, err error) {
+	if a.Ledger == nil {
+		return
+	}
 
-	// Build the command with all sources in a single backup command
-	cmd := buildBackupCommand(token, target, sources)
+	entry := ledger.Entry{
+		ResourceID:   ledger.FilesID(job.Name),
+		Kind:         ledger.KindFiles,
+		Name:         job.Name,
+		Node:         job.Host,
+		StartedAt:    started,
+		EndedAt:      time.Now(),
+		Outcome:      ledger.OutcomeOf(err),
+		MaxBackupAge: job.MaxBackupAge,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	a.Ledger.Record(entry)
+}
+
+// backupJob connects to the job's host, waits for PBS and runs the backup command.
+func (a *BackupDirs) backupJob(ctx context.Context, job config.FileJobConfig, progress string, logger *slog.Logger) error {
+	a.StatusLine.Set(fmt.Sprintf("%s: connecting to %s", progress, job.Host))
+	client, err := connectSSH(job)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	a.StatusLine.Set(fmt.Sprintf("%s: waiting for the PBS host to become available from %s", progress, job.Host))
 
-	a.Logger.Debug("Running consolidated backup command", "command", cmd, "source_count", len(sources))
+	// Test PBS connectivity before attempting backup
+	if err := waitForPBSHost(ctx, client, job.Target, logger); err != nil {
+		return err
+	}
+
+	a.StatusLine.Set(fmt.Sprintf("%s: backing up %d directories", progress, len(job.Sources)))
+
+	// Build the command with all sources in a single backup command
+	// This enables PBS deduplication across all directories
+	cmd := buildBackupCommand(job)
+	logger.Debug("Running consolidated backup command", "source_count", len(job.Sources))
 
 	// Create line loggers for stdout and stderr
-	stdoutLogger := newLineLogger(a.Logger, slog.LevelDebug)
-	stderrLogger := newLineLogger(a.Logger, slog.LevelInfo)
+	stdoutLogger := newLineLogger(logger, slog.LevelDebug)
+	stderrLogger := newLineLogger(logger, slog.LevelInfo)
 	defer stdoutLogger.Close()
 	defer stderrLogger.Close()
 
-	err := a.sshClient.RunWithWriter(cmd, stdoutLogger, stderrLogger)
+	return client.RunWithWriter(cmd, stdoutLogger, stderrLogger)
+}
 
-	labels := prometheus.Labels{"target": target}
+// connectSSH opens an SSH connection to the job's host using its private key.
+func connectSSH(job config.FileJobConfig) (*sshclient.SSHClient, error) {
+	host := job.Host
+
+	// Default to port 22 if not specified
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = host + defaultSSHPort
+	}
+
+	privateKeyPEM, err := os.ReadFile(job.PrivateKeyPath)
 	if err != nil {
-		a.failureCounter.With(labels).Inc()
-	} else {
-		a.lastBackupGauge.With(labels).Set(float64(time.Now().Unix()))
+		return nil, fmt.Errorf("failed to read private key file %s: %w", job.PrivateKeyPath, err)
 	}
 
-	return err
+	client, err := sshclient.New(host, job.User, string(privateKeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SSH client: %w", err)
+	}
+	return client, nil
 }
 
-// buildBackupCommand constructs the PBS backup command with all sources
-func buildBackupCommand(token, target string, sources []string) string {
-	cmd := "export PBS_PASSWORD='" + token + "' && proxmox-backup-client backup"
-	for _, source := range sources {
-		cmd += " " + source
+// buildBackupCommand constructs the PBS backup command with all sources.
+// Every value from the config is quoted, so it reaches proxmox-backup-client unchanged.
+func buildBackupCommand(job config.FileJobConfig) string {
+	cmd := "export PBS_PASSWORD=" + shellQuote(job.Token) + " && proxmox-backup-client backup"
+	for _, source := range job.Sources {
+		cmd += " " + shellQuote(source)
+	}
+	cmd += " --repository " + shellQuote(job.Target)
+	if job.Namespace != "" {
+		cmd += " --ns " + shellQuote(job.Namespace)
+	}
+	if job.BackupID != "" {
+		cmd += " --backup-id " + shellQuote(job.BackupID)
+	}
+	for _, pattern := range job.Exclude {
+		cmd += " --exclude " + shellQuote(pattern)
 	}
-	cmd += " --repository '" + target + "'"
 	return cmd
 }
 
+// shellQuote quotes s as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
 // waitForPBSHost tests if PBS is reachable from the remote host before starting backup
-func (a *BackupDirs) waitForPBSHost(ctx context.Context) error {
+func waitForPBSHost(ctx context.Context, client *sshclient.SSHClient, target string, logger *slog.Logger) error {
 	// Extract hostname from target (format: user@host!datastore@hostname:port)
-	target := a.Files.Target
 	pbsHost := extractPBSHostFromTarget(target)
 	if pbsHost == "" {
 		return fmt.Errorf("could not extract PBS host from target: %s", target)
 	}
 
-	a.Logger.Debug("Testing PBS connectivity", "pbs_host", pbsHost, "max_retries", pbsConnectivityMaxRetries)
+	logger.Debug("Testing PBS connectivity", "pbs_host", pbsHost, "max_retries", pbsConnectivityMaxRetries)
 
 	for attempt := 1; attempt <= pbsConnectivityMaxRetries; attempt++ {
 		// Test connectivity using a simple nc (netcat) command
 		cmd := fmt.Sprintf("nc -z -w5 %s 8007 2>/dev/null", pbsHost)
-		_, _, err := a.sshClient.Run(cmd)
+		_, _, err := client.Run(cmd)
 		if err == nil {
-			a.Logger.Debug("PBS connectivity test successful", "pbs_host", pbsHost, "attempts", attempt)
+			logger.Debug("PBS connectivity test successful", "pbs_host", pbsHost, "attempts", attempt)
 			return nil
 		}
 
-		a.Logger.Warn("PBS connectivity test failed, retrying", "pbs_host", pbsHost, "attempt", attempt, "max_retries", pbsConnectivityMaxRetries, "error", err)
+		logger.Warn("PBS connectivity test failed, retrying", "pbs_host", pbsHost, "attempt", attempt, "max_retries", pbsConnectivityMaxRetries, "error", err)
 
 		if attempt < pbsConnectivityMaxRetries {
 			select {
@@ -277,3 +389,9 @@ func extractPBSHostFromTarget(target string) string {
 	}
 	return ""
 }
+
+// datastoreFromTarget extracts the datastore from a target repository string
+// Format: user@realm!token@hostname[:port]:datastore -> datastore
+func datastoreFromTarget(target string) string {
+	return target[strings.LastIndex(target, ":")+1:]
+}
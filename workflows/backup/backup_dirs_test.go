@@ -0,0 +1,184 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nomis52/goback/clients/pbsclient"
+	"github.com/nomis52/goback/config"
+)
+
+func TestBuildBackupCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		job  config.FileJobConfig
+		want string
+	}{
+		{
+			name: "sources only",
+			job: config.FileJobConfig{
+				Token:   "secret",
+				Target:  "user@pbs!token@pbs.example.com:store",
+				Sources: []string{"root.pxar:/", "home.pxar:/home"},
+			},
+			want: "export PBS_PASSWORD='secret' && proxmox-backup-client backup 'root.pxar:/' 'home.pxar:/home'" +
+				" --repository 'user@pbs!token@pbs.example.com:store'",
+		},
+		{
+			name: "namespace, backup id and excludes",
+			job: config.FileJobConfig{
+				Token:     "secret",
+				Target:    "user@pbs!token@pbs.example.com:store",
+				Namespace: "hosts/nas",
+				BackupID:  "nas01",
+				Sources:   []string{"data.pxar:/srv/data"},
+				Exclude:   []string{"*.tmp", "/srv/data/cache"},
+			},
+			want: "export PBS_PASSWORD='secret' && proxmox-backup-client backup 'data.pxar:/srv/data'" +
+				" --repository 'user@pbs!token@pbs.example.com:store'" +
+				" --ns 'hosts/nas' --backup-id 'nas01'" +
+				" --exclude '*.tmp' --exclude '/srv/data/cache'",
+		},
+		{
+			name: "quotes in values",
+			job: config.FileJobConfig{
+				Token:    "it's",
+				Target:   "user@pbs!token@pbs.example.com:store",
+				BackupID: "bob's; rm -rf /",
+				Sources:  []string{"root.pxar:/"},
+				Exclude:  []string{"'*.tmp'"},
+			},
+			want: `export PBS_PASSWORD='it'\''s' && proxmox-backup-client backup 'root.pxar:/'` +
+				` --repository 'user@pbs!token@pbs.example.com:store'` +
+				` --backup-id 'bob'\''s; rm -rf /'` +
+				` --exclude ''\''*.tmp'\'''`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, buildBackupCommand(tt.job))
+		})
+	}
+}
+
+func TestExtractPBSHostFromTarget(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{target: "user@pbs!token@pbs.example.com:store", want: "pbs.example.com"},
+		{target: "user@pbs!token@pbs.example.com", want: "pbs.example.com"},
+		{target: "pbs.example.com:store", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			assert.Equal(t, tt.want, extractPBSHostFromTarget(tt.target))
+		})
+	}
+}
+
+func TestDatastoreFromTarget(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{target: "user@pbs!token@pbs.example.com:store", want: "store"},
+		{target: "user@pbs!token@pbs.example.com:8007:store", want: "store"},
+		{target: "store", want: "store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			assert.Equal(t, tt.want, datastoreFromTarget(tt.target))
+		})
+	}
+}
+
+func TestBackupDirs_IsDue(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		interval  time.Duration
+		snapshots []time.Time
+		status    int
+		want      bool
+	}{
+		{
+			name: "no interval",
+			want: true,
+		},
+		{
+			name:     "no snapshots",
+			interval: 24 * time.Hour,
+			status:   http.StatusOK,
+			want:     true,
+		},
+		{
+			name:      "latest snapshot older than interval",
+			interval:  24 * time.Hour,
+			snapshots: []time.Time{now.Add(-72 * time.Hour), now.Add(-25 * time.Hour)},
+			status:    http.StatusOK,
+			want:      true,
+		},
+		{
+			name:      "latest snapshot within interval",
+			interval:  24 * time.Hour,
+			snapshots: []time.Time{now.Add(-72 * time.Hour), now.Add(-time.Hour)},
+			status:    http.StatusOK,
+		},
+		{
+			name:     "snapshots unavailable",
+			interval: 24 * time.Hour,
+			status:   http.StatusInternalServerError,
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api2/json/admin/datastore/store/snapshots", r.URL.Path)
+				assert.Equal(t, "hosts", r.URL.Query().Get("ns"))
+				assert.Equal(t, "host", r.URL.Query().Get("backup-type"))
+				assert.Equal(t, "nas01", r.URL.Query().Get("backup-id"))
+
+				w.WriteHeader(tt.status)
+				body := `{"data": [`
+				for i, snapshot := range tt.snapshots {
+					if i > 0 {
+						body += ","
+					}
+					body += fmt.Sprintf(`{"backup-type": "host", "backup-id": "nas01", "backup-time": %d}`, snapshot.Unix())
+				}
+				w.Write([]byte(body + "]}"))
+			}))
+			defer ts.Close()
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			client, err := pbsclient.New(ts.URL, pbsclient.WithLogger(logger))
+			require.NoError(t, err)
+
+			a := &BackupDirs{Logger: logger, PBSClient: client}
+			job := config.FileJobConfig{
+				Name:      "nas",
+				Target:    "user@pbs!token@pbs.example.com:store",
+				Namespace: "hosts",
+				BackupID:  "nas01",
+				Interval:  tt.interval,
+			}
+			assert.Equal(t, tt.want, a.isDue(context.Background(), job))
+		})
+	}
+}
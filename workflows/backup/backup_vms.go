@@ -12,16 +12,31 @@ import (
 
 	"github.com/nomis52/goback/activity"
 	"github.com/nomis52/goback/clients/proxmoxclient"
+	"github.com/nomis52/goback/config"
+	"github.com/nomis52/goback/ledger"
 	"github.com/nomis52/goback/metrics"
+	"github.com/nomis52/goback/workflow"
+	"github.com/nomis52/goback/workflows"
 )
 
 const (
 	backupStatusCheckInterval = 10 * time.Second
+	taskStopTimeout           = 30 * time.Second
+	taskLogPageSize           = 500
 	pbsStorageRetryInterval   = 5 * time.Second
 	pbsStorageMaxRetries      = 6 // 30 seconds total
 	backupProgressTemplate    = "Backing up VMs, %d/%d complete"
 	metricLastBackup          = "last_backup"
 	metricBackupFailure       = "backup_failure"
+	metricBackupDuration      = "backup_duration_seconds"
+	metricBackupSize          = "backup_size_bytes"
+)
+
+var (
+	// backupDurationBuckets spans backups that take a minute through to around eight hours.
+	backupDurationBuckets = prometheus.ExponentialBuckets(60, 2, 10)
+	// backupSizeBuckets spans backups of 1 MiB through to 4 TiB.
+	backupSizeBuckets = prometheus.ExponentialBuckets(1<<20, 4, 12)
 )
 
 // BackupVMs manages the execution of Proxmox backups
@@ -32,17 +47,42 @@ type BackupVMs struct {
 	PowerOnPBS    *PowerOnPBS
 	Registry      metrics.Registry
 	StatusLine    *activity.StatusLine
+	Ledger        ledger.Recorder  // optional
+	Window        workflows.Window // zero if the run has no deadline
 
 	// Configuration
-	BackupTimeout time.Duration `config:"proxmox.backup_timeout"`
-	Storage       string        `config:"proxmox.storage"`
-	MaxBackupAge  time.Duration `config:"compute.max_backup_age"`
-	Mode          string        `config:"compute.mode"`
-	Compress      string        `config:"compute.compress"`
+	BackupTimeout time.Duration             `config:"proxmox.backup_timeout"`
+	Storage       string                    `config:"proxmox.storage"`
+	MaxBackupAge  time.Duration             `config:"compute.max_backup_age"`
+	Mode          string                    `config:"compute.mode"`
+	Compress      string                    `config:"compute.compress"`
+	Include       []config.ResourceSelector `config:"compute.include"`
+	Exclude       []config.ResourceSelector `config:"compute.exclude"`
+	Overrides     []config.ComputeOverride  `config:"compute.overrides"`
+	MaxConcurrent int                       `config:"compute.max_concurrent"`
+	MaxPerNode    int                       `config:"compute.max_concurrent_per_node"`
+	Priority      string                    `config:"compute.priority"`
 
 	// Metrics (initialized in Init)
-	lastBackupGauge metrics.GaugeVec
-	failureCounter  metrics.CounterVec
+	lastBackupGauge   metrics.GaugeVec
+	failureCounter    metrics.CounterVec
+	durationHistogram metrics.HistogramVec
+	sizeHistogram     metrics.HistogramVec
+
+	// Outputs
+	Backups  workflow.Output[[]CompletedBackup]        // backups that finished successfully
+	Deferred workflow.Output[[]proxmoxclient.Resource] // backups not started before the window closed
+
+	mu        sync.Mutex
+	completed []CompletedBackup
+}
+
+// CompletedBackup is a backup whose vzdump task finished successfully.
+type CompletedBackup struct {
+	Resource proxmoxclient.Resource `json:"resource"`
+	Started  time.Time              `json:"started"` // when the backup was requested
+	TaskID   proxmoxclient.TaskID   `json:"task_id"`
+	Size     int64                  `json:"size"` // 0 if the size is unknown
 }
 
 func (a *BackupVMs) Init() error {
@@ -63,10 +103,31 @@ func (a *BackupVMs) Init() error {
 		return fmt.Errorf("creating %s metric: %w", metricBackupFailure, err)
 	}
 
+	a.durationHistogram, err = a.Registry.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    metricBackupDuration,
+		Help:    "Duration of successful backups in seconds",
+		Buckets: backupDurationBuckets,
+	}, []string{"vmid", "name"})
+	if err != nil {
+		return fmt.Errorf("creating %s metric: %w", metricBackupDuration, err)
+	}
+
+	a.sizeHistogram, err = a.Registry.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    metricBackupSize,
+		Help:    "Size of successful backups in bytes",
+		Buckets: backupSizeBuckets,
+	}, []string{"vmid", "name"})
+	if err != nil {
+		return fmt.Errorf("creating %s metric: %w", metricBackupSize, err)
+	}
+
 	return nil
 }
 
 func (a *BackupVMs) Execute(ctx context.Context) error {
+	// Publish the backups that succeeded, even if others failed
+	defer a.publishBackups()
+
 	return activity.CaptureError(a.StatusLine, func() error {
 		a.StatusLine.Set("checking Proxmox version")
 
@@ -95,47 +156,38 @@ func (a *BackupVMs) Execute(ctx context.Context) error {
 
 		a.StatusLine.Set(fmt.Sprintf(backupProgressTemplate, 0, len(resourcesToBackup)))
 
-		// Use wait group to track all backup operations
-		var wg sync.WaitGroup
-		errChan := make(chan error, len(resourcesToBackup))
+		// Run backups in priority order, bounded globally and per node
+		var mu sync.Mutex
+		var backupErrors []error
 		var completedCount atomic.Int32
 
-		// Launch backup operations concurrently
-		for _, resource := range resourcesToBackup {
-			wg.Add(1)
-			go func(r proxmoxclient.Resource) {
-				defer wg.Done()
-				if err := a.performBackupWithMetrics(ctx, r); err != nil {
-					a.Logger.Error("Failed to perform backup",
-						"vmid", r.VMID,
-						"name", r.Name,
-						"node", r.Node,
-						"error", err)
-					errChan <- fmt.Errorf("backup failed for VMID %d: %w", r.VMID, err)
-				}
-				// Update progress regardless of success/failure
-				completed := completedCount.Add(1)
-				a.StatusLine.Set(fmt.Sprintf(backupProgressTemplate, completed, len(resourcesToBackup)))
-			}(resource)
-		}
+		windowClosed := func() bool { return a.Window.Closed(time.Now()) }
+		notStarted := runScheduled(ctx, resourcesToBackup, a.MaxConcurrent, a.MaxPerNode, windowClosed, a.locator(resourcesToBackup), func(ctx context.Context, r proxmoxclient.Resource, err error) {
+			if err := a.performBackupWithMetrics(ctx, r, err); err != nil {
+				a.Logger.Error("Failed to perform backup",
+					"vmid", r.VMID,
+					"name", r.Name,
+					"node", r.Node,
+					"error", err)
+				mu.Lock()
+				backupErrors = append(backupErrors, fmt.Errorf("backup failed for VMID %d: %w", r.VMID, err))
+				mu.Unlock()
+			}
+			// Update progress regardless of success/failure
+			completed := completedCount.Add(1)
+			a.StatusLine.Set(fmt.Sprintf(backupProgressTemplate, completed, len(resourcesToBackup)))
+		})
 
-		// Wait for all backups to complete
-		wg.Wait()
-		close(errChan)
+		backupErrors = append(backupErrors, a.handleNotStarted(ctx, notStarted)...)
 
-		// Collect any errors that occurred
-		errors := make([]error, 0)
-		for err := range errChan {
-			errors = append(errors, err)
+		// If any errors occurred, return a combined error
+		if len(backupErrors) > 0 {
+			return backupFailures(backupErrors)
 		}
 
-		// If any errors occurred, return a combined error
-		if len(errors) > 0 {
-			errMsg := fmt.Sprintf("%d backup(s) failed:", len(errors))
-			for _, err := range errors {
-				errMsg += "\n  - " + err.Error()
-			}
-			return fmt.Errorf(errMsg)
+		if len(notStarted) > 0 {
+			a.StatusLine.Set(fmt.Sprintf("backup window closed, %d backup(s) deferred", len(notStarted)))
+			return nil
 		}
 
 		a.StatusLine.Set("backups complete")
@@ -144,32 +196,219 @@ func (a *BackupVMs) Execute(ctx context.Context) error {
 }
 
 // performBackupWithMetrics wraps performBackup and updates metrics based on the result.
-func (a *BackupVMs) performBackupWithMetrics(ctx context.Context, resource proxmoxclient.Resource) error {
-	err := a.performBackup(ctx, resource)
+// locateErr is the error from locating the resource, in which case the backup is recorded
+// as failed without being started.
+func (a *BackupVMs) performBackupWithMetrics(ctx context.Context, resource proxmoxclient.Resource, locateErr error) error {
+	started := time.Now()
+	var taskID proxmoxclient.TaskID
+	err := locateErr
+	if err == nil {
+		taskID, err = a.performBackup(ctx, resource)
+	}
 
 	labels := prometheus.Labels{
 		"vmid": fmt.Sprintf("%d", resource.VMID),
 		"name": resource.Name,
 	}
+	var size int64
 	if err != nil {
 		a.failureCounter.With(labels).Inc()
 	} else {
 		a.lastBackupGauge.With(labels).Set(float64(time.Now().Unix()))
+		a.durationHistogram.With(labels).Observe(time.Since(started).Seconds())
+		if size = a.backupSize(ctx, resource, started); size > 0 {
+			a.sizeHistogram.With(labels).Observe(float64(size))
+		}
+		a.mu.Lock()
+		a.completed = append(a.completed, CompletedBackup{Resource: resource, Started: started, TaskID: taskID, Size: size})
+		a.mu.Unlock()
 	}
+	a.recordBackup(resource, taskID, started, size, err)
 
 	return err
 }
 
+// publishBackups sets the Backups output to the backups that finished successfully.
+func (a *BackupVMs) publishBackups() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.Backups.Set(append([]CompletedBackup{}, a.completed...))
+}
+
+// handleNotStarted handles the resources runScheduled did not start. If the backup window
+// has closed they are deferred to the next run, even though the run is also cancelled once
+// the window's grace period has passed. Otherwise the run was cancelled before the deadline
+// and an error is returned for each of them.
+func (a *BackupVMs) handleNotStarted(ctx context.Context, notStarted []proxmoxclient.Resource) []error {
+	if len(notStarted) == 0 {
+		return nil
+	}
+	if a.Window.Closed(time.Now()) {
+		a.deferBackups(notStarted)
+		return nil
+	}
+
+	var errs []error
+	for _, r := range notStarted {
+		errs = append(errs, fmt.Errorf("backup not started for VMID %d: %w", r.VMID, ctx.Err()))
+	}
+	return errs
+}
+
+// deferBackups publishes the resources that were not backed up because the backup window
+// closed. Deferred backups are not failures, the resources are still due on the next run.
+func (a *BackupVMs) deferBackups(resources []proxmoxclient.Resource) {
+	for _, r := range resources {
+		a.Logger.Warn("Backup window closed, deferring backup",
+			"vmid", r.VMID,
+			"name", r.Name,
+			"node", r.Node,
+			"deadline", a.Window.Deadline)
+	}
+	a.Deferred.Set(resources)
+}
+
+// recordBackup adds a backup attempt to the ledger, if one is configured. size is 0 for
+// failed backups, or if the size of a successful backup is unknown.
+func (a *BackupVMs) recordBackup(resource proxmoxclient.Resource, taskID proxmoxclient.TaskID, started time.Time, size int64, err error) {
+	if a.Ledger == nil {
+		return
+	}
+
+	entry := ledger.Entry{
+		ResourceID:   ledger.ComputeID(int(resource.VMID)),
+		Kind:         ledger.KindCompute,
+		Name:         resource.Name,
+		Node:         resource.Node,
+		TaskID:       string(taskID),
+		StartedAt:    started,
+		EndedAt:      time.Now(),
+		Outcome:      ledger.OutcomeOf(err),
+		Size:         size,
+		MaxBackupAge: a.settingsFor(resource).MaxBackupAge,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	a.Ledger.Record(entry)
+}
+
+// backupSize returns the size of the resource's newest backup created since started,
+// or 0 if it can't be found. Failing to find the size is not a backup failure.
+func (a *BackupVMs) backupSize(ctx context.Context, resource proxmoxclient.Resource, started time.Time) int64 {
+	backups, err := a.ProxmoxClient.ListBackups(ctx, resource.Node, a.Storage)
+	if err != nil {
+		a.Logger.Warn("Failed to get backup size",
+			"vmid", resource.VMID,
+			"name", resource.Name,
+			"node", resource.Node,
+			"error", err)
+		return 0
+	}
+
+	// Backup times have a resolution of one second
+	since := started.Truncate(time.Second)
+	var latest *proxmoxclient.Backup
+	for i := range backups {
+		b := &backups[i]
+		if b.VMID == resource.VMID && !b.CTime.Before(since) && (latest == nil || b.CTime.After(latest.CTime)) {
+			latest = b
+		}
+	}
+	if latest == nil {
+		return 0
+	}
+	return latest.Size
+}
+
+// backupFailures is the combined error of the backups that failed. It wraps each backup's
+// error, so a backup that timed out makes the activity's Result.TimedOut() report true.
+type backupFailures []error
+
+func (e backupFailures) Error() string {
+	msg := fmt.Sprintf("%d backup(s) failed:", len(e))
+	for _, err := range e {
+		msg += "\n  - " + err.Error()
+	}
+	return msg
+}
+
+func (e backupFailures) Unwrap() []error {
+	return e
+}
+
+// locator returns the locate func for runScheduled, which lists the cluster's guests so
+// each backup runs on the node that hosts the guest now. Guests can migrate between nodes
+// while earlier backups run, and vzdump must run on the node that hosts the guest.
+func (a *BackupVMs) locator(scheduled []proxmoxclient.Resource) func(context.Context) (map[proxmoxclient.VMID]proxmoxclient.Resource, error) {
+	nodes := make(map[proxmoxclient.VMID]string, len(scheduled))
+	for _, r := range scheduled {
+		nodes[r.VMID] = r.Node
+	}
+
+	return func(ctx context.Context) (map[proxmoxclient.VMID]proxmoxclient.Resource, error) {
+		resources, err := a.ProxmoxClient.ListComputeResources(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate resources: %w", err)
+		}
+
+		located := make(map[proxmoxclient.VMID]proxmoxclient.Resource, len(resources))
+		for _, r := range resources {
+			located[r.VMID] = r
+			if node, ok := nodes[r.VMID]; ok && node != r.Node {
+				a.Logger.Info("Resource migrated since backups were scheduled",
+					"vmid", r.VMID,
+					"name", r.Name,
+					"from_node", node,
+					"to_node", r.Node)
+				nodes[r.VMID] = r.Node
+			}
+		}
+		return located, nil
+	}
+}
+
+// listBackups lists the backups in the backup storage, and returns the online nodes that
+// can back up to it. Shared storage, such as PBS, has the same content on every node so it
+// is listed from the first of them. Node-local storage is listed on all of them, so a guest
+// that migrated since its last backup still finds that backup.
+func (a *BackupVMs) listBackups(ctx context.Context) ([]proxmoxclient.Backup, map[string]bool, error) {
+	found, err := a.ProxmoxClient.FindStorage(ctx, a.Storage)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	storageNodes := make(map[string]bool, len(found))
+	for _, node := range found {
+		storageNodes[node.Node] = true
+	}
+	if found[0].IsShared() {
+		found = found[:1]
+	}
+
+	var backups []proxmoxclient.Backup
+	for _, node := range found {
+		nodeBackups, err := a.ProxmoxClient.ListBackups(ctx, node.Node, a.Storage)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list backups on %s: %w", node.Node, err)
+		}
+		backups = append(backups, nodeBackups...)
+	}
+	return backups, storageNodes, nil
+}
+
 // performBackup initiates a backup for a given resource and waits for it to complete.
-// It returns an error if the backup fails or times out.
-func (a *BackupVMs) performBackup(ctx context.Context, resource proxmoxclient.Resource) error {
+// It returns the backup's task ID, and an error if the backup fails or times out.
+func (a *BackupVMs) performBackup(ctx context.Context, resource proxmoxclient.Resource) (proxmoxclient.TaskID, error) {
+	settings := a.settingsFor(resource)
+
 	// Build backup options based on configuration
 	var backupOpts []proxmoxclient.BackupOption
-	if a.Mode != "" {
-		backupOpts = append(backupOpts, proxmoxclient.WithMode(a.Mode))
+	if settings.Mode != "" {
+		backupOpts = append(backupOpts, proxmoxclient.WithMode(settings.Mode))
 	}
-	if a.Compress != "" {
-		backupOpts = append(backupOpts, proxmoxclient.WithCompress(a.Compress))
+	if settings.Compress != "" {
+		backupOpts = append(backupOpts, proxmoxclient.WithCompress(settings.Compress))
 	}
 	// Disable email notifications by using legacy-sendmail mode with no mailto configured
 	backupOpts = append(backupOpts, proxmoxclient.WithNotificationMode("legacy-sendmail"))
@@ -180,8 +419,8 @@ func (a *BackupVMs) performBackup(ctx context.Context, resource proxmoxclient.Re
 		"name", resource.Name,
 		"node", resource.Node,
 		"storage", a.Storage,
-		"mode", a.Mode,
-		"compress", a.Compress)
+		"mode", settings.Mode,
+		"compress", settings.Compress)
 
 	taskID, err := a.ProxmoxClient.Backup(ctx, resource.Node, resource.VMID, a.Storage, backupOpts...)
 	if err != nil {
@@ -189,24 +428,30 @@ func (a *BackupVMs) performBackup(ctx context.Context, resource proxmoxclient.Re
 			"vmid", resource.VMID,
 			"name", resource.Name,
 			"node", resource.Node,
-			"mode", a.Mode,
-			"compress", a.Compress,
+			"mode", settings.Mode,
+			"compress", settings.Compress,
 			"error", err)
-		return err
+		return "", err
 	}
 
-	// Poll for task completion
+	// Poll for task completion, stopping the task if it runs past the backup timeout
+	taskCtx, cancel := context.WithTimeout(ctx, a.BackupTimeout)
+	defer cancel()
+
 	ticker := time.NewTicker(backupStatusCheckInterval)
 	defer ticker.Stop()
 
-	timeout := time.After(a.BackupTimeout)
+	// Number of task log lines already written to the activity log
+	logOffset := 0
 
 	for {
 		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-timeout:
-			return fmt.Errorf("backup timed out after %v for VMID %d", a.BackupTimeout, resource.VMID)
+		case <-taskCtx.Done():
+			a.stopBackupTask(taskCtx, resource, taskID)
+			if ctx.Err() != nil {
+				return taskID, ctx.Err()
+			}
+			return taskID, fmt.Errorf("%w: backup did not finish within %v: %w", workflow.ErrTimeout, a.BackupTimeout, taskCtx.Err())
 		case <-ticker.C:
 			status, err := a.ProxmoxClient.TaskStatus(ctx, resource.Node, taskID)
 			if err != nil {
@@ -216,7 +461,7 @@ func (a *BackupVMs) performBackup(ctx context.Context, resource proxmoxclient.Re
 					"node", resource.Node,
 					"task_id", taskID,
 					"error", err)
-				return err
+				return taskID, err
 			}
 
 			a.Logger.Debug("Backup task status",
@@ -227,24 +472,79 @@ func (a *BackupVMs) performBackup(ctx context.Context, resource proxmoxclient.Re
 				"status", status.Status,
 				"exit_status", status.ExitStatus)
 
+			// Fetch the log after the status so a stopped task's log is complete
+			logOffset = a.streamTaskLog(ctx, resource, taskID, logOffset)
+
 			// Check if task is complete
 			if status.Status == "stopped" {
 				if status.ExitStatus != "OK" {
-					return fmt.Errorf("backup failed with exit status: %s", status.ExitStatus)
+					return taskID, fmt.Errorf("backup failed with exit status: %s", status.ExitStatus)
 				}
 				a.Logger.Debug("Backup completed successfully",
 					"vmid", resource.VMID,
 					"name", resource.Name,
 					"node", resource.Node,
 					"task_id", taskID)
-				return nil
+				return taskID, nil
 			}
 		}
 	}
 }
 
+// streamTaskLog writes the vzdump task log lines after offset to the activity log, so the
+// run history contains the actual backup output. It returns the new offset.
+// Failing to fetch the log is not a backup failure.
+func (a *BackupVMs) streamTaskLog(ctx context.Context, resource proxmoxclient.Resource, taskID proxmoxclient.TaskID, offset int) int {
+	for {
+		lines, err := a.ProxmoxClient.TaskLog(ctx, resource.Node, taskID, offset, taskLogPageSize)
+		if err != nil {
+			a.Logger.Warn("Failed to get backup task log",
+				"vmid", resource.VMID,
+				"name", resource.Name,
+				"node", resource.Node,
+				"task_id", taskID,
+				"error", err)
+			return offset
+		}
+
+		for _, line := range lines {
+			a.Logger.Info(line.Text,
+				"vmid", resource.VMID,
+				"name", resource.Name,
+				"line", line.N)
+			offset = line.N
+		}
+
+		if len(lines) < taskLogPageSize {
+			return offset
+		}
+	}
+}
+
+// stopBackupTask stops an in-progress vzdump task after the run has been cancelled.
+// The request uses a fresh timeout since the run's context is already done.
+func (a *BackupVMs) stopBackupTask(ctx context.Context, resource proxmoxclient.Resource, taskID proxmoxclient.TaskID) {
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), taskStopTimeout)
+	defer cancel()
+
+	a.Logger.Warn("Stopping backup task",
+		"vmid", resource.VMID,
+		"name", resource.Name,
+		"node", resource.Node,
+		"task_id", taskID)
+
+	if err := a.ProxmoxClient.StopTask(stopCtx, resource.Node, taskID); err != nil {
+		a.Logger.Error("Failed to stop backup task",
+			"vmid", resource.VMID,
+			"name", resource.Name,
+			"node", resource.Node,
+			"task_id", taskID,
+			"error", err)
+	}
+}
+
 // determineBackups analyzes resources and their backup status to decide which ones need backing up.
-// It returns the resources that need to be backed up.
+// It returns the resources that need to be backed up, ordered by the configured priority.
 func (a *BackupVMs) determineBackups(ctx context.Context) ([]proxmoxclient.Resource, error) {
 	resources, err := a.ProxmoxClient.ListComputeResources(ctx)
 	if err != nil {
@@ -252,10 +552,17 @@ func (a *BackupVMs) determineBackups(ctx context.Context) ([]proxmoxclient.Resou
 		return nil, err
 	}
 
+	nodes, err := a.ProxmoxClient.ListNodes(ctx)
+	if err != nil {
+		a.Logger.Error("Failed to get list of nodes", "error", err)
+		return nil, err
+	}
+
 	// Retry PBS storage access with backoff since it may not be ready immediately
 	var backups []proxmoxclient.Backup
+	var storageNodes map[string]bool
 	for attempt := 1; attempt <= pbsStorageMaxRetries; attempt++ {
-		backups, err = a.ProxmoxClient.ListBackups(ctx, a.ProxmoxClient.Host(), a.Storage)
+		backups, storageNodes, err = a.listBackups(ctx)
 		if err == nil {
 			break // Success!
 		}
@@ -277,23 +584,68 @@ func (a *BackupVMs) determineBackups(ctx context.Context) ([]proxmoxclient.Resou
 		return nil, err
 	}
 
-	resourceMap := make(map[proxmoxclient.VMID]proxmoxclient.Resource, len(resources))
-	for _, resource := range resources {
+	selected := selectResources(resources, a.Include, a.Exclude)
+	if len(selected) != len(resources) {
+		a.Logger.Debug("Filtered resources by include/exclude selectors",
+			"total", len(resources),
+			"selected", len(selected))
+	}
+
+	resourceMap := make(map[proxmoxclient.VMID]proxmoxclient.Resource, len(selected))
+	for _, resource := range selected {
 		resourceMap[resource.VMID] = resource
 	}
 
+	lastBackups := getMostRecentBackupTimes(backups, selected)
+
+	online := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		online[node.Node] = node.Online()
+	}
+
 	var resourcesToBackup []proxmoxclient.Resource
-	for vmID, lastBackup := range getMostRecentBackupTimes(backups, resources) {
-		if lastBackup.IsZero() || time.Since(lastBackup) > a.MaxBackupAge {
-			if resource, exists := resourceMap[vmID]; exists {
-				resourcesToBackup = append(resourcesToBackup, resource)
-			}
+	for vmID, lastBackup := range lastBackups {
+		resource, exists := resourceMap[vmID]
+		if !exists {
+			continue
+		}
+		maxAge := a.settingsFor(resource).MaxBackupAge
+		if !lastBackup.IsZero() && time.Since(lastBackup) <= maxAge {
+			continue
 		}
+		var skipReason string
+		switch {
+		case !online[resource.Node]:
+			skipReason = "node is offline"
+		case !storageNodes[resource.Node]:
+			skipReason = fmt.Sprintf("storage %s is not available on the node", a.Storage)
+		}
+		if skipReason != "" {
+			a.Logger.Warn("Skipping backup of resource",
+				"vmid", resource.VMID,
+				"name", resource.Name,
+				"node", resource.Node,
+				"last_backup", lastBackup,
+				"reason", skipReason)
+			continue
+		}
+		resourcesToBackup = append(resourcesToBackup, resource)
 	}
 
+	sortByPriority(resourcesToBackup, lastBackups, a.Priority)
 	return resourcesToBackup, nil
 }
 
+// settingsFor returns the backup settings for a resource after applying any matching override.
+func (a *BackupVMs) settingsFor(resource proxmoxclient.Resource) backupSettings {
+	defaults := backupSettings{
+		MaxBackupAge: a.MaxBackupAge,
+		Mode:         a.Mode,
+		Compress:     a.Compress,
+	}
+	return resolveSettings(resource, defaults, a.Overrides)
+}
+
 // getMostRecentBackupTimes returns a map of VMID to the most recent backup time.
 // If a resource has no backups, it returns the zero time (time.Time{}).
 func getMostRecentBackupTimes(backups []proxmoxclient.Backup, resources []proxmoxclient.Resource) map[proxmoxclient.VMID]time.Time {
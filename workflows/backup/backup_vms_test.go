@@ -0,0 +1,359 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nomis52/goback/clients/proxmoxclient"
+	"github.com/nomis52/goback/ledger"
+	"github.com/nomis52/goback/logging"
+	"github.com/nomis52/goback/notify"
+	"github.com/nomis52/goback/workflow"
+	"github.com/nomis52/goback/workflows"
+)
+
+func TestStreamTaskLog(t *testing.T) {
+	// A task log one line longer than a page, so it takes two requests to read
+	var taskLog []string
+	for i := 1; i <= taskLogPageSize+1; i++ {
+		taskLog = append(taskLog, fmt.Sprintf("INFO: line %d", i))
+	}
+
+	tests := []struct {
+		name       string
+		offset     int
+		status     int
+		wantOffset int
+		wantLines  int
+	}{
+		{name: "pages through the whole log", offset: 0, status: http.StatusOK, wantOffset: taskLogPageSize + 1, wantLines: taskLogPageSize + 1},
+		{name: "only new lines", offset: taskLogPageSize - 1, status: http.StatusOK, wantOffset: taskLogPageSize + 1, wantLines: 2},
+		{name: "no new lines", offset: taskLogPageSize + 1, status: http.StatusOK, wantOffset: taskLogPageSize + 1},
+		{name: "errors keep the offset", offset: 10, status: http.StatusInternalServerError, wantOffset: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api2/json/nodes/pve1/tasks/UPID:pve1:vzdump/log", r.URL.Path)
+				if tt.status != http.StatusOK {
+					w.WriteHeader(tt.status)
+					return
+				}
+
+				start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+				limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+				var entries []string
+				for i := start; i < len(taskLog) && i < start+limit; i++ {
+					entries = append(entries, fmt.Sprintf(`{"n": %d, "t": %q}`, i+1, taskLog[i]))
+				}
+				w.Write([]byte(`{"data": [` + strings.Join(entries, ",") + `]}`))
+			}))
+			defer ts.Close()
+
+			client, err := proxmoxclient.New(ts.URL)
+			require.NoError(t, err)
+
+			collector := logging.NewLogCollector()
+			a := &BackupVMs{
+				ProxmoxClient: client,
+				Logger:        slog.New(logging.NewCapturingHandler(slog.NewTextHandler(io.Discard, nil), collector, "BackupVMs")),
+			}
+			resource := proxmoxclient.Resource{VMID: 100, Name: "web", Node: "pve1"}
+
+			offset := a.streamTaskLog(context.Background(), resource, "UPID:pve1:vzdump", tt.offset)
+			assert.Equal(t, tt.wantOffset, offset)
+
+			var lines []string
+			for _, entry := range collector.GetLogs("BackupVMs") {
+				if entry.Level == slog.LevelInfo.String() {
+					lines = append(lines, entry.Message)
+				}
+			}
+			assert.Len(t, lines, tt.wantLines)
+			if tt.wantLines > 0 {
+				assert.Equal(t, taskLog[tt.offset], lines[0], "streaming should resume after the offset")
+			}
+		})
+	}
+}
+
+// fakeCluster serves the Proxmox endpoints used to decide which resources to back up.
+type fakeCluster struct {
+	resources string
+	nodes     string
+	storage   map[string]string // node -> storage list
+	backups   map[string]string // node -> backup list
+}
+
+func (f *fakeCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api2/json")
+	switch {
+	case path == "/cluster/resources":
+		w.Write([]byte(`{"data": ` + f.resources + `}`))
+	case path == "/nodes":
+		w.Write([]byte(`{"data": ` + f.nodes + `}`))
+	case strings.HasSuffix(path, "/storage"):
+		node := strings.TrimSuffix(strings.TrimPrefix(path, "/nodes/"), "/storage")
+		w.Write([]byte(`{"data": ` + f.storage[node] + `}`))
+	case strings.HasSuffix(path, "/storage/backups/content"):
+		node := strings.TrimSuffix(strings.TrimPrefix(path, "/nodes/"), "/storage/backups/content")
+		backups, ok := f.backups[node]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data": ` + backups + `}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestDetermineBackups(t *testing.T) {
+	recent := time.Now().Add(-time.Hour).Unix()
+	old := time.Now().Add(-48 * time.Hour).Unix()
+	backup := func(vmid int, ctime int64) string {
+		return fmt.Sprintf(`{"volid": "backups:backup/vzdump-qemu-%d.vma.zst", "vmid": %d, "ctime": %d, "size": 1024}`, vmid, vmid, ctime)
+	}
+
+	const resources = `[
+		{"vmid": 100, "name": "fresh", "node": "pve1"},
+		{"vmid": 101, "name": "stale", "node": "pve1"},
+		{"vmid": 102, "name": "migrated", "node": "pve2"},
+		{"vmid": 103, "name": "offline", "node": "pve3"},
+		{"vmid": 104, "name": "no-storage", "node": "pve4"}
+	]`
+	const nodes = `[
+		{"node": "pve1", "status": "online"},
+		{"node": "pve2", "status": "online"},
+		{"node": "pve3", "status": "offline"},
+		{"node": "pve4", "status": "online"}
+	]`
+
+	tests := []struct {
+		name    string
+		storage string
+		backups map[string]string
+		want    []proxmoxclient.VMID
+	}{
+		{
+			name:    "shared storage is listed once",
+			storage: `[{"storage": "backups", "shared": 1, "enabled": 1, "active": 1}]`,
+			backups: map[string]string{
+				"pve1": "[" + backup(100, recent) + "," + backup(101, old) + "," + backup(102, recent) + "]",
+			},
+			want: []proxmoxclient.VMID{101},
+		},
+		{
+			name:    "local storage is listed on every node",
+			storage: `[{"storage": "backups", "shared": 0, "enabled": 1, "active": 1}]`,
+			backups: map[string]string{
+				// 102 was backed up on pve1 before it migrated to pve2
+				"pve1": "[" + backup(100, recent) + "," + backup(101, old) + "," + backup(102, recent) + "]",
+				"pve2": "[]",
+			},
+			want: []proxmoxclient.VMID{101},
+		},
+		{
+			name:    "migrated guest without a recent backup",
+			storage: `[{"storage": "backups", "shared": 0, "enabled": 1, "active": 1}]`,
+			backups: map[string]string{
+				"pve1": "[" + backup(100, recent) + "," + backup(101, recent) + "," + backup(102, old) + "]",
+				"pve2": "[]",
+			},
+			want: []proxmoxclient.VMID{102},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(&fakeCluster{
+				resources: resources,
+				nodes:     nodes,
+				storage: map[string]string{
+					"pve1": tt.storage,
+					"pve2": tt.storage,
+					"pve4": `[{"storage": "local", "enabled": 1, "active": 1}]`,
+				},
+				backups: tt.backups,
+			})
+			defer ts.Close()
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			client, err := proxmoxclient.New(ts.URL, proxmoxclient.WithLogger(logger))
+			require.NoError(t, err)
+
+			a := &BackupVMs{
+				ProxmoxClient: client,
+				Logger:        logger,
+				Storage:       "backups",
+				MaxBackupAge:  24 * time.Hour,
+			}
+
+			resources, err := a.determineBackups(context.Background())
+			require.NoError(t, err)
+
+			var got []proxmoxclient.VMID
+			for _, r := range resources {
+				got = append(got, r.VMID)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLocator(t *testing.T) {
+	ts := httptest.NewServer(&fakeCluster{
+		resources: `[{"vmid": 100, "name": "web", "node": "pve2"}]`,
+	})
+	defer ts.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client, err := proxmoxclient.New(ts.URL, proxmoxclient.WithLogger(logger))
+	require.NoError(t, err)
+	a := &BackupVMs{ProxmoxClient: client, Logger: logger}
+
+	locate := a.locator([]proxmoxclient.Resource{{VMID: 100, Name: "web", Node: "pve1"}})
+	located, err := locate(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, located, proxmoxclient.VMID(100))
+	assert.Equal(t, "pve2", located[100].Node, "the backup should run on the node the guest migrated to")
+	assert.NotContains(t, located, proxmoxclient.VMID(101))
+}
+
+func TestHandleNotStarted(t *testing.T) {
+	notStarted := []proxmoxclient.Resource{{VMID: 100, Node: "pve1"}, {VMID: 101, Node: "pve2"}}
+
+	tests := []struct {
+		name         string
+		window       workflows.Window
+		wantDeferred bool
+		wantErrs     int
+	}{
+		{
+			name:     "cancelled without a deadline",
+			wantErrs: 2,
+		},
+		{
+			name:     "cancelled before the deadline",
+			window:   workflows.Window{Deadline: time.Now().Add(time.Hour)},
+			wantErrs: 2,
+		},
+		{
+			name:         "cancelled after the deadline",
+			window:       workflows.Window{Deadline: time.Now().Add(-time.Hour), Grace: time.Minute},
+			wantDeferred: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			a := &BackupVMs{Logger: slog.New(slog.NewTextHandler(io.Discard, nil)), Window: tt.window}
+			errs := a.handleNotStarted(ctx, notStarted)
+
+			assert.Len(t, errs, tt.wantErrs)
+			for _, err := range errs {
+				assert.ErrorIs(t, err, context.Canceled)
+			}
+			deferred, ok := a.Deferred.Get()
+			assert.Equal(t, tt.wantDeferred, ok)
+			if tt.wantDeferred {
+				assert.Equal(t, notStarted, deferred)
+			}
+		})
+	}
+}
+
+func TestPerformBackup_Timeout(t *testing.T) {
+	var stopped atomic.Bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api2/json/nodes/pve1/vzdump":
+			w.Write([]byte(`{"data": "UPID:pve1:vzdump"}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/api2/json/nodes/pve1/tasks/UPID:pve1:vzdump":
+			stopped.Store(true)
+			w.Write([]byte(`{"data": null}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client, err := proxmoxclient.New(ts.URL, proxmoxclient.WithLogger(logger))
+	require.NoError(t, err)
+
+	// The timeout expires before the first task status check
+	a := &BackupVMs{ProxmoxClient: client, Logger: logger, Storage: "backups", BackupTimeout: time.Millisecond}
+	_, err = a.performBackup(context.Background(), proxmoxclient.Resource{VMID: 100, Node: "pve1"})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, stopped.Load(), "the timed out task should be stopped")
+
+	result := workflow.Result{State: workflow.Completed, Error: backupFailures{fmt.Errorf("backup failed for VMID 100: %w", err)}}
+	assert.True(t, result.TimedOut(), "a backup timeout should be reported as the activity timing out")
+}
+
+func TestRecordBackup(t *testing.T) {
+	started := time.Now().Add(-time.Minute)
+	ts := httptest.NewServer(&fakeCluster{
+		backups: map[string]string{
+			"pve1": fmt.Sprintf(`[
+				{"volid": "backups:backup/old", "vmid": 100, "ctime": %d, "size": 1024},
+				{"volid": "backups:backup/new", "vmid": 100, "ctime": %d, "size": 2048},
+				{"volid": "backups:backup/other", "vmid": 101, "ctime": %d, "size": 4096}
+			]`, started.Add(-24*time.Hour).Unix(), started.Add(30*time.Second).Unix(), started.Add(30*time.Second).Unix()),
+		},
+	})
+	defer ts.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client, err := proxmoxclient.New(ts.URL, proxmoxclient.WithLogger(logger))
+	require.NoError(t, err)
+
+	collector := ledger.NewCollector()
+	a := &BackupVMs{
+		ProxmoxClient: client,
+		Logger:        logger,
+		Ledger:        collector.Recorder("BackupVMs"),
+		Storage:       "backups",
+		MaxBackupAge:  24 * time.Hour,
+	}
+	resource := proxmoxclient.Resource{VMID: 100, Name: "web", Node: "pve1"}
+
+	size := a.backupSize(context.Background(), resource, started)
+	a.recordBackup(resource, "UPID:pve1:vzdump:ok", started, size, nil)
+	a.recordBackup(resource, "UPID:pve1:vzdump:failed", started, 0, errors.New("backup failed with exit status: ERROR"))
+
+	entries := collector.Get("BackupVMs")
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "compute-100", entries[0].ResourceID)
+	assert.Equal(t, ledger.KindCompute, entries[0].Kind)
+	assert.Equal(t, "web", entries[0].Name)
+	assert.Equal(t, "pve1", entries[0].Node)
+	assert.Equal(t, "UPID:pve1:vzdump:ok", entries[0].TaskID)
+	assert.Equal(t, notify.OutcomeSuccess, entries[0].Outcome)
+	assert.Equal(t, int64(2048), entries[0].Size, "size of the backup made since started")
+	assert.Equal(t, 24*time.Hour, entries[0].MaxBackupAge)
+
+	assert.Equal(t, notify.OutcomeFailure, entries[1].Outcome)
+	assert.Equal(t, "backup failed with exit status: ERROR", entries[1].Error)
+	assert.Zero(t, entries[1].Size)
+}
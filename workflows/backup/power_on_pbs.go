@@ -6,19 +6,25 @@ import (
 	"log/slog"
 	"time"
 
-	"github.com/nomis52/goback/clients/ipmiclient"
-	"github.com/nomis52/goback/clients/pbsclient"
 	"github.com/nomis52/goback/activity"
+	"github.com/nomis52/goback/clients/pbsclient"
+	"github.com/nomis52/goback/power"
+	"github.com/nomis52/goback/workflow"
 )
 
 const (
 	pingCheckInterval = 5 * time.Second
+
+	// PBS occasionally fails to come up on first boot, so powering on is retried.
+	powerOnMaxAttempts    = 3
+	powerOnInitialBackoff = 30 * time.Second
+	powerOnMaxBackoff     = 2 * time.Minute
 )
 
-// PowerOnPBS manages the power state of the PBS host through IPMI
+// PowerOnPBS manages the power state of the PBS host through the configured power backend
 type PowerOnPBS struct {
 	// Dependencies
-	Controller *ipmiclient.IPMIController
+	Controller power.PowerController
 	PBSClient  *pbsclient.Client
 	Logger     *slog.Logger
 	StatusLine *activity.StatusLine
@@ -31,21 +37,36 @@ func (a *PowerOnPBS) Init() error {
 	return nil
 }
 
+// RetryPolicy implements workflow.Retryable.
+func (a *PowerOnPBS) RetryPolicy() workflow.RetryPolicy {
+	return workflow.RetryPolicy{
+		MaxAttempts:    powerOnMaxAttempts,
+		InitialBackoff: powerOnInitialBackoff,
+		MaxBackoff:     powerOnMaxBackoff,
+	}
+}
+
+// Timeout implements workflow.TimeLimited. Each attempt may take up to BootTimeout for PBS
+// to respond, plus ServiceWaitTime for its services to start.
+func (a *PowerOnPBS) Timeout() time.Duration {
+	return a.BootTimeout + a.ServiceWaitTime
+}
+
 func (a *PowerOnPBS) Execute(ctx context.Context) error {
 	return activity.CaptureError(a.StatusLine, func() error {
 		a.StatusLine.Set("checking PBS power status")
 
 		// Check current power status
-		status, err := a.Controller.Status()
+		status, err := a.Controller.Status(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to get power status: %w", err)
 		}
 		a.Logger.Debug("current PBS power status", "status", status)
 
 		// If power is off, turn it on
-		if status == ipmiclient.PowerStateOff {
-			a.StatusLine.Set("sending IPMI power on command")
-			if err := a.Controller.PowerOn(); err != nil {
+		if status == power.PowerStateOff {
+			a.StatusLine.Set("sending power on command")
+			if err := a.Controller.PowerOn(ctx); err != nil {
 				a.Logger.Error("failed to power on PBS host", "error", err)
 				return fmt.Errorf("failed to power on PBS host: %w", err)
 			}
@@ -63,14 +84,12 @@ func (a *PowerOnPBS) Execute(ctx context.Context) error {
 		ticker := time.NewTicker(pingCheckInterval)
 		defer ticker.Stop()
 
-		timeout := time.After(a.BootTimeout)
+		// The orchestrator cancels ctx once Timeout() has passed
 		attempts := 0
 		for {
 			select {
 			case <-ctx.Done():
 				return fmt.Errorf("context cancelled while waiting for PBS: %w", ctx.Err())
-			case <-timeout:
-				return fmt.Errorf("timed out waiting for PBS to become available after %v", a.BootTimeout)
 			case <-ticker.C:
 				attempts++
 				_, err := a.PBSClient.Ping()
@@ -0,0 +1,109 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/nomis52/goback/clients/proxmoxclient"
+)
+
+const (
+	// PriorityOldestFirst backs up resources with the oldest (or no) backup first.
+	PriorityOldestFirst = "oldest_first"
+	// PriorityVMID backs up resources in ascending VMID order.
+	PriorityVMID = "vmid"
+)
+
+// sortByPriority orders resources in place according to the priority setting.
+// Ties are broken by VMID so the order is stable between runs.
+func sortByPriority(resources []proxmoxclient.Resource, lastBackups map[proxmoxclient.VMID]time.Time, priority string) {
+	slices.SortStableFunc(resources, func(a, b proxmoxclient.Resource) int {
+		if priority != PriorityVMID {
+			if c := lastBackups[a.VMID].Compare(lastBackups[b.VMID]); c != 0 {
+				return c
+			}
+		}
+		return int(a.VMID) - int(b.VMID)
+	})
+}
+
+// runScheduled calls run for each resource in order, keeping at most maxConcurrent
+// calls in flight overall and at most maxPerNode per Proxmox node. A maxConcurrent of
+// zero means unlimited; maxPerNode comes from compute.max_concurrent_per_node, which
+// defaults to one. When a node is at its limit, later resources on other nodes are
+// started ahead of it so one busy node does not hold up the rest.
+//
+// Guests can migrate while earlier calls run, so each round of starts first calls locate
+// to find the node every guest is on now, and a resource's slot is taken on that node. A
+// resource that moved to a node at its limit waits for a slot there. If locate fails or
+// the guest no longer exists, run is called with the scheduled resource and the error.
+//
+// No new calls are started once ctx is done or closed reports that the backup window
+// has closed.
+// runScheduled waits for in-flight calls to return and then returns the resources
+// that were never started.
+func runScheduled(ctx context.Context, resources []proxmoxclient.Resource, maxConcurrent, maxPerNode int, closed func() bool,
+	locate func(context.Context) (map[proxmoxclient.VMID]proxmoxclient.Resource, error),
+	run func(context.Context, proxmoxclient.Resource, error)) []proxmoxclient.Resource {
+	pending := slices.Clone(resources)
+	perNode := make(map[string]int)
+	running := 0
+	done := make(chan string)
+
+	for {
+		if ctx.Err() == nil && !closed() {
+			var located map[proxmoxclient.VMID]proxmoxclient.Resource
+			var locateErr error
+			locatedThisRound := false
+			for i := 0; i < len(pending); {
+				if maxConcurrent > 0 && running >= maxConcurrent {
+					break
+				}
+				if maxPerNode > 0 && perNode[pending[i].Node] >= maxPerNode {
+					i++
+					continue
+				}
+
+				// Only list the cluster's guests once a resource could be started
+				if !locatedThisRound {
+					located, locateErr = locate(ctx)
+					locatedThisRound = true
+				}
+				r, err := pending[i], locateErr
+				if err == nil {
+					current, ok := located[r.VMID]
+					if !ok {
+						err = fmt.Errorf("VMID %d no longer exists", r.VMID)
+					} else {
+						r = current
+					}
+				}
+				if err == nil && maxPerNode > 0 && perNode[r.Node] >= maxPerNode {
+					// Migrated to a busy node, wait for a slot there
+					pending[i] = r
+					i++
+					continue
+				}
+
+				pending = slices.Delete(pending, i, i+1)
+				running++
+				perNode[r.Node]++
+				go func() {
+					run(ctx, r, err)
+					done <- r.Node
+				}()
+			}
+		}
+
+		if running == 0 {
+			return pending
+		}
+
+		// Wait for a call to finish before trying to start more
+		node := <-done
+		running--
+		perNode[node]--
+	}
+}
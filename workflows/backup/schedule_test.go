@@ -0,0 +1,338 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nomis52/goback/clients/proxmoxclient"
+)
+
+func TestSortByPriority(t *testing.T) {
+	now := time.Now()
+	lastBackups := map[proxmoxclient.VMID]time.Time{
+		100: now.Add(-time.Hour),
+		101: {},
+		102: now.Add(-48 * time.Hour),
+		103: now.Add(-48 * time.Hour),
+	}
+
+	tests := []struct {
+		name     string
+		priority string
+		want     []proxmoxclient.VMID
+	}{
+		{
+			name:     "oldest first",
+			priority: PriorityOldestFirst,
+			want:     []proxmoxclient.VMID{101, 102, 103, 100},
+		},
+		{
+			name:     "vmid",
+			priority: PriorityVMID,
+			want:     []proxmoxclient.VMID{100, 101, 102, 103},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources := []proxmoxclient.Resource{{VMID: 103}, {VMID: 100}, {VMID: 102}, {VMID: 101}}
+			sortByPriority(resources, lastBackups, tt.priority)
+
+			got := make([]proxmoxclient.VMID, 0, len(resources))
+			for _, r := range resources {
+				got = append(got, r.VMID)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRunScheduled(t *testing.T) {
+	resources := []proxmoxclient.Resource{
+		{VMID: 100, Node: "pve1"},
+		{VMID: 101, Node: "pve1"},
+		{VMID: 102, Node: "pve2"},
+		{VMID: 103, Node: "pve1"},
+		{VMID: 104, Node: "pve2"},
+		{VMID: 105, Node: "pve3"},
+	}
+
+	tests := []struct {
+		name          string
+		maxConcurrent int
+		maxPerNode    int
+		wantMax       int
+		wantMaxNode   int
+	}{
+		{
+			name:        "unlimited",
+			wantMax:     len(resources),
+			wantMaxNode: 3,
+		},
+		{
+			name:          "global limit",
+			maxConcurrent: 2,
+			wantMax:       2,
+			wantMaxNode:   2,
+		},
+		{
+			name:        "per node limit",
+			maxPerNode:  1,
+			wantMax:     3,
+			wantMaxNode: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newConcurrencyTracker()
+
+			notStarted := runScheduled(context.Background(), resources, tt.maxConcurrent, tt.maxPerNode, notClosed, asScheduled(resources), tracker.run)
+
+			assert.Empty(t, notStarted)
+			assert.Len(t, tracker.started, len(resources))
+			assert.LessOrEqual(t, tracker.maxRunning, tt.wantMax)
+			for node, max := range tracker.maxPerNode {
+				assert.LessOrEqual(t, max, tt.wantMaxNode, "node %s", node)
+			}
+		})
+	}
+}
+
+func TestRunScheduled_Order(t *testing.T) {
+	resources := []proxmoxclient.Resource{
+		{VMID: 100, Node: "pve1"},
+		{VMID: 101, Node: "pve1"},
+		{VMID: 102, Node: "pve2"},
+	}
+	tracker := newConcurrencyTracker()
+
+	runScheduled(context.Background(), resources, 1, 0, notClosed, asScheduled(resources), tracker.run)
+
+	assert.Equal(t, []proxmoxclient.VMID{100, 101, 102}, tracker.started)
+}
+
+func TestRunScheduled_Cancelled(t *testing.T) {
+	resources := []proxmoxclient.Resource{
+		{VMID: 100, Node: "pve1"},
+		{VMID: 101, Node: "pve1"},
+		{VMID: 102, Node: "pve1"},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	tracker := newConcurrencyTracker()
+
+	notStarted := runScheduled(ctx, resources, 0, 1, notClosed, asScheduled(resources), func(ctx context.Context, r proxmoxclient.Resource, err error) {
+		tracker.run(ctx, r, err)
+		cancel()
+	})
+
+	assert.Equal(t, []proxmoxclient.VMID{100}, tracker.started)
+	require.Len(t, notStarted, 2)
+	assert.Equal(t, proxmoxclient.VMID(101), notStarted[0].VMID)
+	assert.Equal(t, proxmoxclient.VMID(102), notStarted[1].VMID)
+}
+
+func TestRunScheduled_WindowClosed(t *testing.T) {
+	t.Run("closed before the first backup", func(t *testing.T) {
+		resources := []proxmoxclient.Resource{
+			{VMID: 100, Node: "pve1"},
+			{VMID: 101, Node: "pve2"},
+		}
+		tracker := newConcurrencyTracker()
+
+		closed := func() bool { return true }
+		notStarted := runScheduled(context.Background(), resources, 0, 0, closed, asScheduled(resources), tracker.run)
+
+		assert.Empty(t, tracker.started)
+		assert.Equal(t, resources, notStarted)
+	})
+
+	t.Run("closed during a backup", func(t *testing.T) {
+		resources := []proxmoxclient.Resource{
+			{VMID: 100, Node: "pve1"},
+			{VMID: 101, Node: "pve1"},
+			{VMID: 102, Node: "pve1"},
+		}
+		tracker := newConcurrencyTracker()
+
+		var windowClosed atomic.Bool
+		notStarted := runScheduled(context.Background(), resources, 0, 1, windowClosed.Load, asScheduled(resources), func(ctx context.Context, r proxmoxclient.Resource, err error) {
+			tracker.run(ctx, r, err)
+			// Close the window while the first backup is in progress
+			windowClosed.Store(true)
+			assert.NoError(t, ctx.Err(), "backups in progress are not stopped")
+		})
+
+		assert.Equal(t, []proxmoxclient.VMID{100}, tracker.started)
+		require.Len(t, notStarted, 2)
+		assert.Equal(t, proxmoxclient.VMID(101), notStarted[0].VMID)
+		assert.Equal(t, proxmoxclient.VMID(102), notStarted[1].VMID)
+	})
+}
+
+func TestRunScheduled_Migrated(t *testing.T) {
+	resources := []proxmoxclient.Resource{
+		{VMID: 100, Node: "pve1"},
+		{VMID: 101, Node: "pve2"},
+		{VMID: 102, Node: "pve2"},
+	}
+	// 101 migrated to pve1 after the backups were scheduled
+	locate := func(context.Context) (map[proxmoxclient.VMID]proxmoxclient.Resource, error) {
+		return map[proxmoxclient.VMID]proxmoxclient.Resource{
+			100: {VMID: 100, Node: "pve1"},
+			101: {VMID: 101, Node: "pve1"},
+			102: {VMID: 102, Node: "pve2"},
+		}, nil
+	}
+	tracker := newConcurrencyTracker()
+
+	// Hold the first two backups until both have started, so a second backup on pve1
+	// would overlap the first
+	release := make(chan struct{})
+	var started atomic.Int32
+	notStarted := runScheduled(context.Background(), resources, 0, 1, notClosed, locate, func(_ context.Context, r proxmoxclient.Resource, err error) {
+		assert.NoError(t, err)
+		tracker.start(r)
+		if started.Add(1) == 2 {
+			close(release)
+		}
+		<-release
+		tracker.finish(r)
+	})
+
+	assert.Empty(t, notStarted)
+	assert.ElementsMatch(t, []proxmoxclient.VMID{100, 101, 102}, tracker.started)
+	assert.Equal(t, 1, tracker.maxPerNode["pve1"])
+	assert.Equal(t, proxmoxclient.VMID(101), tracker.started[2], "101 should wait for the pve1 slot")
+}
+
+func TestRunScheduled_LocateFailed(t *testing.T) {
+	resources := []proxmoxclient.Resource{
+		{VMID: 100, Node: "pve1"},
+		{VMID: 101, Node: "pve1"},
+	}
+	// 101 was removed after the backups were scheduled
+	locate := func(context.Context) (map[proxmoxclient.VMID]proxmoxclient.Resource, error) {
+		return map[proxmoxclient.VMID]proxmoxclient.Resource{100: resources[0]}, nil
+	}
+
+	var mu sync.Mutex
+	errs := make(map[proxmoxclient.VMID]error)
+	notStarted := runScheduled(context.Background(), resources, 0, 0, notClosed, locate, func(_ context.Context, r proxmoxclient.Resource, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs[r.VMID] = err
+	})
+
+	assert.Empty(t, notStarted)
+	require.Len(t, errs, 2)
+	assert.NoError(t, errs[100])
+	assert.EqualError(t, errs[101], "VMID 101 no longer exists")
+}
+
+func TestRunScheduled_ListFailed(t *testing.T) {
+	resources := []proxmoxclient.Resource{
+		{VMID: 100, Node: "pve1"},
+		{VMID: 101, Node: "pve2"},
+	}
+	locate := func(context.Context) (map[proxmoxclient.VMID]proxmoxclient.Resource, error) {
+		return nil, errors.New("failed to locate resources")
+	}
+
+	var mu sync.Mutex
+	var started []proxmoxclient.Resource
+	notStarted := runScheduled(context.Background(), resources, 0, 0, notClosed, locate, func(_ context.Context, r proxmoxclient.Resource, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		started = append(started, r)
+		assert.EqualError(t, err, "failed to locate resources")
+	})
+
+	assert.Empty(t, notStarted)
+	assert.ElementsMatch(t, resources, started, "the scheduled resources should be passed to run")
+}
+
+func TestRunScheduled_LocatesOncePerRound(t *testing.T) {
+	resources := []proxmoxclient.Resource{
+		{VMID: 100, Node: "pve1"},
+		{VMID: 101, Node: "pve1"},
+		{VMID: 102, Node: "pve2"},
+		{VMID: 103, Node: "pve3"},
+	}
+	var calls atomic.Int32
+	locate := func(ctx context.Context) (map[proxmoxclient.VMID]proxmoxclient.Resource, error) {
+		calls.Add(1)
+		return asScheduled(resources)(ctx)
+	}
+	tracker := newConcurrencyTracker()
+
+	// The first round starts 100, 102 and 103, and 101 starts in the round after 100
+	// finishes. Rounds while pve1 is busy can't start anything so don't locate.
+	notStarted := runScheduled(context.Background(), resources, 0, 1, notClosed, locate, tracker.run)
+
+	assert.Empty(t, notStarted)
+	assert.Len(t, tracker.started, len(resources))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// notClosed is a closed func for a backup window that never closes.
+func notClosed() bool {
+	return false
+}
+
+// asScheduled returns a locate func for resources that have not migrated.
+func asScheduled(resources []proxmoxclient.Resource) func(context.Context) (map[proxmoxclient.VMID]proxmoxclient.Resource, error) {
+	return func(context.Context) (map[proxmoxclient.VMID]proxmoxclient.Resource, error) {
+		located := make(map[proxmoxclient.VMID]proxmoxclient.Resource, len(resources))
+		for _, r := range resources {
+			located[r.VMID] = r
+		}
+		return located, nil
+	}
+}
+
+// concurrencyTracker records the start order and the peak number of concurrent calls.
+type concurrencyTracker struct {
+	mu         sync.Mutex
+	started    []proxmoxclient.VMID
+	running    int
+	maxRunning int
+	perNode    map[string]int
+	maxPerNode map[string]int
+}
+
+func newConcurrencyTracker() *concurrencyTracker {
+	return &concurrencyTracker{
+		perNode:    make(map[string]int),
+		maxPerNode: make(map[string]int),
+	}
+}
+
+func (c *concurrencyTracker) run(_ context.Context, r proxmoxclient.Resource, _ error) {
+	c.start(r)
+	c.finish(r)
+}
+
+func (c *concurrencyTracker) start(r proxmoxclient.Resource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.started = append(c.started, r.VMID)
+	c.running++
+	c.perNode[r.Node]++
+	c.maxRunning = max(c.maxRunning, c.running)
+	c.maxPerNode[r.Node] = max(c.maxPerNode[r.Node], c.perNode[r.Node])
+}
+
+func (c *concurrencyTracker) finish(r proxmoxclient.Resource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.running--
+	c.perNode[r.Node]--
+}
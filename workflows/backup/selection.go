@@ -0,0 +1,94 @@
+package backup
+
+import (
+	"path"
+	"slices"
+	"time"
+
+	"github.com/nomis52/goback/clients/proxmoxclient"
+	"github.com/nomis52/goback/config"
+)
+
+// backupSettings holds the effective backup settings for a single resource.
+type backupSettings struct {
+	MaxBackupAge time.Duration
+	Mode         string
+	Compress     string
+}
+
+// selectResources returns the resources matching the include list and not matching
+// any exclude selector. An empty include list selects every resource.
+func selectResources(resources []proxmoxclient.Resource, include, exclude []config.ResourceSelector) []proxmoxclient.Resource {
+	selected := make([]proxmoxclient.Resource, 0, len(resources))
+	for _, r := range resources {
+		if len(include) > 0 && !matchesAny(include, r) {
+			continue
+		}
+		if matchesAny(exclude, r) {
+			continue
+		}
+		selected = append(selected, r)
+	}
+	return selected
+}
+
+// resolveSettings returns the backup settings for a resource, applying the first matching
+// override on top of the defaults.
+func resolveSettings(r proxmoxclient.Resource, defaults backupSettings, overrides []config.ComputeOverride) backupSettings {
+	for _, o := range overrides {
+		if !matches(o.Match, r) {
+			continue
+		}
+		settings := defaults
+		if o.MaxBackupAge > 0 {
+			settings.MaxBackupAge = o.MaxBackupAge
+		}
+		if o.Mode != "" {
+			settings.Mode = o.Mode
+		}
+		if o.Compress != "" {
+			settings.Compress = o.Compress
+		}
+		return settings
+	}
+	return defaults
+}
+
+// matchesAny returns true if the resource matches at least one selector.
+func matchesAny(selectors []config.ResourceSelector, r proxmoxclient.Resource) bool {
+	for _, s := range selectors {
+		if matches(s, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns true if the resource satisfies every criterion set on the selector.
+func matches(s config.ResourceSelector, r proxmoxclient.Resource) bool {
+	if len(s.VMIDs) > 0 && !slices.Contains(s.VMIDs, int(r.VMID)) {
+		return false
+	}
+	if len(s.Names) > 0 && !slices.ContainsFunc(s.Names, func(pattern string) bool {
+		ok, _ := path.Match(pattern, r.Name) // patterns are validated at config load
+		return ok
+	}) {
+		return false
+	}
+	if len(s.Nodes) > 0 && !slices.Contains(s.Nodes, r.Node) {
+		return false
+	}
+	if len(s.Tags) > 0 {
+		tags := r.TagList()
+		if !slices.ContainsFunc(s.Tags, func(tag string) bool { return slices.Contains(tags, tag) }) {
+			return false
+		}
+	}
+	if len(s.Pools) > 0 && !slices.Contains(s.Pools, r.Pool) {
+		return false
+	}
+	if s.Template != nil && *s.Template != r.IsTemplate() {
+		return false
+	}
+	return true
+}
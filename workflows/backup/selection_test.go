@@ -0,0 +1,108 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nomis52/goback/clients/proxmoxclient"
+	"github.com/nomis52/goback/config"
+)
+
+var testResources = []proxmoxclient.Resource{
+	{VMID: 100, Name: "web-1", Node: "pve1", Tags: "prod"},
+	{VMID: 101, Name: "scratch-a", Node: "pve1"},
+	{VMID: 102, Name: "media", Node: "pve2", Tags: "media;bulk", Pool: "large"},
+	{VMID: 9000, Name: "debian-template", Node: "pve2", Template: 1},
+}
+
+func TestSelectResources(t *testing.T) {
+	isTemplate := true
+
+	tests := []struct {
+		name    string
+		include []config.ResourceSelector
+		exclude []config.ResourceSelector
+		want    []proxmoxclient.VMID
+	}{
+		{
+			name: "no selectors",
+			want: []proxmoxclient.VMID{100, 101, 102, 9000},
+		},
+		{
+			name:    "include by vmid",
+			include: []config.ResourceSelector{{VMIDs: []int{100, 102}}},
+			want:    []proxmoxclient.VMID{100, 102},
+		},
+		{
+			name:    "include by node",
+			include: []config.ResourceSelector{{Nodes: []string{"pve2"}}},
+			want:    []proxmoxclient.VMID{102, 9000},
+		},
+		{
+			name:    "exclude by name glob and template",
+			exclude: []config.ResourceSelector{{Names: []string{"scratch-*"}}, {Template: &isTemplate}},
+			want:    []proxmoxclient.VMID{100, 102},
+		},
+		{
+			name:    "exclude wins over include",
+			include: []config.ResourceSelector{{Nodes: []string{"pve1"}}},
+			exclude: []config.ResourceSelector{{Tags: []string{"prod"}}},
+			want:    []proxmoxclient.VMID{101},
+		},
+		{
+			name:    "criteria are combined",
+			include: []config.ResourceSelector{{Nodes: []string{"pve2"}, Pools: []string{"large"}}},
+			want:    []proxmoxclient.VMID{102},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := selectResources(testResources, tt.include, tt.exclude)
+
+			got := make([]proxmoxclient.VMID, 0, len(selected))
+			for _, r := range selected {
+				got = append(got, r.VMID)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResolveSettings(t *testing.T) {
+	defaults := backupSettings{MaxBackupAge: 24 * time.Hour, Mode: "snapshot", Compress: "zstd"}
+	overrides := []config.ComputeOverride{
+		{Match: config.ResourceSelector{Tags: []string{"media"}}, MaxBackupAge: 7 * 24 * time.Hour, Mode: "stop"},
+		{Match: config.ResourceSelector{Nodes: []string{"pve2"}}, Compress: "lzo"},
+	}
+
+	tests := []struct {
+		name     string
+		resource proxmoxclient.Resource
+		want     backupSettings
+	}{
+		{
+			name:     "no match uses defaults",
+			resource: testResources[0],
+			want:     defaults,
+		},
+		{
+			name:     "first match applies",
+			resource: testResources[2],
+			want:     backupSettings{MaxBackupAge: 7 * 24 * time.Hour, Mode: "stop", Compress: "zstd"},
+		},
+		{
+			name:     "unset fields fall back to defaults",
+			resource: testResources[3],
+			want:     backupSettings{MaxBackupAge: 24 * time.Hour, Mode: "snapshot", Compress: "lzo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resolveSettings(tt.resource, defaults, overrides))
+		})
+	}
+}
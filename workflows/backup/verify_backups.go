@@ -0,0 +1,116 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nomis52/goback/activity"
+	"github.com/nomis52/goback/clients/proxmoxclient"
+)
+
+// VerifyBackups confirms that every backup BackupVMs reported as successful actually
+// landed in the backup storage. A vzdump task can exit "OK" without the snapshot being
+// usable, so each resource must have a backup created after its backup was requested,
+// with a plausible size.
+// Runs after BackupVMs, even if some of the VM backups failed, so the backups that did
+// complete are still verified.
+type VerifyBackups struct {
+	// Dependencies
+	ProxmoxClient *proxmoxclient.Client
+	Logger        *slog.Logger
+	BackupVMs     *BackupVMs `workflow:"tolerate_failure"`
+	StatusLine    *activity.StatusLine
+
+	// Configuration
+	Storage      string  `config:"proxmox.storage"`
+	MinSizeRatio float64 `config:"compute.min_backup_size_ratio"`
+}
+
+func (a *VerifyBackups) Init() error {
+	return nil
+}
+
+func (a *VerifyBackups) Execute(ctx context.Context) error {
+	completed, _ := a.BackupVMs.Backups.Get()
+	if len(completed) == 0 {
+		a.StatusLine.Set("no backups to verify")
+		return nil
+	}
+
+	return activity.CaptureError(a.StatusLine, func() error {
+		a.StatusLine.Set(fmt.Sprintf("verifying %d backup(s) in %s", len(completed), a.Storage))
+
+		// Backups are listed per node, so only query each node once
+		backupsByNode := make(map[string][]proxmoxclient.Backup)
+		var problems []string
+		for _, c := range completed {
+			node := c.Resource.Node
+			backups, ok := backupsByNode[node]
+			if !ok {
+				var err error
+				backups, err = a.ProxmoxClient.ListBackups(ctx, node, a.Storage)
+				if err != nil {
+					return fmt.Errorf("failed to list backups on %s: %w", node, err)
+				}
+				backupsByNode[node] = backups
+			}
+
+			if problem := checkBackup(c, backups, a.MinSizeRatio); problem != "" {
+				a.Logger.Error("Backup verification failed",
+					"vmid", c.Resource.VMID,
+					"name", c.Resource.Name,
+					"node", node,
+					"problem", problem)
+				problems = append(problems, fmt.Sprintf("VMID %d (%s): %s", c.Resource.VMID, c.Resource.Name, problem))
+			}
+		}
+
+		// If any backups are missing, return a per-VM report
+		if len(problems) > 0 {
+			errMsg := fmt.Sprintf("%d of %d backup(s) failed verification:", len(problems), len(completed))
+			for _, problem := range problems {
+				errMsg += "\n  - " + problem
+			}
+			return errors.New(errMsg)
+		}
+
+		a.StatusLine.Set(fmt.Sprintf("verified %d backup(s)", len(completed)))
+		return nil
+	})
+}
+
+// checkBackup looks for the backup of a completed resource in the storage's backups.
+// It returns a description of the problem, or "" if the backup looks complete.
+func checkBackup(c CompletedBackup, backups []proxmoxclient.Backup, minSizeRatio float64) string {
+	// Backup times have a resolution of one second
+	since := c.Started.Truncate(time.Second)
+
+	var latest, previous *proxmoxclient.Backup
+	for i := range backups {
+		b := &backups[i]
+		if b.VMID != c.Resource.VMID {
+			continue
+		}
+		if !b.CTime.Before(since) {
+			if latest == nil || b.CTime.After(latest.CTime) {
+				latest = b
+			}
+		} else if previous == nil || b.CTime.After(previous.CTime) {
+			previous = b
+		}
+	}
+
+	switch {
+	case latest == nil:
+		return fmt.Sprintf("no backup created since %s", since.Format(time.RFC3339))
+	case latest.Size <= 0:
+		return fmt.Sprintf("backup %s is empty", latest.VolID)
+	case previous != nil && minSizeRatio > 0 && float64(latest.Size) < minSizeRatio*float64(previous.Size):
+		return fmt.Sprintf("backup %s is %d bytes, less than %.0f%% of the previous backup (%d bytes)",
+			latest.VolID, latest.Size, minSizeRatio*100, previous.Size)
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nomis52/goback/clients/proxmoxclient"
+)
+
+func TestCheckBackup(t *testing.T) {
+	started := time.Date(2026, 1, 2, 4, 5, 0, 500_000_000, time.UTC)
+	completed := CompletedBackup{
+		Resource: proxmoxclient.Resource{VMID: 100, Name: "web", Node: "pve1"},
+		Started:  started,
+	}
+	backup := func(vmid proxmoxclient.VMID, ctime time.Time, size int64) proxmoxclient.Backup {
+		return proxmoxclient.Backup{VMID: vmid, CTime: ctime, Size: size, VolID: "pbs:backup/vm/" + ctime.Format("150405")}
+	}
+	previous := backup(100, started.Add(-24*time.Hour), 1000)
+
+	tests := []struct {
+		name         string
+		backups      []proxmoxclient.Backup
+		minSizeRatio float64
+		want         string
+	}{
+		{
+			name:    "new backup",
+			backups: []proxmoxclient.Backup{previous, backup(100, started.Add(time.Minute), 900)},
+		},
+		{
+			name:    "same second as start",
+			backups: []proxmoxclient.Backup{backup(100, started.Truncate(time.Second), 900)},
+		},
+		{
+			name:    "only an old backup",
+			backups: []proxmoxclient.Backup{previous, backup(101, started.Add(time.Minute), 900)},
+			want:    "no backup created since 2026-01-02T04:05:00Z",
+		},
+		{
+			name:    "empty backup",
+			backups: []proxmoxclient.Backup{backup(100, started.Add(time.Minute), 0)},
+			want:    "backup pbs:backup/vm/040600 is empty",
+		},
+		{
+			name:         "much smaller than previous",
+			backups:      []proxmoxclient.Backup{previous, backup(100, started.Add(time.Minute), 100)},
+			minSizeRatio: 0.5,
+			want:         "backup pbs:backup/vm/040600 is 100 bytes, less than 50% of the previous backup (1000 bytes)",
+		},
+		{
+			name:    "smaller than previous without ratio",
+			backups: []proxmoxclient.Backup{previous, backup(100, started.Add(time.Minute), 100)},
+		},
+		{
+			name:         "first backup ignores ratio",
+			backups:      []proxmoxclient.Backup{backup(100, started.Add(time.Minute), 100)},
+			minSizeRatio: 0.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, checkBackup(completed, tt.backups, tt.minSizeRatio))
+		})
+	}
+}
@@ -6,18 +6,31 @@ import (
 	"fmt"
 	"log/slog"
 
-	"github.com/nomis52/goback/clients/ipmiclient"
 	"github.com/nomis52/goback/clients/pbsclient"
 	"github.com/nomis52/goback/clients/proxmoxclient"
 	"github.com/nomis52/goback/config"
+	"github.com/nomis52/goback/power"
 	"github.com/nomis52/goback/workflow"
 	"github.com/nomis52/goback/workflows"
+	"github.com/nomis52/goback/workflows/poweroff"
 )
 
 // NewWorkflow creates a workflow that powers on PBS and performs backups.
-// The workflow executes: PowerOnPBS → BackupDirs → BackupVMs
+// The workflow executes: PowerOnPBS → BackupDirs → BackupVMs → VerifyBackups
 // It does NOT power off PBS after completion.
 func NewWorkflow(params workflows.Params) (workflow.Workflow, error) {
+	return newWorkflow(params, false)
+}
+
+// NewWorkflowWithPowerOff creates a backup workflow that powers off PBS when it finishes.
+// The workflow executes: PowerOnPBS → BackupDirs → BackupVMs → VerifyBackups → PowerOffPBS
+// PowerOffPBS is a finalizer, so it runs even if the backups fail or the run is cancelled.
+func NewWorkflowWithPowerOff(params workflows.Params) (workflow.Workflow, error) {
+	return newWorkflow(params, true)
+}
+
+// newWorkflow creates the backup workflow, optionally with PowerOffPBS as its cleanup step.
+func newWorkflow(params workflows.Params, powerOff bool) (workflow.Workflow, error) {
 	cfg := params.Config
 	logger := params.Logger
 
@@ -25,6 +38,8 @@ func NewWorkflow(params workflows.Params) (workflow.Workflow, error) {
 	o := workflow.NewOrchestrator(
 		workflow.WithConfig(cfg),
 		workflow.WithLogger(logger),
+		workflow.WithResultObserver(params.ResultObserver),
+		workflow.WithMetrics(params.Registry, params.Name),
 	)
 
 	// Build shared dependencies
@@ -34,7 +49,7 @@ func NewWorkflow(params workflows.Params) (workflow.Workflow, error) {
 	}
 
 	// Register factories for shared dependencies
-	workflow.Provide(o, workflow.Shared(deps.ipmiController))
+	workflow.Provide(o, workflow.Shared(deps.powerController))
 	workflow.Provide(o, workflow.Shared(deps.pbsClient))
 	workflow.Provide(o, workflow.Shared(deps.proxmoxClient))
 
@@ -45,8 +60,14 @@ func NewWorkflow(params workflows.Params) (workflow.Workflow, error) {
 	powerOnPBS := &PowerOnPBS{}
 	backupDirs := &BackupDirs{}
 	backupVMs := &BackupVMs{}
+	verifyBackups := &VerifyBackups{}
 
-	if err := o.AddActivity(powerOnPBS, backupDirs, backupVMs); err != nil {
+	activities := []workflow.Activity{powerOnPBS, backupDirs, backupVMs, verifyBackups}
+	if powerOff {
+		activities = append(activities, &poweroff.PowerOffPBS{})
+	}
+
+	if err := o.AddActivity(activities...); err != nil {
 		return nil, fmt.Errorf("failed to add activities: %w", err)
 	}
 
@@ -55,33 +76,31 @@ func NewWorkflow(params workflows.Params) (workflow.Workflow, error) {
 
 // deps holds all dependencies that can be injected into workflows.
 type deps struct {
-	ipmiController *ipmiclient.IPMIController
-	pbsClient      *pbsclient.Client
-	proxmoxClient  *proxmoxclient.Client
+	powerController power.PowerController
+	pbsClient       *pbsclient.Client
+	proxmoxClient   *proxmoxclient.Client
 }
 
 // buildDeps creates all dependencies needed for backup workflows.
 func buildDeps(cfg *config.Config, logger *slog.Logger) (*deps, error) {
-	ctrl := ipmiclient.NewIPMIController(
-		cfg.PBS.IPMI.Host,
-		ipmiclient.WithUsername(cfg.PBS.IPMI.Username),
-		ipmiclient.WithPassword(cfg.PBS.IPMI.Password),
-		ipmiclient.WithLogger(logger),
-	)
+	ctrl, err := power.New(cfg.PBS, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create power controller: %w", err)
+	}
 
-	pbsClient, err := pbsclient.New(cfg.PBS.Host, pbsclient.WithLogger(logger))
+	pbsClient, err := workflows.NewPBSClient(cfg.PBS, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create PBS client: %w", err)
 	}
 
-	proxmoxClient, err := proxmoxclient.New(cfg.Proxmox.Host, proxmoxclient.WithToken(cfg.Proxmox.Token))
+	proxmoxClient, err := workflows.NewProxmoxClient(cfg.Proxmox, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Proxmox client: %w", err)
 	}
 
 	return &deps{
-		ipmiController: ctrl,
-		pbsClient:      pbsClient,
-		proxmoxClient:  proxmoxClient,
+		powerController: ctrl,
+		pbsClient:       pbsClient,
+		proxmoxClient:   proxmoxClient,
 	}, nil
 }
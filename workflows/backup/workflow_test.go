@@ -0,0 +1,145 @@
+package backup
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nomis52/goback/config"
+	"github.com/nomis52/goback/metrics"
+	"github.com/nomis52/goback/workflow"
+	"github.com/nomis52/goback/workflows"
+)
+
+func TestNewWorkflow_Window(t *testing.T) {
+	tests := []struct {
+		name   string
+		window workflows.Window
+	}{
+		{
+			name: "no deadline",
+		},
+		{
+			name:   "open window",
+			window: workflows.Window{Deadline: time.Now().Add(time.Hour), Grace: time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(testWorkflowHandler(t))
+			defer ts.Close()
+
+			registry, err := metrics.NewScrapeRegistry()
+			require.NoError(t, err)
+
+			wf, err := NewWorkflow(workflows.Params{
+				Name:     "backup",
+				Config:   testWorkflowConfig(ts.URL),
+				Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+				Registry: registry,
+				Window:   tt.window,
+			})
+			require.NoError(t, err)
+			require.NoError(t, wf.Execute(context.Background()))
+
+			results := wf.GetAllResults()
+			assert.Len(t, results, 4)
+			for id, result := range results {
+				assert.Equal(t, workflow.Completed, result.State, "activity %s", id.String())
+				assert.NoError(t, result.Error, "activity %s", id.String())
+			}
+		})
+	}
+}
+
+func TestNewWorkflow_VerifiesAfterBackupFailure(t *testing.T) {
+	handler := testWorkflowHandler(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api2/json/version" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		handler(w, r)
+	}))
+	defer ts.Close()
+
+	registry, err := metrics.NewScrapeRegistry()
+	require.NoError(t, err)
+
+	wf, err := NewWorkflow(workflows.Params{
+		Name:     "backup",
+		Config:   testWorkflowConfig(ts.URL),
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Registry: registry,
+	})
+	require.NoError(t, err)
+	require.Error(t, wf.Execute(context.Background()))
+
+	results := wf.GetAllResults()
+	backupVMs := results[workflow.GetActivityID(&BackupVMs{})]
+	require.NotNil(t, backupVMs)
+	assert.Error(t, backupVMs.Error)
+
+	verify := results[workflow.GetActivityID(&VerifyBackups{})]
+	require.NotNil(t, verify)
+	assert.Equal(t, workflow.Completed, verify.State)
+	assert.NoError(t, verify.Error)
+}
+
+// testWorkflowHandler returns a handler that stands in for the smart plug, PBS and
+// Proxmox VE: PBS is already on and Proxmox VE has one node with the backup storage
+// but no guests.
+func testWorkflowHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/plug/status":
+			w.Write([]byte(`{"ison": true}`))
+		case r.URL.Path == "/api2/json/version":
+			w.Write([]byte(`{"data": {"version": "8.2"}}`))
+		case r.URL.Path == "/api2/json/nodes":
+			w.Write([]byte(`{"data": [{"node": "pve1", "status": "online"}]}`))
+		case r.URL.Path == "/api2/json/nodes/pve1/storage":
+			w.Write([]byte(`{"data": [{"storage": "pbs", "enabled": 1, "active": 1}]}`))
+		case strings.HasPrefix(r.URL.Path, "/api2/json/"):
+			w.Write([]byte(`{"data": []}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+// testWorkflowConfig returns a config with PBS, its smart plug and Proxmox VE all at url.
+func testWorkflowConfig(url string) *config.Config {
+	return &config.Config{
+		PBS: config.PBSConfig{
+			Host: url,
+			Power: config.PowerConfig{
+				Type: config.PowerTypeSmartPlug,
+				SmartPlug: config.SmartPlugConfig{
+					OnURL:           url + "/plug/on",
+					OffURL:          url + "/plug/off",
+					StatusURL:       url + "/plug/status",
+					StatusOnPattern: `"ison":\s*true`,
+				},
+			},
+			BootTimeout:     time.Minute,
+			ShutdownTimeout: time.Minute,
+		},
+		Proxmox: config.ProxmoxConfig{
+			Host:          url,
+			Token:         "t",
+			Storage:       "pbs",
+			BackupTimeout: time.Minute,
+		},
+	}
+}
@@ -0,0 +1,54 @@
+package workflows
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/nomis52/goback/clients/pbsclient"
+	"github.com/nomis52/goback/clients/proxmoxclient"
+	"github.com/nomis52/goback/clients/pveapi"
+	"github.com/nomis52/goback/config"
+)
+
+// NewPBSClient creates a PBS API client with the authentication, TLS and timeout settings in cfg.
+func NewPBSClient(cfg config.PBSConfig, logger *slog.Logger) (*pbsclient.Client, error) {
+	httpClient, err := pveapi.NewHTTPClient(pveapi.ConnOptions{
+		Timeout:     cfg.Timeout,
+		CABundle:    cfg.TLS.CABundle,
+		Fingerprint: cfg.TLS.Fingerprint,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure PBS connection: %w", err)
+	}
+
+	opts := []pbsclient.Option{
+		pbsclient.WithLogger(logger),
+		pbsclient.WithHTTPClient(httpClient),
+		pbsclient.WithToken(cfg.Token),
+	}
+	if cfg.Username != "" {
+		opts = append(opts, pbsclient.WithTicketAuth(cfg.Username, cfg.Password))
+	}
+	return pbsclient.New(cfg.Host, opts...)
+}
+
+// NewProxmoxClient creates a Proxmox VE API client with the authentication, TLS and timeout settings in cfg.
+func NewProxmoxClient(cfg config.ProxmoxConfig, logger *slog.Logger) (*proxmoxclient.Client, error) {
+	httpClient, err := pveapi.NewHTTPClient(pveapi.ConnOptions{
+		Timeout:     cfg.Timeout,
+		CABundle:    cfg.TLS.CABundle,
+		Fingerprint: cfg.TLS.Fingerprint,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure Proxmox connection: %w", err)
+	}
+
+	opts := []proxmoxclient.Option{
+		proxmoxclient.WithHTTPClient(httpClient),
+		proxmoxclient.WithToken(cfg.Token),
+	}
+	if cfg.Username != "" {
+		opts = append(opts, proxmoxclient.WithTicketAuth(cfg.Username, cfg.Password))
+	}
+	return proxmoxclient.New(cfg.Host, opts...)
+}
@@ -0,0 +1,153 @@
+// Package custom builds the workflows declared in the workflows section of the config.
+// A declared workflow lists registered activity types, such as PowerOnPBS and BackupVMs,
+// plus optional ordering constraints, so variants of the built-in workflows can be run
+// without recompiling.
+package custom
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/nomis52/goback/clients/pbsclient"
+	"github.com/nomis52/goback/clients/proxmoxclient"
+	"github.com/nomis52/goback/config"
+	"github.com/nomis52/goback/power"
+	"github.com/nomis52/goback/workflow"
+	"github.com/nomis52/goback/workflows"
+	"github.com/nomis52/goback/workflows/backup"
+	"github.com/nomis52/goback/workflows/maintenance"
+	"github.com/nomis52/goback/workflows/poweroff"
+	"github.com/nomis52/goback/workflows/restoretest"
+)
+
+// activities maps the activity types a declared workflow may use to their constructors.
+var activities = map[string]func() workflow.Activity{
+	"PowerOnPBS":       func() workflow.Activity { return &backup.PowerOnPBS{} },
+	"BackupDirs":       func() workflow.Activity { return &backup.BackupDirs{} },
+	"BackupVMs":        func() workflow.Activity { return &backup.BackupVMs{} },
+	"VerifyBackups":    func() workflow.Activity { return &backup.VerifyBackups{} },
+	"PruneDatastores":  func() workflow.Activity { return &maintenance.PruneDatastores{} },
+	"GarbageCollect":   func() workflow.Activity { return &maintenance.GarbageCollect{} },
+	"VerifyDatastores": func() workflow.Activity { return &maintenance.VerifyDatastores{} },
+	"RestoreTest":      func() workflow.Activity { return &restoretest.RestoreTest{} },
+	"PowerOffPBS":      func() workflow.Activity { return &poweroff.PowerOffPBS{} },
+}
+
+// Activities returns the sorted names of the activity types a declared workflow may use.
+func Activities() []string {
+	return slices.Sorted(maps.Keys(activities))
+}
+
+// NewWorkflow creates the workflow declared in the config under params.Name.
+// The declaration is looked up when the workflow is created, so a reloaded config
+// takes effect on the next run.
+func NewWorkflow(params workflows.Params) (workflow.Workflow, error) {
+	cfg := params.Config
+	logger := params.Logger
+
+	o, err := newOrchestrator(params)
+	if err != nil {
+		return nil, err
+	}
+
+	ctrl, err := power.New(cfg.PBS, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create power controller: %w", err)
+	}
+
+	pbsClient, err := workflows.NewPBSClient(cfg.PBS, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PBS client: %w", err)
+	}
+
+	proxmoxClient, err := workflows.NewProxmoxClient(cfg.Proxmox, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Proxmox client: %w", err)
+	}
+
+	// Register factories for shared dependencies
+	workflow.Provide(o, workflow.Shared(ctrl))
+	workflow.Provide(o, workflow.Shared(pbsClient))
+	workflow.Provide(o, workflow.Shared(proxmoxClient))
+
+	return o, nil
+}
+
+// Validate checks the activities and dependency graph of every workflow declared in cfg,
+// so a workflow with an unknown activity, a missing dependency or a cycle is rejected when
+// the config is loaded rather than when it is first run. No clients are created.
+func Validate(cfg *config.Config, logger *slog.Logger) error {
+	for _, declared := range cfg.Workflows {
+		o, err := newOrchestrator(workflows.Params{
+			Name:   declared.Name,
+			Config: cfg,
+			Logger: logger,
+		})
+		if err != nil {
+			return fmt.Errorf("workflow %q: %w", declared.Name, err)
+		}
+
+		// Unconnected clients satisfy the dependency check without reading credentials or CA bundles
+		workflow.Provide(o, workflow.Shared(&pbsclient.Client{}))
+		workflow.Provide(o, workflow.Shared(&proxmoxclient.Client{}))
+
+		if err := o.Validate(); err != nil {
+			return fmt.Errorf("workflow %q: %w", declared.Name, err)
+		}
+	}
+	return nil
+}
+
+// newOrchestrator creates an orchestrator with the activities and ordering constraints
+// of the workflow declared under params.Name. The clients the activities use are not
+// provided, so the caller must register them before the workflow is executed.
+func newOrchestrator(params workflows.Params) (*workflow.Orchestrator, error) {
+	cfg := params.Config
+
+	idx := slices.IndexFunc(cfg.Workflows, func(w config.WorkflowConfig) bool { return w.Name == params.Name })
+	if idx < 0 {
+		return nil, fmt.Errorf("workflow %q is not declared in the config", params.Name)
+	}
+	declared := cfg.Workflows[idx]
+
+	added := make(map[string]workflow.Activity, len(declared.Activities))
+	list := make([]workflow.Activity, 0, len(declared.Activities))
+	for _, name := range declared.Activities {
+		newActivity, ok := activities[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown activity %q (available: %v)", name, Activities())
+		}
+		a := newActivity()
+		added[name] = a
+		list = append(list, a)
+	}
+
+	// Create orchestrator with config and logger options
+	o := workflow.NewOrchestrator(
+		workflow.WithConfig(cfg),
+		workflow.WithLogger(params.Logger),
+		workflow.WithResultObserver(params.ResultObserver),
+		workflow.WithMetrics(params.Registry, params.Name),
+	)
+
+	// Inject common factories (logger, metrics registry, status line)
+	params.InjectInto(o)
+
+	if err := o.AddActivity(list...); err != nil {
+		return nil, fmt.Errorf("failed to add activities: %w", err)
+	}
+
+	for _, ordering := range declared.Ordering {
+		before, after := added[ordering.Before], added[ordering.After]
+		if before == nil || after == nil {
+			return nil, fmt.Errorf("ordering %s before %s refers to an activity that is not listed", ordering.Before, ordering.After)
+		}
+		if err := o.AddDependency(after, before); err != nil {
+			return nil, fmt.Errorf("failed to add ordering %s before %s: %w", ordering.Before, ordering.After, err)
+		}
+	}
+
+	return o, nil
+}
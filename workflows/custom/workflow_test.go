@@ -0,0 +1,129 @@
+package custom
+
+import (
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nomis52/goback/config"
+	"github.com/nomis52/goback/workflow"
+	"github.com/nomis52/goback/workflows"
+)
+
+func TestActivities_NamesMatchTypes(t *testing.T) {
+	for name, newActivity := range activities {
+		assert.Equal(t, name, workflow.GetActivityID(newActivity()).Type)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow config.WorkflowConfig
+		wantErr  string
+	}{
+		{
+			name: "power on, VMs only",
+			workflow: config.WorkflowConfig{
+				Name:       "vms_only",
+				Activities: []string{"PowerOnPBS", "BackupVMs", "VerifyBackups"},
+			},
+		},
+		{
+			name: "ordering between independent activities",
+			workflow: config.WorkflowConfig{
+				Name:       "backup_then_restore",
+				Activities: []string{"PowerOnPBS", "BackupVMs", "RestoreTest", "PowerOffPBS"},
+				Ordering:   []config.OrderingConfig{{Before: "BackupVMs", After: "RestoreTest"}},
+			},
+		},
+		{
+			name: "unknown activity",
+			workflow: config.WorkflowConfig{
+				Name:       "typo",
+				Activities: []string{"PowerOnPBS", "BackupVM"},
+			},
+			wantErr: `workflow "typo": unknown activity "BackupVM"`,
+		},
+		{
+			name: "missing dependency",
+			workflow: config.WorkflowConfig{
+				Name:       "no_power_on",
+				Activities: []string{"BackupVMs"},
+			},
+			wantErr: "has nil dependency: PowerOnPBS",
+		},
+		{
+			name: "cycle",
+			workflow: config.WorkflowConfig{
+				Name:       "cycle",
+				Activities: []string{"PowerOnPBS", "BackupVMs", "VerifyBackups"},
+				Ordering:   []config.OrderingConfig{{Before: "VerifyBackups", After: "PowerOnPBS"}},
+			},
+			wantErr: "circular dependency",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Workflows = []config.WorkflowConfig{tt.workflow}
+
+			err := Validate(cfg, testLogger())
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidate_NoClients(t *testing.T) {
+	cfg := testConfig()
+	cfg.Proxmox.TLS.CABundle = filepath.Join(t.TempDir(), "missing.pem")
+	cfg.Workflows = []config.WorkflowConfig{{
+		Name:       "vms_only",
+		Activities: []string{"PowerOnPBS", "BackupVMs", "VerifyBackups"},
+	}}
+
+	// Validate only checks the declaration, so a CA bundle that can't be read isn't an error
+	require.NoError(t, Validate(cfg, testLogger()))
+
+	_, err := NewWorkflow(workflows.Params{Name: "vms_only", Config: cfg, Logger: testLogger()})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create Proxmox client")
+}
+
+func TestNewWorkflow_NotDeclared(t *testing.T) {
+	_, err := NewWorkflow(workflows.Params{Name: "missing", Config: testConfig(), Logger: testLogger()})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `workflow "missing" is not declared in the config`)
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		PBS: config.PBSConfig{
+			Host:            "https://pbs.example.com:8007",
+			IPMI:            config.IPMIConfig{Host: "ipmi.example.com", Username: "u", Password: "p"},
+			BootTimeout:     time.Minute,
+			ShutdownTimeout: time.Minute,
+		},
+		Proxmox: config.ProxmoxConfig{
+			Host:          "https://pve.example.com:8006",
+			Token:         "t",
+			Storage:       "pbs",
+			BackupTimeout: time.Minute,
+		},
+	}
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
@@ -15,7 +15,7 @@ func NewWorkflow(params workflows.Params) (workflow.Workflow, error) {
 
 	// Create orchestrator with config and logger options
 	var opts []workflow.OrchestratorOption
-	opts = append(opts, workflow.WithLogger(logger))
+	opts = append(opts, workflow.WithLogger(logger), workflow.WithResultObserver(params.ResultObserver), workflow.WithMetrics(params.Registry, params.Name))
 	if cfg != nil {
 		opts = append(opts, workflow.WithConfig(cfg))
 	}
@@ -0,0 +1,54 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nomis52/goback/activity"
+	"github.com/nomis52/goback/clients/pbsclient"
+)
+
+// GarbageCollect runs garbage collection on each datastore to reclaim the space freed
+// by pruning. It is a no-op unless maintenance.garbage_collection is set.
+// Runs after PruneDatastores.
+type GarbageCollect struct {
+	// Dependencies
+	PBSClient       *pbsclient.Client
+	PruneDatastores *PruneDatastores
+	Logger          *slog.Logger
+	StatusLine      *activity.StatusLine
+
+	// Configuration
+	Datastores  []string      `config:"maintenance.datastores"`
+	Enabled     bool          `config:"maintenance.garbage_collection"`
+	TaskTimeout time.Duration `config:"maintenance.task_timeout"`
+}
+
+func (a *GarbageCollect) Init() error {
+	return nil
+}
+
+func (a *GarbageCollect) Execute(ctx context.Context) error {
+	if !a.Enabled || len(a.Datastores) == 0 {
+		a.StatusLine.Set("garbage collection not configured")
+		return nil
+	}
+
+	runner := &taskRunner{client: a.PBSClient, logger: a.Logger, statusLine: a.StatusLine, timeout: a.TaskTimeout}
+
+	return activity.CaptureError(a.StatusLine, func() error {
+		err := forEachDatastore(ctx, a.Datastores, func(store, progress string) error {
+			return runner.run(ctx, "garbage collection on "+progress, func(ctx context.Context) (pbsclient.TaskID, error) {
+				return a.PBSClient.StartGarbageCollection(ctx, store)
+			})
+		})
+		if err != nil {
+			return err
+		}
+
+		a.StatusLine.Set(fmt.Sprintf("garbage collected %d datastore(s)", len(a.Datastores)))
+		return nil
+	})
+}
@@ -0,0 +1,78 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nomis52/goback/activity"
+	"github.com/nomis52/goback/clients/pbsclient"
+	"github.com/nomis52/goback/config"
+	"github.com/nomis52/goback/workflows/backup"
+)
+
+// PruneDatastores removes old snapshots from each datastore according to the configured
+// keep options. It is a no-op if no keep option is set.
+// Runs after the PBS server is powered on.
+type PruneDatastores struct {
+	// Dependencies
+	PBSClient  *pbsclient.Client
+	PowerOnPBS *backup.PowerOnPBS
+	Logger     *slog.Logger
+	StatusLine *activity.StatusLine
+
+	// Configuration
+	Datastores  []string           `config:"maintenance.datastores"`
+	Prune       config.PruneConfig `config:"maintenance.prune"`
+	TaskTimeout time.Duration      `config:"maintenance.task_timeout"`
+}
+
+func (a *PruneDatastores) Init() error {
+	return nil
+}
+
+func (a *PruneDatastores) Execute(ctx context.Context) error {
+	if !a.Prune.Enabled() || len(a.Datastores) == 0 {
+		a.StatusLine.Set("pruning not configured")
+		return nil
+	}
+
+	runner := &taskRunner{client: a.PBSClient, logger: a.Logger, statusLine: a.StatusLine, timeout: a.TaskTimeout}
+	opts := pruneOptions(a.Prune)
+
+	return activity.CaptureError(a.StatusLine, func() error {
+		err := forEachDatastore(ctx, a.Datastores, func(store, progress string) error {
+			return runner.run(ctx, "prune of "+progress, func(ctx context.Context) (pbsclient.TaskID, error) {
+				return a.PBSClient.Prune(ctx, store, opts...)
+			})
+		})
+		if err != nil {
+			return err
+		}
+
+		a.StatusLine.Set(fmt.Sprintf("pruned %d datastore(s)", len(a.Datastores)))
+		return nil
+	})
+}
+
+// pruneOptions converts the configured keep options into client options.
+func pruneOptions(cfg config.PruneConfig) []pbsclient.PruneOption {
+	var opts []pbsclient.PruneOption
+	if cfg.KeepLast > 0 {
+		opts = append(opts, pbsclient.WithKeepLast(cfg.KeepLast))
+	}
+	if cfg.KeepDaily > 0 {
+		opts = append(opts, pbsclient.WithKeepDaily(cfg.KeepDaily))
+	}
+	if cfg.KeepWeekly > 0 {
+		opts = append(opts, pbsclient.WithKeepWeekly(cfg.KeepWeekly))
+	}
+	if cfg.KeepMonthly > 0 {
+		opts = append(opts, pbsclient.WithKeepMonthly(cfg.KeepMonthly))
+	}
+	if cfg.KeepYearly > 0 {
+		opts = append(opts, pbsclient.WithKeepYearly(cfg.KeepYearly))
+	}
+	return opts
+}
@@ -0,0 +1,120 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/nomis52/goback/activity"
+	"github.com/nomis52/goback/clients/pbsclient"
+)
+
+const (
+	taskCheckInterval = 10 * time.Second
+	taskStopTimeout   = 30 * time.Second
+
+	// warningsPrefix is how PBS reports a task that completed with warnings, e.g. "WARNINGS: 2"
+	warningsPrefix = "WARNINGS"
+)
+
+// taskRunner starts PBS tasks and waits for them to finish, reporting progress on a status line.
+type taskRunner struct {
+	client     *pbsclient.Client
+	logger     *slog.Logger
+	statusLine *activity.StatusLine
+	timeout    time.Duration
+}
+
+// startFunc starts a PBS task and returns its ID.
+type startFunc func(ctx context.Context) (pbsclient.TaskID, error)
+
+// run starts a task with start and waits for it to finish. desc describes the task in
+// status updates, e.g. "garbage collection on backups (1/2)".
+// If ctx is cancelled while the task runs, the task is stopped on the PBS server.
+func (r *taskRunner) run(ctx context.Context, desc string, start startFunc) error {
+	r.statusLine.Set("starting " + desc)
+	upid, err := start(ctx)
+	if err != nil {
+		return err
+	}
+	r.logger.Debug("PBS task started", "task", desc, "upid", upid)
+
+	started := time.Now()
+	timeout := time.After(r.timeout)
+	for {
+		status, err := r.client.TaskStatus(ctx, upid)
+		if err != nil {
+			if ctx.Err() != nil {
+				r.stopTask(ctx, upid)
+				return ctx.Err()
+			}
+			return err
+		}
+
+		if !status.Running() {
+			return r.checkExitStatus(desc, upid, status.ExitStatus)
+		}
+
+		r.statusLine.Set(fmt.Sprintf("%s: running for %s", desc, time.Since(started).Round(time.Second)))
+
+		select {
+		case <-ctx.Done():
+			r.stopTask(ctx, upid)
+			return ctx.Err()
+		case <-timeout:
+			r.stopTask(ctx, upid)
+			return fmt.Errorf("%s timed out after %v", desc, r.timeout)
+		case <-time.After(taskCheckInterval):
+		}
+	}
+}
+
+// checkExitStatus returns an error unless the task finished successfully.
+// Tasks that completed with warnings are logged but treated as successful.
+func (r *taskRunner) checkExitStatus(desc string, upid pbsclient.TaskID, exitStatus string) error {
+	switch {
+	case exitStatus == pbsclient.TaskExitOK:
+		r.logger.Debug("PBS task completed", "task", desc, "upid", upid)
+		return nil
+	case strings.HasPrefix(exitStatus, warningsPrefix):
+		r.logger.Warn("PBS task completed with warnings", "task", desc, "upid", upid, "exit_status", exitStatus)
+		return nil
+	case exitStatus == "":
+		return errors.New(desc + " stopped without an exit status")
+	default:
+		return fmt.Errorf("%s failed: %s", desc, exitStatus)
+	}
+}
+
+// stopTask stops a task after the run has been cancelled or the task timed out.
+// The request uses a fresh timeout since the run's context may already be done.
+func (r *taskRunner) stopTask(ctx context.Context, upid pbsclient.TaskID) {
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), taskStopTimeout)
+	defer cancel()
+
+	r.logger.Warn("Stopping PBS task", "upid", upid)
+	if err := r.client.StopTask(stopCtx, upid); err != nil {
+		r.logger.Error("Failed to stop PBS task", "upid", upid, "error", err)
+	}
+}
+
+// forEachDatastore runs f for each datastore, continuing past failures, and returns the
+// combined error. f is given a progress string such as "backups (1/2)".
+func forEachDatastore(ctx context.Context, datastores []string, f func(store, progress string) error) error {
+	var errs []error
+	for i, store := range datastores {
+		if ctx.Err() != nil {
+			errs = append(errs, fmt.Errorf("datastore %q not started: %w", store, ctx.Err()))
+			continue
+		}
+
+		progress := fmt.Sprintf("%s (%d/%d)", store, i+1, len(datastores))
+		if err := f(store, progress); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
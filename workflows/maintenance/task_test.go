@@ -0,0 +1,128 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nomis52/goback/activity"
+	"github.com/nomis52/goback/clients/pbsclient"
+	"github.com/nomis52/goback/config"
+	"github.com/nomis52/goback/workflow"
+)
+
+func TestTaskRunner(t *testing.T) {
+	const upid = "UPID:pbs:task"
+
+	tests := []struct {
+		name       string
+		exitStatus string
+		startErr   error
+		wantErr    string
+		wantStatus string
+	}{
+		{
+			name:       "success",
+			exitStatus: "OK",
+			wantStatus: "starting prune of backups (1/1)",
+		},
+		{
+			name:       "warnings are not failures",
+			exitStatus: "WARNINGS: 2",
+		},
+		{
+			name:       "task failed",
+			exitStatus: "datastore is locked",
+			wantErr:    "prune of backups (1/1) failed: datastore is locked",
+		},
+		{
+			name:     "start failed",
+			startErr: errors.New("permission denied"),
+			wantErr:  "permission denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api2/json/nodes/localhost/tasks/"+upid+"/status", r.URL.Path)
+				w.Write([]byte(`{"data": {"upid": "` + upid + `", "status": "stopped", "exitstatus": "` + tt.exitStatus + `"}}`))
+			}))
+			defer ts.Close()
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			client, err := pbsclient.New(ts.URL, pbsclient.WithLogger(logger))
+			require.NoError(t, err)
+
+			id := workflow.ActivityID{Module: "maintenance", Type: "PruneDatastores"}
+			statuses := activity.NewStatusHandler()
+			runner := &taskRunner{
+				client:     client,
+				logger:     logger,
+				statusLine: activity.NewStatusLine(id, logger, statuses),
+				timeout:    time.Minute,
+			}
+
+			err = runner.run(context.Background(), "prune of backups (1/1)", func(ctx context.Context) (pbsclient.TaskID, error) {
+				return upid, tt.startErr
+			})
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			if tt.wantStatus != "" {
+				assert.Equal(t, tt.wantStatus, statuses.Get(id))
+			}
+		})
+	}
+}
+
+func TestForEachDatastore(t *testing.T) {
+	var visited []string
+	err := forEachDatastore(context.Background(), []string{"a", "b", "c"}, func(store, progress string) error {
+		visited = append(visited, progress)
+		if store == "b" {
+			return errors.New("b failed")
+		}
+		return nil
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, "b failed", err.Error())
+	assert.Equal(t, []string{"a (1/3)", "b (2/3)", "c (3/3)"}, visited, "a failure should not stop later datastores")
+}
+
+func TestVerifyOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.VerifyConfig
+		want int
+	}{
+		{name: "verify everything", cfg: config.VerifyConfig{Enabled: true}, want: 0},
+		{name: "skip verified", cfg: config.VerifyConfig{Enabled: true, IgnoreVerified: true}, want: 1},
+		{name: "re-verify outdated", cfg: config.VerifyConfig{Enabled: true, IgnoreVerified: true, OutdatedAfter: 36 * time.Hour}, want: 2},
+		{name: "outdated without ignore", cfg: config.VerifyConfig{Enabled: true, OutdatedAfter: 36 * time.Hour}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, verifyOptions(tt.cfg), tt.want)
+		})
+	}
+}
+
+func TestPruneOptions(t *testing.T) {
+	assert.Empty(t, pruneOptions(config.PruneConfig{}))
+	assert.Len(t, pruneOptions(config.PruneConfig{KeepDaily: 7, KeepWeekly: 4, KeepMonthly: 6}), 3)
+}
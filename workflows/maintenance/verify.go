@@ -0,0 +1,70 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/nomis52/goback/activity"
+	"github.com/nomis52/goback/clients/pbsclient"
+	"github.com/nomis52/goback/config"
+)
+
+// VerifyDatastores verifies the snapshots in each datastore. It is a no-op unless
+// maintenance.verify.enabled is set.
+// Runs after GarbageCollect so the two tasks don't compete for disk bandwidth.
+type VerifyDatastores struct {
+	// Dependencies
+	PBSClient      *pbsclient.Client
+	GarbageCollect *GarbageCollect
+	Logger         *slog.Logger
+	StatusLine     *activity.StatusLine
+
+	// Configuration
+	Datastores  []string            `config:"maintenance.datastores"`
+	Verify      config.VerifyConfig `config:"maintenance.verify"`
+	TaskTimeout time.Duration       `config:"maintenance.task_timeout"`
+}
+
+func (a *VerifyDatastores) Init() error {
+	return nil
+}
+
+func (a *VerifyDatastores) Execute(ctx context.Context) error {
+	if !a.Verify.Enabled || len(a.Datastores) == 0 {
+		a.StatusLine.Set("verification not configured")
+		return nil
+	}
+
+	runner := &taskRunner{client: a.PBSClient, logger: a.Logger, statusLine: a.StatusLine, timeout: a.TaskTimeout}
+	opts := verifyOptions(a.Verify)
+
+	return activity.CaptureError(a.StatusLine, func() error {
+		err := forEachDatastore(ctx, a.Datastores, func(store, progress string) error {
+			return runner.run(ctx, "verification of "+progress, func(ctx context.Context) (pbsclient.TaskID, error) {
+				return a.PBSClient.Verify(ctx, store, opts...)
+			})
+		})
+		if err != nil {
+			return err
+		}
+
+		a.StatusLine.Set(fmt.Sprintf("verified %d datastore(s)", len(a.Datastores)))
+		return nil
+	})
+}
+
+// verifyOptions converts the verify configuration into client options.
+func verifyOptions(cfg config.VerifyConfig) []pbsclient.VerifyOption {
+	var opts []pbsclient.VerifyOption
+	if cfg.IgnoreVerified {
+		opts = append(opts, pbsclient.WithIgnoreVerified(true))
+		if cfg.OutdatedAfter > 0 {
+			days := int(math.Ceil(cfg.OutdatedAfter.Hours() / 24))
+			opts = append(opts, pbsclient.WithOutdatedAfter(days))
+		}
+	}
+	return opts
+}
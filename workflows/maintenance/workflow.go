@@ -0,0 +1,58 @@
+// Package maintenance provides a workflow that runs PBS datastore maintenance.
+// PBS is only powered on while goback needs it, so its own scheduled prune, garbage
+// collection and verify jobs rarely get a chance to run. This workflow runs them on demand.
+package maintenance
+
+import (
+	"fmt"
+
+	"github.com/nomis52/goback/power"
+	"github.com/nomis52/goback/workflow"
+	"github.com/nomis52/goback/workflows"
+	"github.com/nomis52/goback/workflows/backup"
+)
+
+// NewWorkflow creates a workflow that powers on PBS and maintains its datastores.
+// The workflow executes: PowerOnPBS → PruneDatastores → GarbageCollect → VerifyDatastores
+// It does NOT power off PBS after completion.
+func NewWorkflow(params workflows.Params) (workflow.Workflow, error) {
+	cfg := params.Config
+	logger := params.Logger
+
+	// Create orchestrator with config and logger options
+	o := workflow.NewOrchestrator(
+		workflow.WithConfig(cfg),
+		workflow.WithLogger(logger),
+		workflow.WithResultObserver(params.ResultObserver),
+		workflow.WithMetrics(params.Registry, params.Name),
+	)
+
+	ctrl, err := power.New(cfg.PBS, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create power controller: %w", err)
+	}
+
+	pbsClient, err := workflows.NewPBSClient(cfg.PBS, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PBS client: %w", err)
+	}
+
+	// Register factories for shared dependencies
+	workflow.Provide(o, workflow.Shared(ctrl))
+	workflow.Provide(o, workflow.Shared(pbsClient))
+
+	// Inject common factories (logger, metrics registry, status line)
+	params.InjectInto(o)
+
+	// Add maintenance activities
+	powerOnPBS := &backup.PowerOnPBS{}
+	prune := &PruneDatastores{}
+	gc := &GarbageCollect{}
+	verify := &VerifyDatastores{}
+
+	if err := o.AddActivity(powerOnPBS, prune, gc, verify); err != nil {
+		return nil, fmt.Errorf("failed to add activities: %w", err)
+	}
+
+	return o, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/nomis52/goback/activity"
 	"github.com/nomis52/goback/config"
+	"github.com/nomis52/goback/ledger"
 	"github.com/nomis52/goback/metrics"
 	"github.com/nomis52/goback/workflow"
 )
@@ -15,6 +16,9 @@ import (
 // Params contains common parameters for workflow construction.
 // Workflows may use all or a subset of these fields depending on their needs.
 type Params struct {
+	// Name is the name the workflow is registered under, used to label its activity metrics.
+	Name string
+
 	// Config is the application configuration. Some workflows (e.g., test) may not need this.
 	Config *config.Config
 
@@ -29,11 +33,21 @@ type Params struct {
 
 	// Registry is used for activity-level metrics. May be nil if metrics are not needed.
 	Registry metrics.Registry
+
+	// ResultObserver is notified of activity state transitions. May be nil.
+	ResultObserver workflow.ResultObserver
+
+	// RecorderFactory creates per-activity backup ledger recorders. May be nil if the
+	// backups of each resource are not recorded.
+	RecorderFactory func(workflow.ActivityID) ledger.Recorder
+
+	// Window is the run's backup window. The zero Window if the run has no deadline.
+	Window Window
 }
 
 // InjectInto registers common factories into an orchestrator.
 // This eliminates duplication across workflow constructors by providing
-// the standard logger factory, metrics registry, and status line factories.
+// the standard logger factory, metrics registry, backup ledger, backup window and status line factories.
 func (p Params) InjectInto(o *workflow.Orchestrator) {
 	// Default logger factory to shared logger if not provided
 	loggerFactory := p.LoggerFactory
@@ -46,6 +60,14 @@ func (p Params) InjectInto(o *workflow.Orchestrator) {
 		workflow.Provide(o, workflow.Shared(p.Registry))
 	}
 
+	// Backup ledger recorder factory (optional - activities check for nil)
+	if p.RecorderFactory != nil {
+		workflow.Provide(o, p.RecorderFactory)
+	}
+
+	// Backup window (the zero Window if the run has no deadline)
+	workflow.Provide(o, workflow.Shared(p.Window))
+
 	// Logger factory (per-activity, defaults to shared logger)
 	workflow.Provide(o, loggerFactory)
 
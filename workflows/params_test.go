@@ -0,0 +1,67 @@
+package workflows
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nomis52/goback/workflow"
+)
+
+func TestParams_InjectWindow(t *testing.T) {
+	deadline := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		window     Window
+		wantClosed bool
+	}{
+		{
+			name: "no deadline",
+		},
+		{
+			name:   "open window",
+			window: Window{Deadline: deadline, Grace: time.Minute},
+		},
+		{
+			name:       "closed window",
+			window:     Window{Deadline: time.Now().Add(-time.Minute)},
+			wantClosed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := workflow.NewOrchestrator()
+			params := Params{Logger: slog.New(slog.NewTextHandler(io.Discard, nil)), Window: tt.window}
+			params.InjectInto(o)
+
+			activity := &WindowActivity{}
+			require.NoError(t, o.AddActivity(activity))
+			require.NoError(t, o.Execute(context.Background()))
+
+			assert.Equal(t, tt.window, activity.Window)
+			assert.Equal(t, tt.wantClosed, activity.closed)
+		})
+	}
+}
+
+// WindowActivity records whether its backup window had closed when it executed.
+type WindowActivity struct {
+	Logger *slog.Logger
+	Window Window
+
+	closed bool
+}
+
+func (a *WindowActivity) Init() error { return nil }
+
+func (a *WindowActivity) Execute(ctx context.Context) error {
+	a.closed = a.Window.Closed(time.Now())
+	return nil
+}
@@ -3,43 +3,37 @@
 // PowerOffPBS Activity:
 //
 // The PowerOffPBS activity handles the graceful shutdown of the Proxmox Backup Server (PBS)
-// after backup operations are complete. It implements a clean two-stage IPMI-based shutdown:
+// after backup operations are complete. It implements a two-stage shutdown through the
+// configured power backend (IPMI, Redfish, Wake-on-LAN + SSH or a smart plug):
 //
-// 1. Graceful IPMI Shutdown:
-//   - Uses IPMI "chassis power soft" to send ACPI shutdown signal to PBS
-//   - Monitors IPMI power status for shutdown completion
+// 1. Graceful Shutdown:
+//   - Asks the backend for a graceful shutdown (ACPI soft-off, Redfish GracefulShutdown
+//     or an SSH shutdown command)
+//   - Monitors the power status for shutdown completion
 //   - This is equivalent to pressing the power button gently
 //
-// 2. Hard IPMI Power-off (if timeout):
+// 2. Hard Power-off (if timeout):
 //   - If graceful shutdown doesn't complete within timeout, forces hard power-off
-//   - Uses IPMI "chassis power off" for immediate shutdown
 //   - Equivalent to holding the power button or pulling the plug
+//   - Not every backend supports this; Wake-on-LAN hosts can only be shut down gracefully
 //
 // Monitoring Logic:
-//   - After graceful shutdown command, continuously checks IPMI power status
-//   - If IPMI reports "off", shutdown is complete (success)
+//   - After graceful shutdown command, continuously checks the power status
+//   - If the backend reports "off", shutdown is complete (success)
 //   - If timeout expires and still "on", forces hard power-off
 //
-// Advantages over SSH approach:
-//   - No network connectivity dependencies
-//   - No SSH configuration or authentication required
-//   - Works even if OS is unresponsive or SSH service is down
-//   - Single interface for all power management operations
-//   - Hardware-level reliability through BMC
-//
 // Configuration Requirements:
-//   - timeouts.shutdown_timeout: Maximum time to wait for graceful shutdown
-//   - IPMI controller configuration (host, username, password)
+//   - pbs.shutdown_timeout: Maximum time to wait for graceful shutdown
+//   - pbs.power: power backend configuration (defaults to IPMI via pbs.ipmi)
 //
 // Dependencies:
-//   - Requires IPMI controller for all power operations
-//   - No SSH or PBS client dependencies needed
+//   - Requires a power.PowerController for all power operations
+//   - No PBS client dependencies needed
 //
 // Error Handling:
-//   - IPMI graceful shutdown failures trigger immediate hard power-off
+//   - Graceful shutdown failures trigger immediate hard power-off
 //   - Shutdown timeout triggers hard power-off
 //   - Already powered-off systems are handled gracefully
-//   - All operations use hardware-level IPMI commands
 package poweroff
 
 import (
@@ -48,24 +42,24 @@ import (
 	"log/slog"
 	"time"
 
-	"github.com/nomis52/goback/clients/ipmiclient"
 	"github.com/nomis52/goback/activity"
+	"github.com/nomis52/goback/power"
+	"github.com/nomis52/goback/workflow"
 )
 
 const (
 	shutdownCheckInterval = 5 * time.Second
 )
 
-// PowerOffPBS manages the graceful shutdown of the PBS host via IPMI commands.
+// PowerOffPBS manages the graceful shutdown of the PBS host via the configured power backend.
 //
 // This activity ensures that the PBS server is gracefully powered down after
 // backup operations complete, reducing power consumption and wear on the hardware.
 //
-// The shutdown process uses pure IPMI commands for maximum reliability,
-// with graceful ACPI shutdown as primary method and hard power-off as fallback.
+// A graceful shutdown is used as the primary method with a hard power-off as fallback.
 type PowerOffPBS struct {
 	// Dependencies
-	Controller *ipmiclient.IPMIController
+	Controller power.PowerController
 	Logger     *slog.Logger
 	StatusLine *activity.StatusLine
 
@@ -78,43 +72,53 @@ func (a *PowerOffPBS) Init() error {
 	return nil
 }
 
-// Execute performs the PBS shutdown process using pure IPMI commands.
+// Finalize makes PowerOffPBS a workflow.Finalizer, so that when it is part of a larger
+// workflow PBS is powered off even if earlier activities failed or were cancelled.
+// Activities that did not succeed are logged.
+func (a *PowerOffPBS) Finalize(dependencies map[workflow.ActivityID]workflow.Result) {
+	for id, result := range dependencies {
+		if !result.IsSuccess() {
+			a.Logger.Warn("powering off PBS after an activity did not succeed",
+				"activity", id.ShortString(),
+				"state", result.State.String())
+		}
+	}
+}
+
+// Execute performs the PBS shutdown process.
 //
 // The execution follows this sequence:
 //  1. Check if PBS is already powered off (early return if so)
-//  2. Send IPMI graceful shutdown command ("chassis power soft")
-//  3. Monitor IPMI power status until shutdown completes or timeout
-//  4. Fall back to IPMI hard power-off if graceful shutdown times out
-//
-// This approach provides maximum reliability by using only hardware-level
-// IPMI commands, eliminating network and SSH dependencies.
+//  2. Request a graceful shutdown
+//  3. Monitor the power status until shutdown completes or timeout
+//  4. Fall back to a hard power-off if graceful shutdown times out
 func (a *PowerOffPBS) Execute(ctx context.Context) error {
 	return activity.CaptureError(a.StatusLine, func() error {
 		a.StatusLine.Set("checking PBS power status")
 
 		// Check current power status first
-		status, err := a.Controller.Status()
+		status, err := a.Controller.Status(ctx)
 		if err != nil {
 			a.Logger.Warn("failed to get initial power status", "error", err)
-		} else if status == ipmiclient.PowerStateOff {
+		} else if status == power.PowerStateOff {
 			a.StatusLine.Set("PBS server already powered off")
 			return nil
 		}
 
-		// Attempt graceful shutdown via IPMI ACPI signal
+		// Attempt graceful shutdown
 		a.StatusLine.Set("sending graceful shutdown signal")
-		if err := a.gracefulIPMIShutdown(); err != nil {
-			a.Logger.Warn("graceful IPMI shutdown failed, falling back to hard power-off", "error", err)
+		if err := a.gracefulShutdown(ctx); err != nil {
+			a.Logger.Warn("graceful shutdown failed, falling back to hard power-off", "error", err)
 			a.StatusLine.Set("forcing hard power off")
-			return a.hardIPMIPowerOff()
+			return a.hardPowerOff(ctx)
 		}
 
-		// Wait for system to shutdown by monitoring IPMI power status
+		// Wait for system to shutdown by monitoring the power status
 		a.StatusLine.Set("waiting for PBS server to shut down")
-		if err := a.waitForShutdownViaIPMI(ctx); err != nil {
-			a.Logger.Warn("graceful shutdown timed out, forcing hard power-off via IPMI", "error", err)
+		if err := a.waitForShutdown(ctx); err != nil {
+			a.Logger.Warn("graceful shutdown timed out, forcing hard power-off", "error", err)
 			a.StatusLine.Set("forcing hard power off")
-			return a.hardIPMIPowerOff()
+			return a.hardPowerOff(ctx)
 		}
 
 		a.StatusLine.Set("PBS server powered off")
@@ -122,18 +126,23 @@ func (a *PowerOffPBS) Execute(ctx context.Context) error {
 	})
 }
 
-// gracefulIPMIShutdown sends a graceful shutdown signal via IPMI ACPI
-func (a *PowerOffPBS) gracefulIPMIShutdown() error {
-	if err := a.Controller.PowerOff(); err != nil {
-		return fmt.Errorf("failed to send IPMI graceful shutdown signal: %w", err)
+// gracefulShutdown asks the power backend for a graceful shutdown.
+// Some backends (e.g. a smart plug) wait for the host to halt, so the request is bounded
+// by the shutdown timeout.
+func (a *PowerOffPBS) gracefulShutdown(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, a.ShutdownTimeout)
+	defer cancel()
+
+	if err := a.Controller.PowerOff(ctx); err != nil {
+		return fmt.Errorf("failed to send graceful shutdown signal: %w", err)
 	}
 
 	return nil
 }
 
-// waitForShutdownViaIPMI monitors IPMI power status until PBS shuts down or timeout
-func (a *PowerOffPBS) waitForShutdownViaIPMI(ctx context.Context) error {
-	a.Logger.Debug("monitoring PBS shutdown via IPMI power status", "timeout", a.ShutdownTimeout)
+// waitForShutdown monitors the power status until PBS shuts down or timeout
+func (a *PowerOffPBS) waitForShutdown(ctx context.Context) error {
+	a.Logger.Debug("monitoring PBS shutdown via power status", "timeout", a.ShutdownTimeout)
 
 	ticker := time.NewTicker(shutdownCheckInterval)
 	defer ticker.Stop()
@@ -150,17 +159,17 @@ func (a *PowerOffPBS) waitForShutdownViaIPMI(ctx context.Context) error {
 		case <-ticker.C:
 			attempts++
 
-			// Check IPMI power status
-			status, err := a.Controller.Status()
+			// Check power status
+			status, err := a.Controller.Status(ctx)
 			if err != nil {
-				a.Logger.Debug("IPMI status check failed", "attempt", attempts, "error", err)
+				a.Logger.Debug("power status check failed", "attempt", attempts, "error", err)
 				continue // Keep trying
 			}
 
-			a.Logger.Debug("IPMI power status check", "status", status, "attempt", attempts)
+			a.Logger.Debug("power status check", "status", status, "attempt", attempts)
 
 			// Check if PBS has powered off
-			if status == ipmiclient.PowerStateOff {
+			if status == power.PowerStateOff {
 				a.Logger.Debug("PBS shutdown completed successfully", "attempts", attempts)
 				return nil
 			}
@@ -171,12 +180,12 @@ func (a *PowerOffPBS) waitForShutdownViaIPMI(ctx context.Context) error {
 	}
 }
 
-// hardIPMIPowerOff performs an immediate hard power off via IPMI
-func (a *PowerOffPBS) hardIPMIPowerOff() error {
-	a.Logger.Warn("performing hard power-off via IPMI")
+// hardPowerOff performs an immediate hard power off
+func (a *PowerOffPBS) hardPowerOff(ctx context.Context) error {
+	a.Logger.Warn("performing hard power-off")
 
-	if err := a.Controller.PowerOffHard(); err != nil {
-		return fmt.Errorf("failed to hard power-off PBS host via IPMI: %w", err)
+	if err := a.Controller.PowerOffHard(ctx); err != nil {
+		return fmt.Errorf("failed to hard power-off PBS host: %w", err)
 	}
 
 	return nil
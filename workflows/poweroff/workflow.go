@@ -1,17 +1,18 @@
 // Package poweroff provides workflow factories for power-off operations.
-// It orchestrates the graceful shutdown of the PBS server via IPMI.
+// It orchestrates the graceful shutdown of the PBS server via the configured power backend.
 package poweroff
 
 import (
 	"fmt"
 
-	"github.com/nomis52/goback/clients/ipmiclient"
+	"github.com/nomis52/goback/power"
 	"github.com/nomis52/goback/workflow"
 	"github.com/nomis52/goback/workflows"
 )
 
 // NewWorkflow creates a workflow that gracefully powers off PBS.
 // The workflow executes: PowerOffPBS
+// The workflow ignores cancellation so PBS is still powered off when a run is cancelled.
 func NewWorkflow(params workflows.Params) (workflow.Workflow, error) {
 	cfg := params.Config
 	logger := params.Logger
@@ -20,15 +21,15 @@ func NewWorkflow(params workflows.Params) (workflow.Workflow, error) {
 	o := workflow.NewOrchestrator(
 		workflow.WithConfig(cfg),
 		workflow.WithLogger(logger),
+		workflow.WithResultObserver(params.ResultObserver),
+		workflow.WithMetrics(params.Registry, params.Name),
 	)
 
-	// Create IPMI controller directly (no buildDeps needed)
-	ctrl := ipmiclient.NewIPMIController(
-		cfg.PBS.IPMI.Host,
-		ipmiclient.WithUsername(cfg.PBS.IPMI.Username),
-		ipmiclient.WithPassword(cfg.PBS.IPMI.Password),
-		ipmiclient.WithLogger(logger),
-	)
+	// Create power controller directly (no buildDeps needed)
+	ctrl, err := power.New(cfg.PBS, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create power controller: %w", err)
+	}
 
 	// Register factories for dependencies
 	workflow.Provide(o, workflow.Shared(ctrl))
@@ -43,5 +44,5 @@ func NewWorkflow(params workflows.Params) (workflow.Workflow, error) {
 		return nil, fmt.Errorf("failed to add activities: %w", err)
 	}
 
-	return o, nil
+	return workflow.IgnoreCancel(o), nil
 }
@@ -0,0 +1,342 @@
+package restoretest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/nomis52/goback/activity"
+	"github.com/nomis52/goback/clients/proxmoxclient"
+	"github.com/nomis52/goback/config"
+	"github.com/nomis52/goback/metrics"
+	"github.com/nomis52/goback/workflows/backup"
+)
+
+var (
+	ErrNotConfigured = errors.New("restore test is not configured: restore_test node, storage and scratch_vmid are required")
+	ErrNoBackups     = errors.New("no backups available to restore")
+)
+
+const (
+	taskCheckInterval  = 5 * time.Second
+	agentCheckInterval = 10 * time.Second
+	startTimeout       = 5 * time.Minute
+	cleanupTimeout     = 15 * time.Minute
+
+	// warningsPrefix is how Proxmox reports a task that completed with warnings, e.g. "WARNINGS: 1"
+	warningsPrefix = "WARNINGS"
+
+	metricLastSuccess = "restore_test_last_success"
+	metricDuration    = "restore_test_duration_seconds"
+	metricFailure     = "restore_test_failure"
+)
+
+// RestoreTest proves that backups can be restored. It restores the latest backup of a
+// configured or random guest to a scratch VMID with networking removed, boots it,
+// optionally waits for the QEMU guest agent, and then destroys the scratch guest.
+// Runs after the PBS server is powered on.
+//
+// The scratch guest is only destroyed if this activity created it; the test refuses to
+// run if the scratch VMID is already in use.
+type RestoreTest struct {
+	// Dependencies
+	ProxmoxClient *proxmoxclient.Client
+	PowerOnPBS    *backup.PowerOnPBS
+	Logger        *slog.Logger
+	StatusLine    *activity.StatusLine
+	Registry      metrics.Registry
+
+	// Configuration
+	BackupStorage string                   `config:"proxmox.storage"`
+	Config        config.RestoreTestConfig `config:"restore_test"`
+
+	// Metrics (initialized in Init)
+	lastSuccessGauge metrics.Gauge
+	durationGauge    metrics.Gauge
+	failureCounter   metrics.Counter
+
+	// randIntN picks the guest whose backup is restored
+	randIntN func(n int) int
+}
+
+func (a *RestoreTest) Init() error {
+	if a.Config.Node == "" || a.Config.Storage == "" || a.Config.ScratchVMID == 0 {
+		return ErrNotConfigured
+	}
+	if a.randIntN == nil {
+		a.randIntN = rand.IntN
+	}
+
+	var err error
+	a.lastSuccessGauge, err = a.Registry.NewGauge(prometheus.GaugeOpts{
+		Name: metricLastSuccess,
+		Help: "Unix timestamp of the last successful restore test",
+	})
+	if err != nil {
+		return fmt.Errorf("creating %s metric: %w", metricLastSuccess, err)
+	}
+
+	a.durationGauge, err = a.Registry.NewGauge(prometheus.GaugeOpts{
+		Name: metricDuration,
+		Help: "Time taken to restore and boot the backup in the last successful restore test",
+	})
+	if err != nil {
+		return fmt.Errorf("creating %s metric: %w", metricDuration, err)
+	}
+
+	a.failureCounter, err = a.Registry.NewCounter(prometheus.CounterOpts{
+		Name: metricFailure,
+		Help: "Count of restore test failures",
+	})
+	if err != nil {
+		return fmt.Errorf("creating %s metric: %w", metricFailure, err)
+	}
+
+	return nil
+}
+
+func (a *RestoreTest) Execute(ctx context.Context) error {
+	return activity.CaptureError(a.StatusLine, func() error {
+		started := time.Now()
+		restored, err := a.restoreTest(ctx)
+		if err != nil {
+			a.failureCounter.Inc()
+			return err
+		}
+
+		duration := time.Since(started).Round(time.Second)
+		a.lastSuccessGauge.Set(float64(time.Now().Unix()))
+		a.durationGauge.Set(duration.Seconds())
+		a.StatusLine.Set(fmt.Sprintf("restored VMID %d backup from %s in %s",
+			restored.VMID, restored.CTime.Format(time.RFC3339), duration))
+		return nil
+	})
+}
+
+// restoreTest runs the restore test and returns the backup that was restored.
+// The scratch guest is destroyed before returning once a restore has been attempted.
+func (a *RestoreTest) restoreTest(ctx context.Context) (restored proxmoxclient.Backup, err error) {
+	node := a.Config.Node
+	scratch := proxmoxclient.VMID(a.Config.ScratchVMID)
+
+	a.StatusLine.Set("selecting backup to restore")
+	if err := a.checkScratchUnused(ctx, scratch); err != nil {
+		return restored, err
+	}
+
+	backups, err := a.ProxmoxClient.ListBackups(ctx, node, a.BackupStorage)
+	if err != nil {
+		return restored, fmt.Errorf("failed to list backups: %w", err)
+	}
+	restored, err = pickBackup(backups, a.Config.VMIDs, a.randIntN)
+	if err != nil {
+		return restored, err
+	}
+
+	guestType := guestTypeOf(restored)
+	a.Logger.Info("Restoring backup",
+		"vmid", restored.VMID,
+		"volid", restored.VolID,
+		"scratch_vmid", scratch,
+		"node", node,
+		"storage", a.Config.Storage)
+
+	a.StatusLine.Set(fmt.Sprintf("restoring VMID %d backup from %s to VMID %d",
+		restored.VMID, restored.CTime.Format(time.RFC3339), scratch))
+	taskID, err := a.ProxmoxClient.Restore(ctx, node, guestType, scratch, restored.VolID, a.Config.Storage)
+	if err != nil {
+		return restored, err
+	}
+
+	// From here on the scratch guest may exist, so always clean it up
+	defer func() {
+		if cleanupErr := a.destroyScratch(ctx, guestType, scratch); cleanupErr != nil {
+			err = errors.Join(err, cleanupErr)
+		}
+	}()
+
+	if err := a.waitForTask(ctx, taskID, a.Config.RestoreTimeout); err != nil {
+		return restored, fmt.Errorf("restore failed: %w", err)
+	}
+
+	a.StatusLine.Set(fmt.Sprintf("disabling networking on VMID %d", scratch))
+	if err := a.disableNetworking(ctx, guestType, scratch); err != nil {
+		return restored, err
+	}
+
+	a.StatusLine.Set(fmt.Sprintf("starting VMID %d", scratch))
+	taskID, err = a.ProxmoxClient.StartGuest(ctx, node, guestType, scratch)
+	if err != nil {
+		return restored, err
+	}
+	if err := a.waitForTask(ctx, taskID, startTimeout); err != nil {
+		return restored, fmt.Errorf("restored guest failed to start: %w", err)
+	}
+
+	if a.Config.WaitForAgent && guestType == proxmoxclient.GuestTypeQEMU {
+		a.StatusLine.Set(fmt.Sprintf("waiting for the guest agent on VMID %d", scratch))
+		if err := a.waitForAgent(ctx, scratch); err != nil {
+			return restored, err
+		}
+	}
+
+	return restored, nil
+}
+
+// checkScratchUnused returns an error if a guest with the scratch VMID already exists,
+// so the test never restores over, or destroys, a guest it did not create.
+func (a *RestoreTest) checkScratchUnused(ctx context.Context, scratch proxmoxclient.VMID) error {
+	resources, err := a.ProxmoxClient.ListComputeResources(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list resources: %w", err)
+	}
+	for _, r := range resources {
+		if r.VMID == scratch {
+			return fmt.Errorf("scratch VMID %d is already in use by %q on %s", scratch, r.Name, r.Node)
+		}
+	}
+	return nil
+}
+
+// disableNetworking removes all network devices from the restored guest so it cannot
+// clash with the guest it was backed up from.
+func (a *RestoreTest) disableNetworking(ctx context.Context, guestType string, vmid proxmoxclient.VMID) error {
+	cfg, err := a.ProxmoxClient.GuestConfig(ctx, a.Config.Node, guestType, vmid)
+	if err != nil {
+		return fmt.Errorf("failed to get restored guest config: %w", err)
+	}
+
+	var nics []string
+	for key := range cfg {
+		if strings.HasPrefix(key, "net") {
+			nics = append(nics, key)
+		}
+	}
+	if len(nics) == 0 {
+		return nil
+	}
+	slices.Sort(nics)
+
+	if err := a.ProxmoxClient.DeleteGuestConfig(ctx, a.Config.Node, guestType, vmid, nics); err != nil {
+		return fmt.Errorf("failed to remove network devices %v: %w", nics, err)
+	}
+	a.Logger.Debug("Removed network devices from restored guest", "vmid", vmid, "devices", nics)
+	return nil
+}
+
+// waitForAgent polls the QEMU guest agent until it responds or the agent timeout expires.
+func (a *RestoreTest) waitForAgent(ctx context.Context, vmid proxmoxclient.VMID) error {
+	timeout := time.After(a.Config.AgentTimeout)
+	for {
+		err := a.ProxmoxClient.AgentPing(ctx, a.Config.Node, vmid)
+		if err == nil {
+			return nil
+		}
+		a.Logger.Debug("Guest agent not responding yet", "vmid", vmid, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout:
+			return fmt.Errorf("guest agent did not respond within %v: %w", a.Config.AgentTimeout, err)
+		case <-time.After(agentCheckInterval):
+		}
+	}
+}
+
+// destroyScratch stops and destroys the scratch guest. It uses a fresh timeout since the
+// run's context may already be done.
+func (a *RestoreTest) destroyScratch(ctx context.Context, guestType string, vmid proxmoxclient.VMID) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+	defer cancel()
+
+	a.StatusLine.Set(fmt.Sprintf("destroying scratch VMID %d", vmid))
+
+	// Stopping fails if the guest never started, which is fine
+	if taskID, err := a.ProxmoxClient.StopGuest(ctx, a.Config.Node, guestType, vmid); err != nil {
+		a.Logger.Debug("Failed to stop scratch guest", "vmid", vmid, "error", err)
+	} else if err := a.waitForTask(ctx, taskID, startTimeout); err != nil {
+		a.Logger.Debug("Failed to stop scratch guest", "vmid", vmid, "error", err)
+	}
+
+	taskID, err := a.ProxmoxClient.DestroyGuest(ctx, a.Config.Node, guestType, vmid)
+	if err == nil {
+		err = a.waitForTask(ctx, taskID, cleanupTimeout)
+	}
+	if err != nil {
+		a.Logger.Error("Failed to destroy scratch guest", "vmid", vmid, "error", err)
+		return fmt.Errorf("failed to destroy scratch VMID %d: %w", vmid, err)
+	}
+	return nil
+}
+
+// waitForTask polls a task on the restore node until it stops or the timeout expires.
+func (a *RestoreTest) waitForTask(ctx context.Context, taskID proxmoxclient.TaskID, timeout time.Duration) error {
+	deadline := time.After(timeout)
+	for {
+		status, err := a.ProxmoxClient.TaskStatus(ctx, a.Config.Node, taskID)
+		if err != nil {
+			return err
+		}
+
+		if status.Status == "stopped" {
+			if status.ExitStatus != "OK" && !strings.HasPrefix(status.ExitStatus, warningsPrefix) {
+				return fmt.Errorf("task failed with exit status: %s", status.ExitStatus)
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return fmt.Errorf("task timed out after %v", timeout)
+		case <-time.After(taskCheckInterval):
+		}
+	}
+}
+
+// pickBackup returns the latest backup of a guest chosen with randIntN from the guests
+// that have backups. If vmids is not empty only those guests are considered.
+func pickBackup(backups []proxmoxclient.Backup, vmids []int, randIntN func(n int) int) (proxmoxclient.Backup, error) {
+	latest := make(map[proxmoxclient.VMID]proxmoxclient.Backup)
+	for _, b := range backups {
+		if len(vmids) > 0 && !slices.Contains(vmids, int(b.VMID)) {
+			continue
+		}
+		if current, ok := latest[b.VMID]; !ok || b.CTime.After(current.CTime) {
+			latest[b.VMID] = b
+		}
+	}
+	if len(latest) == 0 {
+		return proxmoxclient.Backup{}, ErrNoBackups
+	}
+
+	// Sort the candidates so the choice only depends on randIntN
+	candidates := make([]proxmoxclient.VMID, 0, len(latest))
+	for vmid := range latest {
+		candidates = append(candidates, vmid)
+	}
+	slices.Sort(candidates)
+
+	return latest[candidates[randIntN(len(candidates))]], nil
+}
+
+// guestTypeOf returns the guest type of a backup, falling back to its volume ID for
+// storages that don't report a subtype.
+func guestTypeOf(b proxmoxclient.Backup) string {
+	if b.Subtype != "" {
+		return b.Subtype
+	}
+	if strings.Contains(b.VolID, "backup/ct/") || strings.Contains(b.VolID, "-lxc-") {
+		return proxmoxclient.GuestTypeLXC
+	}
+	return proxmoxclient.GuestTypeQEMU
+}
@@ -0,0 +1,195 @@
+package restoretest
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nomis52/goback/activity"
+	"github.com/nomis52/goback/clients/proxmoxclient"
+	"github.com/nomis52/goback/config"
+	"github.com/nomis52/goback/metrics"
+	"github.com/nomis52/goback/workflow"
+)
+
+func TestPickBackup(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 4, 0, 0, 0, time.UTC) }
+	backups := []proxmoxclient.Backup{
+		{VMID: 101, CTime: day(1), VolID: "101-old"},
+		{VMID: 100, CTime: day(2), VolID: "100-new"},
+		{VMID: 101, CTime: day(3), VolID: "101-new"},
+		{VMID: 100, CTime: day(1), VolID: "100-old"},
+		{VMID: 102, CTime: day(1), VolID: "102"},
+	}
+
+	tests := []struct {
+		name    string
+		vmids   []int
+		pick    int
+		want    string
+		wantErr error
+	}{
+		{name: "first candidate", pick: 0, want: "100-new"},
+		{name: "second candidate", pick: 1, want: "101-new"},
+		{name: "configured vmids", vmids: []int{102}, pick: 0, want: "102"},
+		{name: "no matching backups", vmids: []int{200}, wantErr: ErrNoBackups},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var candidates int
+			got, err := pickBackup(backups, tt.vmids, func(n int) int {
+				candidates = n
+				return tt.pick
+			})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.VolID)
+			if len(tt.vmids) == 0 {
+				assert.Equal(t, 3, candidates)
+			}
+		})
+	}
+}
+
+func TestGuestTypeOf(t *testing.T) {
+	assert.Equal(t, "lxc", guestTypeOf(proxmoxclient.Backup{Subtype: "lxc"}))
+	assert.Equal(t, "lxc", guestTypeOf(proxmoxclient.Backup{VolID: "pbs:backup/ct/101/2026-01-02T04:05:00Z"}))
+	assert.Equal(t, "qemu", guestTypeOf(proxmoxclient.Backup{VolID: "pbs:backup/vm/100/2026-01-02T04:05:00Z"}))
+}
+
+func TestRestoreTest_Execute(t *testing.T) {
+	tests := []struct {
+		name          string
+		resources     string
+		startExit     string
+		wantErr       string
+		wantRequests  []string
+		wantNoRequest string
+	}{
+		{
+			name:      "success",
+			resources: `[{"vmid": 100, "name": "web", "node": "pve1", "type": "qemu"}]`,
+			startExit: "OK",
+			wantRequests: []string{
+				"POST /api2/json/nodes/pve1/qemu",
+				"PUT /api2/json/nodes/pve1/qemu/9999/config",
+				"POST /api2/json/nodes/pve1/qemu/9999/status/start",
+				"POST /api2/json/nodes/pve1/qemu/9999/agent/ping",
+				"POST /api2/json/nodes/pve1/qemu/9999/status/stop",
+				"DELETE /api2/json/nodes/pve1/qemu/9999",
+			},
+		},
+		{
+			name:      "failed start still destroys the scratch guest",
+			resources: `[{"vmid": 100, "name": "web", "node": "pve1", "type": "qemu"}]`,
+			startExit: "start failed: no bootable device",
+			wantErr:   "restored guest failed to start: task failed with exit status: start failed: no bootable device",
+			wantRequests: []string{
+				"POST /api2/json/nodes/pve1/qemu",
+				"POST /api2/json/nodes/pve1/qemu/9999/status/start",
+				"DELETE /api2/json/nodes/pve1/qemu/9999",
+			},
+		},
+		{
+			name:          "scratch vmid in use",
+			resources:     `[{"vmid": 9999, "name": "precious", "node": "pve2", "type": "qemu"}]`,
+			wantErr:       `scratch VMID 9999 is already in use by "precious" on pve2`,
+			wantNoRequest: "DELETE /api2/json/nodes/pve1/qemu/9999",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var requests []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				mu.Unlock()
+
+				switch {
+				case r.URL.Path == "/api2/json/cluster/resources":
+					w.Write([]byte(`{"data": ` + tt.resources + `}`))
+				case r.URL.Path == "/api2/json/nodes/pve1/storage/pbs/content":
+					w.Write([]byte(`{"data": [{"volid": "pbs:backup/vm/100/2026-01-02T04:05:00Z", "vmid": 100, "ctime": 1767326700, "size": 1024, "subtype": "qemu"}]}`))
+				case strings.HasPrefix(r.URL.Path, "/api2/json/nodes/pve1/tasks/"):
+					exit := "OK"
+					if strings.Contains(r.URL.Path, "qmstart") {
+						exit = tt.startExit
+					}
+					w.Write([]byte(`{"data": {"status": "stopped", "exitstatus": "` + exit + `"}}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api2/json/nodes/pve1/qemu/9999/config":
+					w.Write([]byte(`{"data": {"name": "web", "net0": "virtio=AA:BB:CC:DD:EE:FF,bridge=vmbr0"}}`))
+				case r.Method == http.MethodPut:
+					assert.Equal(t, "net0", r.URL.Query().Get("delete"))
+					w.Write([]byte(`{"data": null}`))
+				case strings.HasSuffix(r.URL.Path, "/status/start"):
+					w.Write([]byte(`{"data": "UPID:pve1:qmstart"}`))
+				case strings.HasSuffix(r.URL.Path, "/agent/ping"):
+					w.Write([]byte(`{"data": {}}`))
+				default:
+					w.Write([]byte(`{"data": "UPID:pve1:task"}`))
+				}
+			}))
+			defer ts.Close()
+
+			client, err := proxmoxclient.New(ts.URL)
+			require.NoError(t, err)
+			registry, err := metrics.NewScrapeRegistry()
+			require.NoError(t, err)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			a := &RestoreTest{
+				ProxmoxClient: client,
+				Logger:        logger,
+				StatusLine:    activity.NewStatusLine(workflow.ActivityID{Module: "restoretest", Type: "RestoreTest"}, logger, activity.NewStatusHandler()),
+				Registry:      registry,
+				BackupStorage: "pbs",
+				Config: config.RestoreTestConfig{
+					Node:           "pve1",
+					Storage:        "local-lvm",
+					ScratchVMID:    9999,
+					WaitForAgent:   true,
+					AgentTimeout:   time.Minute,
+					RestoreTimeout: time.Minute,
+				},
+			}
+			require.NoError(t, a.Init())
+
+			err = a.Execute(context.Background())
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Subset(t, requests, tt.wantRequests)
+			if tt.wantNoRequest != "" {
+				assert.NotContains(t, requests, tt.wantNoRequest)
+			}
+		})
+	}
+}
+
+func TestRestoreTest_InitNotConfigured(t *testing.T) {
+	a := &RestoreTest{}
+	assert.ErrorIs(t, a.Init(), ErrNotConfigured)
+}
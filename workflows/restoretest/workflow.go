@@ -0,0 +1,61 @@
+// Package restoretest provides a workflow that proves backups can be restored.
+// It restores a sample backup to a scratch guest, boots it and destroys it again.
+package restoretest
+
+import (
+	"fmt"
+
+	"github.com/nomis52/goback/power"
+	"github.com/nomis52/goback/workflow"
+	"github.com/nomis52/goback/workflows"
+	"github.com/nomis52/goback/workflows/backup"
+)
+
+// NewWorkflow creates a workflow that powers on PBS and runs a restore test.
+// The workflow executes: PowerOnPBS → RestoreTest
+// It does NOT power off PBS after completion.
+func NewWorkflow(params workflows.Params) (workflow.Workflow, error) {
+	cfg := params.Config
+	logger := params.Logger
+
+	// Create orchestrator with config and logger options
+	o := workflow.NewOrchestrator(
+		workflow.WithConfig(cfg),
+		workflow.WithLogger(logger),
+		workflow.WithResultObserver(params.ResultObserver),
+		workflow.WithMetrics(params.Registry, params.Name),
+	)
+
+	ctrl, err := power.New(cfg.PBS, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create power controller: %w", err)
+	}
+
+	pbsClient, err := workflows.NewPBSClient(cfg.PBS, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PBS client: %w", err)
+	}
+
+	proxmoxClient, err := workflows.NewProxmoxClient(cfg.Proxmox, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Proxmox client: %w", err)
+	}
+
+	// Register factories for shared dependencies
+	workflow.Provide(o, workflow.Shared(ctrl))
+	workflow.Provide(o, workflow.Shared(pbsClient))
+	workflow.Provide(o, workflow.Shared(proxmoxClient))
+
+	// Inject common factories (logger, metrics registry, status line)
+	params.InjectInto(o)
+
+	// Add restore test activities
+	powerOnPBS := &backup.PowerOnPBS{}
+	restoreTest := &RestoreTest{}
+
+	if err := o.AddActivity(powerOnPBS, restoreTest); err != nil {
+		return nil, fmt.Errorf("failed to add activities: %w", err)
+	}
+
+	return o, nil
+}
@@ -0,0 +1,25 @@
+package workflows
+
+import "time"
+
+// Window bounds when a run may start new work, such as a backup window that must close
+// before the workday starts. Activities that can stop early declare a workflows.Window
+// dependency, which is the zero Window if the run has no deadline:
+//
+//	type BackupVMs struct {
+//	    Window workflows.Window
+//	}
+type Window struct {
+	// Deadline is when the window closes. Activities should not start new work after it.
+	// Zero if the run has no deadline.
+	Deadline time.Time
+	// Grace is how long work in progress at the deadline may continue. The run is
+	// cancelled once the grace period has passed.
+	Grace time.Duration
+}
+
+// Closed reports whether the window has closed at time now.
+// A window without a deadline never closes.
+func (w Window) Closed(now time.Time) bool {
+	return !w.Deadline.IsZero() && !now.Before(w.Deadline)
+}